@@ -0,0 +1,970 @@
+// Package blobfs provides a billy filesystem over an object store, as S3 or
+// GCS, accessed through a pluggable Driver.
+package blobfs // import "gopkg.in/src-d/go-billy.v4/blobfs"
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/internal/buffer"
+	"gopkg.in/src-d/go-billy.v4/transport"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+const (
+	defaultDirectoryMode = os.ModeDir | 0755
+	defaultFileMode      = 0644
+	delimiter            = "/"
+	modeMetadata         = "mode"
+	tokenMetadata        = "token"
+)
+
+// Blob is a filesystem over an object store. The paths are mapped to keys of
+// objects, and the directories are emulated by the common prefixes of the
+// keys, plus an empty object with the key of the directory ending with a
+// slash, created by MkdirAll to keep empty directories.
+//
+// The permissions of the files are stored in the metadata of the objects.
+// The content of the files opened for writing is buffered in memory, and
+// written to the store when the file is closed. Rename is done copying and
+// removing every object involved, so it isn't atomic.
+//
+// If the driver implements ExclusiveDriver, the files opened with O_CREATE and
+// O_EXCL are created with a conditional write, and the filesystem has the
+// CreateExclusiveCapability. Every conditional write carries a random token in
+// the metadata of the object, if the driver retries a write that succeeded, the
+// object found is recognized as its own by the token, instead of failing with
+// os.ErrExist. Without it O_EXCL is emulated checking if the object exists
+// before writing it, which is racy.
+//
+// If the driver implements MultipartDriver, the files bigger than the part
+// size are written with a multipart upload, uploading up to Concurrency parts
+// at the same time. Close reports every part that failed in an *UploadError.
+type Blob struct {
+	d Driver
+	o *Options
+}
+
+// New returns a new filesystem over the objects of the given driver,
+// configured with the given options.
+func New(d Driver, opts ...Option) billy.Filesystem {
+	return chroot.New(&Blob{d: d, o: NewOptions(opts...)}, string(filepath.Separator))
+}
+
+func (fs *Blob) Create(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, defaultFileMode)
+}
+
+func (fs *Blob) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *Blob) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	key := toKey(filename)
+	o, err := fs.d.Head(key)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if o == nil {
+		isDir, err := fs.isDir(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if isDir {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: billy.ErrIsDir}
+		}
+
+		if flag&os.O_CREATE == 0 {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+		}
+
+		if err := fs.checkParents(filename, key); err != nil {
+			return nil, err
+		}
+	} else if flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrExist}
+	}
+
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) == 0 {
+		return &reader{name: filename, key: key, d: fs.d, size: o.Size, retry: &fs.o.Retry}, nil
+	}
+
+	return fs.openWriter(filename, key, flag, perm, o)
+}
+
+func (fs *Blob) openWriter(filename, key string, flag int, perm os.FileMode, o *Object) (billy.File, error) {
+	w := &writer{name: filename, key: key, d: fs.d, o: fs.o, flag: flag, content: buffer.New(nil),
+		metadata: map[string]string{
+			modeMetadata: strconv.FormatUint(uint64(perm.Perm()), 8),
+		}}
+
+	if o != nil {
+		w.metadata = o.Metadata
+	}
+
+	if o == nil && flag&os.O_EXCL != 0 {
+		if ed, ok := fs.d.(ExclusiveDriver); ok {
+			if err := fs.putExclusive(ed, filename, w); err != nil {
+				return nil, err
+			}
+
+			return w, nil
+		}
+	}
+
+	if o == nil || flag&os.O_TRUNC != 0 {
+		// the object is written at open, as a file would be created or
+		// truncated, so it is visible before the file is closed.
+		if err := fs.d.Put(key, bytes.NewReader(nil), w.metadata); err != nil {
+			return nil, err
+		}
+
+		return w, nil
+	}
+
+	r, err := fs.d.Get(key, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	w.content = buffer.New(content)
+	return w, nil
+}
+
+// putExclusive creates the empty object of w, if it doesn't exist, with a
+// conditional write identified by a random token.
+func (fs *Blob) putExclusive(d ExclusiveDriver, filename string, w *writer) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	w.metadata[tokenMetadata] = token
+	err = d.PutIfNotExists(w.key, bytes.NewReader(nil), w.metadata)
+	if !os.IsExist(err) {
+		return err
+	}
+
+	o, herr := fs.d.Head(w.key)
+	if herr == nil && o.Metadata[tokenMetadata] == token {
+		return nil
+	}
+
+	return &os.PathError{Op: "open", Path: filename, Err: os.ErrExist}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// checkParents returns an error if any of the parents of the given key is a
+// file.
+func (fs *Blob) checkParents(filename, key string) error {
+	for dir := path.Dir(key); dir != "."; dir = path.Dir(dir) {
+		_, err := fs.d.Head(dir)
+		if err == nil {
+			return &os.PathError{Op: "open", Path: filename, Err: syscall.ENOTDIR}
+		}
+
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isDir returns true if the given key is a directory, being the root, having
+// a directory object, or being the prefix of any key.
+func (fs *Blob) isDir(key string) (bool, error) {
+	if key == "" {
+		return true, nil
+	}
+
+	objects, prefixes, err := fs.d.List(key+delimiter, delimiter)
+	if err != nil {
+		return false, err
+	}
+
+	return len(objects) > 0 || len(prefixes) > 0, nil
+}
+
+// dirModTime returns the modification time of a directory, the one of its
+// directory object or, if it has none, the latest of the objects inside it.
+func (fs *Blob) dirModTime(key string) (time.Time, error) {
+	var modTime time.Time
+	if key == "" {
+		return modTime, nil
+	}
+
+	o, err := fs.d.Head(key + delimiter)
+	if err == nil {
+		return o.ModTime, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return modTime, err
+	}
+
+	objects, _, err := fs.d.List(key+delimiter, "")
+	if err != nil {
+		return modTime, err
+	}
+
+	for _, o := range objects {
+		if o.ModTime.After(modTime) {
+			modTime = o.ModTime
+		}
+	}
+
+	return modTime, nil
+}
+
+func (fs *Blob) Stat(filename string) (os.FileInfo, error) {
+	key := toKey(filename)
+	o, err := fs.d.Head(key)
+	if err == nil {
+		return newFileInfo(o), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	isDir, err := fs.isDir(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isDir {
+		return nil, &os.PathError{Op: "stat", Path: filename, Err: os.ErrNotExist}
+	}
+
+	fi := newDirInfo(key)
+	fi.modTime, err = fs.dirModTime(key)
+	return fi, err
+}
+
+// Lstat is equivalent to Stat, symbolic links are not supported.
+func (fs *Blob) Lstat(filename string) (os.FileInfo, error) {
+	return fs.Stat(filename)
+}
+
+func (fs *Blob) ReadDir(dirname string) ([]os.FileInfo, error) {
+	key := toKey(dirname)
+	prefix := key
+	if prefix != "" {
+		prefix += delimiter
+	}
+
+	objects, prefixes, err := fs.d.List(prefix, delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != "" && len(objects) == 0 && len(prefixes) == 0 {
+		_, err := fs.d.Head(key)
+		if err == nil {
+			return nil, &os.PathError{Op: "readdir", Path: dirname, Err: syscall.ENOTDIR}
+		}
+
+		if os.IsNotExist(err) {
+			err = &os.PathError{Op: "readdir", Path: dirname, Err: os.ErrNotExist}
+		}
+
+		return nil, err
+	}
+
+	entries := make([]os.FileInfo, 0, len(objects)+len(prefixes))
+	for i := range objects {
+		if objects[i].Key == prefix {
+			continue
+		}
+
+		entries = append(entries, newFileInfo(&objects[i]))
+	}
+
+	for _, p := range prefixes {
+		entries = append(entries, newDirInfo(strings.TrimSuffix(p, delimiter)))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+// MkdirAll creates a directory object for the given path, the parents are
+// implied by its key.
+func (fs *Blob) MkdirAll(filename string, perm os.FileMode) error {
+	key := toKey(filename)
+	if key == "" {
+		return nil
+	}
+
+	for dir := key; dir != "."; dir = path.Dir(dir) {
+		_, err := fs.d.Head(dir)
+		if err == nil {
+			return &os.PathError{Op: "mkdir", Path: filename, Err: syscall.ENOTDIR}
+		}
+
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return fs.d.Put(key+delimiter, bytes.NewReader(nil), nil)
+}
+
+// Rename copies the objects involved to the new keys, removing the old ones
+// afterwards.
+func (fs *Blob) Rename(from, to string) error {
+	fromKey, toKey := toKey(from), toKey(to)
+	if toKey == fromKey || strings.HasPrefix(toKey, fromKey+delimiter) {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: os.ErrInvalid}
+	}
+
+	if err := fs.checkParents(to, toKey); err != nil {
+		return err
+	}
+
+	_, err := fs.d.Head(fromKey)
+	if err == nil {
+		if err := fs.checkReplace(from, to, toKey, false); err != nil {
+			return err
+		}
+
+		return fs.move(fromKey, toKey)
+	}
+
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	if fromKey == "" {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EINVAL}
+	}
+
+	objects, _, err := fs.d.List(fromKey+delimiter, "")
+	if err != nil {
+		return err
+	}
+
+	if len(objects) == 0 {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: os.ErrNotExist}
+	}
+
+	if err := fs.checkReplace(from, to, toKey, true); err != nil {
+		return err
+	}
+
+	for _, o := range objects {
+		if err := fs.move(o.Key, toKey+o.Key[len(fromKey):]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkReplace returns an error if the file, or the directory if dir is true,
+// being renamed can't replace the one at toKey, as os.Rename does: a file
+// replaces a file, and a directory replaces an empty directory.
+func (fs *Blob) checkReplace(from, to, toKey string, dir bool) error {
+	if toKey == "" {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EINVAL}
+	}
+
+	_, err := fs.d.Head(toKey)
+	switch {
+	case err == nil && dir:
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.ENOTDIR}
+	case err == nil:
+		return nil
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	objects, prefixes, err := fs.d.List(toKey+delimiter, delimiter)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(objects) == 0 && len(prefixes) == 0:
+		return nil
+	case !dir:
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EISDIR}
+	case len(prefixes) > 0 || len(objects) > 1 || objects[0].Key != toKey+delimiter:
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.ENOTEMPTY}
+	}
+
+	return nil
+}
+
+func (fs *Blob) move(from, to string) error {
+	if err := fs.d.Copy(from, to); err != nil {
+		return err
+	}
+
+	return fs.d.Delete(from)
+}
+
+func (fs *Blob) Remove(filename string) error {
+	key := toKey(filename)
+	_, err := fs.d.Head(key)
+	if err == nil {
+		return fs.d.Delete(key)
+	}
+
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	if key == "" {
+		return &os.PathError{Op: "remove", Path: filename, Err: syscall.EINVAL}
+	}
+
+	objects, _, err := fs.d.List(key+delimiter, "")
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(objects) == 0:
+		return &os.PathError{Op: "remove", Path: filename, Err: os.ErrNotExist}
+	case len(objects) > 1 || objects[0].Key != key+delimiter:
+		return &os.PathError{Op: "remove", Path: filename, Err: syscall.ENOTEMPTY}
+	}
+
+	return fs.d.Delete(key + delimiter)
+}
+
+// RemoveMany implements the BulkRemover interface. The objects are deleted with
+// a single call to DeleteMany if the driver implements BatchDriver. A directory
+// is removed only if every object inside it is being removed too.
+func (fs *Blob) RemoveMany(paths []string) error {
+	errs := make(map[string]error)
+	owners := make(map[string]string)
+	var keys, dirs []string
+
+	add := func(key, path string) {
+		owners[key] = path
+		keys = append(keys, key)
+	}
+
+	for _, path := range paths {
+		key := toKey(path)
+		if key == "" {
+			errs[path] = &os.PathError{Op: "remove", Path: path, Err: syscall.EINVAL}
+			continue
+		}
+
+		_, err := fs.d.Head(key)
+		switch {
+		case err == nil:
+			add(key, path)
+		case os.IsNotExist(err):
+			dirs = append(dirs, path)
+		default:
+			errs[path] = err
+		}
+	}
+
+	for _, path := range dirs {
+		key := toKey(path)
+		objects, _, err := fs.d.List(key+delimiter, "")
+		if err != nil {
+			errs[path] = err
+			continue
+		}
+
+		var marker bool
+		for _, o := range objects {
+			if o.Key == key+delimiter {
+				marker = true
+				continue
+			}
+
+			if _, ok := owners[o.Key]; !ok {
+				errs[path] = &os.PathError{Op: "remove", Path: path, Err: syscall.ENOTEMPTY}
+				break
+			}
+		}
+
+		if _, ok := errs[path]; !ok && marker {
+			add(key+delimiter, path)
+		}
+	}
+
+	for key, err := range fs.deleteMany(keys) {
+		errs[owners[key]] = err
+	}
+
+	if len(errs) > 0 {
+		return &billy.RemoveManyError{Errors: errs}
+	}
+
+	return nil
+}
+
+func (fs *Blob) deleteMany(keys []string) map[string]error {
+	if bd, ok := fs.d.(BatchDriver); ok {
+		return bd.DeleteMany(keys)
+	}
+
+	errs := make(map[string]error)
+	for _, key := range keys {
+		if err := fs.d.Delete(key); err != nil {
+			errs[key] = err
+		}
+	}
+
+	return errs
+}
+
+func (fs *Blob) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (fs *Blob) TempFile(dir, prefix string) (billy.File, error) {
+	return util.TempFile(fs, dir, prefix)
+}
+
+func (fs *Blob) TempDir(dir, prefix string) (string, error) {
+	return util.TempDir(fs, dir, prefix)
+}
+
+// Symlink is not supported by blobfs.
+func (fs *Blob) Symlink(target, link string) error {
+	return &os.LinkError{Op: "symlink", Old: target, New: link, Err: billy.ErrNotSupported}
+}
+
+// Readlink is not supported by blobfs.
+func (fs *Blob) Readlink(link string) (string, error) {
+	return "", &os.PathError{Op: "readlink", Path: link, Err: billy.ErrNotSupported}
+}
+
+// Capabilities implements the Capable interface.
+func (fs *Blob) Capabilities() billy.Capability {
+	c := billy.WriteCapability | billy.ReadCapability |
+		billy.ReadAndWriteCapability | billy.SeekCapability |
+		billy.TruncateCapability
+
+	if _, ok := fs.d.(ExclusiveDriver); ok {
+		c |= billy.CreateExclusiveCapability
+	}
+
+	return c
+}
+
+// toKey returns the key of the object of the given path.
+func toKey(filename string) string {
+	return path.Clean(filepath.ToSlash(filepath.Join(string(filepath.Separator), filename)))[1:]
+}
+
+// reader is a file opened for reading, the content is read from the store on
+// demand, with ranged requests.
+type reader struct {
+	name     string
+	key      string
+	d        Driver
+	size     int64
+	position int64
+	r        io.ReadCloser
+	retry    *transport.RetryPolicy
+	isClosed bool
+}
+
+func (f *reader) Name() string {
+	return f.name
+}
+
+func (f *reader) Read(b []byte) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	for retry := 0; ; retry++ {
+		if f.r == nil {
+			if f.position >= f.size {
+				return 0, io.EOF
+			}
+
+			r, err := f.d.Get(f.key, f.position, -1)
+			if err != nil {
+				if !isTemporary(err) || !f.retry.Wait(retry) {
+					return 0, err
+				}
+
+				continue
+			}
+
+			f.r = r
+		}
+
+		n, err := f.r.Read(b)
+		f.position += int64(n)
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		// the read was interrupted, the next request resumes it.
+		f.discard()
+		if n > 0 {
+			return n, nil
+		}
+
+		if !f.retry.Wait(retry) {
+			return 0, err
+		}
+	}
+}
+
+// isTemporary returns true if err is marked as temporary by the driver, with
+// a Temporary method as net.Error.
+func isTemporary(err error) bool {
+	t, ok := err.(interface{ Temporary() bool })
+	return ok && t.Temporary()
+}
+
+func (f *reader) ReadAt(b []byte, off int64) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	if off >= f.size {
+		return 0, io.EOF
+	}
+
+	var read int
+	for retry := 0; ; retry++ {
+		n, err := f.readAt(b[read:], off+int64(read))
+		read += n
+		if n > 0 {
+			retry = 0
+		}
+
+		switch {
+		case err == nil:
+			return read, nil
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			if off+int64(read) < f.size && f.retry.Wait(retry) {
+				continue
+			}
+
+			return read, io.EOF
+		case isTemporary(err) && f.retry.Wait(retry):
+			continue
+		default:
+			if ie, ok := err.(*interruptedError); ok {
+				err = ie.error
+			}
+
+			return read, err
+		}
+	}
+}
+
+// readAt reads len(b) bytes at off with a single request, wrapping the errors
+// of the body, but not the ones of Get, as temporary, so they're retried.
+func (f *reader) readAt(b []byte, off int64) (int, error) {
+	r, err := f.d.Get(f.key, off, int64(len(b)))
+	if err != nil {
+		return 0, err
+	}
+
+	defer r.Close()
+	n, err := io.ReadFull(r, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		err = &interruptedError{err}
+	}
+
+	return n, err
+}
+
+// interruptedError is the error of a read interrupted mid-stream.
+type interruptedError struct{ error }
+
+func (e *interruptedError) Temporary() bool { return true }
+
+// Seek sets the offset of the next Read, the current request to the store is
+// discarded, if any.
+func (f *reader) Seek(offset int64, whence int) (int64, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.position
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrInvalid}
+	}
+
+	if offset != f.position {
+		f.discard()
+	}
+
+	f.position = offset
+	return offset, nil
+}
+
+func (f *reader) discard() {
+	if f.r != nil {
+		f.r.Close()
+		f.r = nil
+	}
+}
+
+func (f *reader) Write(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.name, Err: os.ErrPermission}
+}
+
+func (f *reader) Truncate(size int64) error {
+	return &os.PathError{Op: "truncate", Path: f.name, Err: os.ErrPermission}
+}
+
+func (f *reader) Close() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	f.isClosed = true
+	f.discard()
+	return nil
+}
+
+// Lock is a no-op in blobfs.
+func (f *reader) Lock() error {
+	return nil
+}
+
+// Unlock is a no-op in blobfs.
+func (f *reader) Unlock() error {
+	return nil
+}
+
+// writer is a file opened for writing, the content is buffered in memory and
+// written to the store when closed.
+type writer struct {
+	name     string
+	key      string
+	d        Driver
+	o        *Options
+	flag     int
+	metadata map[string]string
+	content  *buffer.Buffer
+	isClosed bool
+}
+
+func (f *writer) Name() string {
+	return f.name
+}
+
+func (f *writer) Read(b []byte) (int, error) {
+	if err := f.checkRead(); err != nil {
+		return 0, err
+	}
+
+	return f.content.Read(b)
+}
+
+func (f *writer) ReadAt(b []byte, off int64) (int, error) {
+	if err := f.checkRead(); err != nil {
+		return 0, err
+	}
+
+	n, err := f.content.ReadAt(b, off)
+	if err != nil && err != io.EOF {
+		err = &os.PathError{Op: "readat", Path: f.name, Err: err}
+	}
+
+	return n, err
+}
+
+func (f *writer) checkRead() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	if f.flag&os.O_WRONLY != 0 {
+		return &os.PathError{Op: "read", Path: f.name, Err: os.ErrPermission}
+	}
+
+	return nil
+}
+
+func (f *writer) Write(p []byte) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	if f.isReadOnly() {
+		return 0, &os.PathError{Op: "write", Path: f.name, Err: os.ErrPermission}
+	}
+
+	if f.flag&os.O_APPEND != 0 {
+		f.content.Seek(0, io.SeekEnd)
+	}
+
+	return f.content.Write(p)
+}
+
+func (f *writer) Seek(offset int64, whence int) (int64, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	offset, err := f.content.Seek(offset, whence)
+	if err != nil {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: err}
+	}
+
+	return offset, nil
+}
+
+func (f *writer) Truncate(size int64) error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	if f.isReadOnly() {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: os.ErrPermission}
+	}
+
+	if err := f.content.Truncate(size); err != nil {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: err}
+	}
+
+	return nil
+}
+
+// isReadOnly returns true if the file was opened with O_RDONLY, e.g. along
+// with O_CREATE, to create it without writing it.
+func (f *writer) isReadOnly() bool {
+	return f.flag&(os.O_RDONLY|os.O_WRONLY|os.O_RDWR) == os.O_RDONLY
+}
+
+// Sync writes the current content of the file to the store.
+func (f *writer) Sync() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	return f.upload()
+}
+
+// Close writes the content of the file to the store.
+func (f *writer) Close() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	f.isClosed = true
+	return f.upload()
+}
+
+// Lock is a no-op in blobfs.
+func (f *writer) Lock() error {
+	return nil
+}
+
+// Unlock is a no-op in blobfs.
+func (f *writer) Unlock() error {
+	return nil
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	sys     interface{}
+}
+
+func newFileInfo(o *Object) *fileInfo {
+	return &fileInfo{
+		name:    path.Base(o.Key),
+		size:    o.Size,
+		mode:    fileMode(o),
+		modTime: o.ModTime,
+		sys:     o,
+	}
+}
+
+func fileMode(o *Object) os.FileMode {
+	mode, err := strconv.ParseUint(o.Metadata[modeMetadata], 8, 32)
+	if err != nil {
+		return defaultFileMode
+	}
+
+	return os.FileMode(mode).Perm()
+}
+
+func newDirInfo(key string) *fileInfo {
+	name := path.Base(key)
+	if key == "" {
+		name = string(filepath.Separator)
+	}
+
+	return &fileInfo{name: name, mode: defaultDirectoryMode}
+}
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *fileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi *fileInfo) Mode() os.FileMode {
+	return fi.mode
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+func (fi *fileInfo) IsDir() bool {
+	return fi.mode.IsDir()
+}
+
+func (fi *fileInfo) Sys() interface{} {
+	return fi.sys
+}
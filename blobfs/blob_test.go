@@ -0,0 +1,454 @@
+package blobfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/transport"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&BlobSuite{})
+
+type BlobSuite struct {
+	test.FilesystemSuite
+	d *MemoryDriver
+}
+
+func (s *BlobSuite) SetUpTest(c *C) {
+	s.d = NewMemoryDriver()
+	s.FilesystemSuite = test.NewFilesystemSuite(New(s.d))
+}
+
+func (s *BlobSuite) TestConcurrentRemove(c *C) {
+	c.Skip("the directories without objects inside are removed")
+}
+
+func (s *BlobSuite) TestKeys(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar/qux", []byte("qux"), 0644), IsNil)
+	c.Assert(s.FS.MkdirAll("/baz", 0755), IsNil)
+
+	objects, prefixes, err := s.d.List("", "")
+	c.Assert(err, IsNil)
+	c.Assert(prefixes, HasLen, 0)
+	c.Assert(objects, HasLen, 2)
+	c.Assert(objects[0].Key, Equals, "baz/")
+	c.Assert(objects[1].Key, Equals, "foo/bar/qux")
+}
+
+func (s *BlobSuite) TestImplicitDirectories(c *C) {
+	c.Assert(s.d.Put("foo/bar/qux", bytes.NewReader(nil), nil), IsNil)
+
+	fi, err := s.FS.Stat("foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+
+	entries, err := s.FS.ReadDir("foo")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Name(), Equals, "bar")
+	c.Assert(entries[0].IsDir(), Equals, true)
+
+	err = s.FS.Remove("foo/bar")
+	c.Assert(err, NotNil)
+
+	c.Assert(s.FS.Remove("foo/bar/qux"), IsNil)
+	_, err = s.FS.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *BlobSuite) TestRenameDirectory(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar", []byte("bar"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "foo/qux/baz", []byte("baz"), 0644), IsNil)
+
+	c.Assert(s.FS.Rename("foo", "new"), IsNil)
+
+	objects, _, err := s.d.List("", "")
+	c.Assert(err, IsNil)
+	c.Assert(objects, HasLen, 2)
+	c.Assert(objects[0].Key, Equals, "new/bar")
+	c.Assert(objects[1].Key, Equals, "new/qux/baz")
+}
+
+func (s *BlobSuite) TestRenameIntoSubtree(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar", []byte("bar"), 0644), IsNil)
+
+	err := s.FS.Rename("foo", "foo/qux")
+	c.Assert(err, FitsTypeOf, &os.LinkError{})
+	c.Assert(err.(*os.LinkError).Err, Equals, os.ErrInvalid)
+
+	objects, _, err := s.d.List("", "")
+	c.Assert(err, IsNil)
+	c.Assert(objects, HasLen, 1)
+	c.Assert(objects[0].Key, Equals, "foo/bar")
+}
+
+func (s *BlobSuite) TestSymlinkNotSupported(c *C) {
+	err := s.FS.Symlink("foo", "bar")
+	c.Assert(err, FitsTypeOf, &os.LinkError{})
+	c.Assert(err.(*os.LinkError).Err, Equals, billy.ErrNotSupported)
+
+	_, err = s.FS.Readlink("bar")
+	c.Assert(err, FitsTypeOf, &os.PathError{})
+	c.Assert(err.(*os.PathError).Err, Equals, billy.ErrNotSupported)
+}
+
+func (s *BlobSuite) TestWriteOnClose(c *C) {
+	f, err := s.FS.Create("foo")
+	c.Assert(err, IsNil)
+
+	_, err = f.Write([]byte("foo"))
+	c.Assert(err, IsNil)
+
+	o, err := s.d.Head("foo")
+	c.Assert(err, IsNil)
+	c.Assert(o.Size, Equals, int64(0))
+
+	c.Assert(f.Close(), IsNil)
+
+	o, err = s.d.Head("foo")
+	c.Assert(err, IsNil)
+	c.Assert(o.Size, Equals, int64(3))
+}
+
+func (s *BlobSuite) TestReadSeek(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("0123456789"), 0644), IsNil)
+
+	f, err := s.FS.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	b := make([]byte, 3)
+	_, err = io.ReadFull(f, b)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "012")
+
+	_, err = f.Seek(-2, io.SeekEnd)
+	c.Assert(err, IsNil)
+
+	all, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(all), Equals, "89")
+
+	n, err := f.ReadAt(b, 8)
+	c.Assert(err, Equals, io.EOF)
+	c.Assert(string(b[:n]), Equals, "89")
+}
+
+// retryingDriver simulates a driver retrying a conditional write whose first
+// attempt succeeded, but its response was lost.
+type retryingDriver struct {
+	*MemoryDriver
+}
+
+func (d *retryingDriver) PutIfNotExists(key string, r io.Reader, metadata map[string]string) error {
+	if err := d.MemoryDriver.PutIfNotExists(key, r, metadata); err != nil {
+		return err
+	}
+
+	return d.MemoryDriver.PutIfNotExists(key, bytes.NewReader(nil), metadata)
+}
+
+// plainDriver hides the conditional writes of MemoryDriver.
+type plainDriver struct {
+	Driver
+}
+
+func (s *BlobSuite) TestCreateExclusive(c *C) {
+	c.Assert(billy.CapabilityCheck(s.FS, billy.CreateExclusiveCapability), Equals, true)
+
+	f, err := s.FS.OpenFile("foo", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(err, IsNil)
+
+	o, err := s.d.Head("foo")
+	c.Assert(err, IsNil)
+	c.Assert(o.Metadata[tokenMetadata], Not(Equals), "")
+	c.Assert(f.Close(), IsNil)
+
+	_, err = s.FS.OpenFile("foo", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(os.IsExist(err), Equals, true)
+}
+
+func (s *BlobSuite) TestCreateExclusiveRace(c *C) {
+	fs := &Blob{d: s.d}
+	w := &writer{key: "foo", metadata: map[string]string{}}
+
+	c.Assert(s.d.Put("foo", bytes.NewReader(nil), nil), IsNil)
+	err := fs.putExclusive(s.d, "foo", w)
+	c.Assert(os.IsExist(err), Equals, true)
+}
+
+func (s *BlobSuite) TestCreateExclusiveRetry(c *C) {
+	fs := New(&retryingDriver{s.d})
+
+	f, err := fs.OpenFile("foo", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	_, err = fs.OpenFile("foo", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(os.IsExist(err), Equals, true)
+}
+
+func (s *BlobSuite) TestCreateExclusiveNotSupported(c *C) {
+	fs := New(&plainDriver{s.d})
+	c.Assert(billy.CapabilityCheck(fs, billy.CreateExclusiveCapability), Equals, false)
+
+	f, err := fs.OpenFile("foo", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	_, err = fs.OpenFile("foo", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(os.IsExist(err), Equals, true)
+}
+
+func (s *BlobSuite) TestSync(c *C) {
+	f, err := s.FS.Create("foo")
+	c.Assert(err, IsNil)
+
+	_, err = f.Write([]byte("foo"))
+	c.Assert(err, IsNil)
+	c.Assert(billy.Sync(f), IsNil)
+
+	o, err := s.d.Head("foo")
+	c.Assert(err, IsNil)
+	c.Assert(o.Size, Equals, int64(3))
+	c.Assert(f.Close(), IsNil)
+}
+
+// countingDriver counts the calls to DeleteMany.
+type countingDriver struct {
+	*MemoryDriver
+	calls int
+}
+
+func (d *countingDriver) DeleteMany(keys []string) map[string]error {
+	d.calls++
+	return d.MemoryDriver.DeleteMany(keys)
+}
+
+func (s *BlobSuite) TestRemoveMany(c *C) {
+	d := &countingDriver{MemoryDriver: s.d}
+	fs := New(d)
+	c.Assert(util.WriteFile(fs, "foo/bar", []byte("bar"), 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "foo/qux/baz", []byte("baz"), 0644), IsNil)
+	c.Assert(fs.MkdirAll("foo/empty", 0755), IsNil)
+
+	c.Assert(util.RemoveAll(fs, "foo"), IsNil)
+	c.Assert(d.calls, Equals, 1)
+
+	objects, _, err := s.d.List("", "")
+	c.Assert(err, IsNil)
+	c.Assert(objects, HasLen, 0)
+}
+
+func (s *BlobSuite) TestRemoveManyNotEmpty(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar", []byte("bar"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "foo/qux", []byte("qux"), 0644), IsNil)
+
+	err := billy.RemoveMany(s.FS, []string{"foo/bar", "foo", "missing"})
+	c.Assert(err, FitsTypeOf, &billy.RemoveManyError{})
+	c.Assert(err.(*billy.RemoveManyError).Paths(), DeepEquals, []string{"foo"})
+
+	_, err = s.FS.Stat("foo/bar")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = s.FS.Stat("foo/qux")
+	c.Assert(err, IsNil)
+}
+
+// multipartDriver fails the upload of the given parts, and records the
+// maximum number of parts uploaded at the same time.
+type multipartDriver struct {
+	*MemoryDriver
+	fail map[int]error
+
+	m             sync.Mutex
+	uploading     int
+	maxUploading  int
+	uploadedParts int
+}
+
+func (d *multipartDriver) UploadPart(key, uploadID string, number int, r io.Reader, mode ChecksumMode, checksum []byte) (string, error) {
+	d.m.Lock()
+	d.uploading++
+	d.uploadedParts++
+	if d.uploading > d.maxUploading {
+		d.maxUploading = d.uploading
+	}
+	d.m.Unlock()
+
+	defer func() {
+		d.m.Lock()
+		d.uploading--
+		d.m.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := d.fail[number]; err != nil {
+		return "", err
+	}
+
+	return d.MemoryDriver.UploadPart(key, uploadID, number, r, mode, checksum)
+}
+
+func (s *BlobSuite) TestMultipart(c *C) {
+	d := &multipartDriver{MemoryDriver: s.d}
+	fs := New(d, WithPartSize(4), WithConcurrency(2), WithChecksum(ChecksumSHA256))
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("0123456789"), 0640), IsNil)
+	c.Assert(d.uploadedParts, Equals, 3)
+	c.Assert(d.maxUploading, Equals, 2)
+	c.Assert(s.d.uploads, HasLen, 0)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "0123456789")
+
+	fi, err := fs.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode(), Equals, os.FileMode(0640))
+}
+
+func (s *BlobSuite) TestMultipartSmallFile(c *C) {
+	d := &multipartDriver{MemoryDriver: s.d}
+	fs := New(d, WithPartSize(4))
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("0123"), 0644), IsNil)
+	c.Assert(d.uploadedParts, Equals, 0)
+}
+
+func (s *BlobSuite) TestMultipartError(c *C) {
+	d := &multipartDriver{MemoryDriver: s.d, fail: map[int]error{
+		2: io.ErrUnexpectedEOF,
+		3: ErrChecksum,
+	}}
+
+	fs := New(d, WithPartSize(2), WithConcurrency(1))
+	f, err := fs.Create("foo")
+	c.Assert(err, IsNil)
+
+	_, err = f.Write([]byte("0123456789"))
+	c.Assert(err, IsNil)
+
+	err = f.Close()
+	c.Assert(err, FitsTypeOf, &UploadError{})
+
+	uerr := err.(*UploadError)
+	c.Assert(uerr.Key, Equals, "foo")
+	c.Assert(uerr.Numbers(), DeepEquals, []int{2, 3})
+	c.Assert(uerr.Parts[2], Equals, io.ErrUnexpectedEOF)
+	c.Assert(uerr.AbortErr, IsNil)
+	c.Assert(d.uploadedParts, Equals, 5)
+	c.Assert(s.d.uploads, HasLen, 0)
+}
+
+func (s *BlobSuite) TestMemoryDriverChecksum(c *C) {
+	id, err := s.d.CreateMultipart("foo", nil)
+	c.Assert(err, IsNil)
+
+	_, err = s.d.UploadPart("foo", id, 1, bytes.NewReader([]byte("foo")),
+		ChecksumMD5, ChecksumMD5.Sum([]byte("bar")))
+	c.Assert(err, Equals, ErrChecksum)
+}
+
+// flakyDriver returns readers interrupted after the first byte, the given
+// number of times.
+type flakyDriver struct {
+	*MemoryDriver
+	interruptions int
+}
+
+func (d *flakyDriver) Get(key string, offset, length int64) (io.ReadCloser, error) {
+	r, err := d.MemoryDriver.Get(key, offset, length)
+	if err != nil || d.interruptions == 0 {
+		return r, err
+	}
+
+	d.interruptions--
+	return &interruptedReader{ReadCloser: r}, nil
+}
+
+var errReset = errors.New("connection reset")
+
+type interruptedReader struct {
+	io.ReadCloser
+	read bool
+}
+
+func (r *interruptedReader) Read(p []byte) (int, error) {
+	if r.read || len(p) == 0 {
+		return 0, errReset
+	}
+
+	r.read = true
+	return r.ReadCloser.Read(p[:1])
+}
+
+func (s *BlobSuite) TestReadResumed(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foobar"), 0644), IsNil)
+
+	d := &flakyDriver{MemoryDriver: s.d, interruptions: 3}
+	fs := New(d, WithRetry(transport.RetryPolicy{MaxRetries: 1}))
+
+	content, err := readFile(fs, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foobar")
+	c.Assert(d.interruptions, Equals, 0)
+
+	d.interruptions = 6
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	b := make([]byte, 5)
+	n, err := f.ReadAt(b, 1)
+	c.Assert(err, IsNil)
+	c.Assert(string(b[:n]), Equals, "oobar")
+}
+
+func (s *BlobSuite) TestReadNotRetried(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foobar"), 0644), IsNil)
+
+	d := &flakyDriver{MemoryDriver: s.d, interruptions: 1}
+	fs := New(d, WithRetry(transport.RetryPolicy{}))
+
+	content, err := readFile(fs, "foo")
+	c.Assert(err, Equals, errReset)
+	c.Assert(string(content), Equals, "f")
+}
+
+func readFile(fs billy.Filesystem, filename string) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func (s *BlobSuite) TestNewOptions(c *C) {
+	o := NewOptions(WithPartSize(-1), WithChecksum(ChecksumMD5))
+	c.Assert(o, DeepEquals, &Options{
+		PartSize:    DefaultPartSize,
+		Concurrency: DefaultConcurrency,
+		Checksum:    ChecksumMD5,
+		Retry:       transport.DefaultRetryPolicy,
+	})
+}
@@ -0,0 +1,127 @@
+package blobfs
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"time"
+)
+
+// Object holds the attributes of an object of the store.
+type Object struct {
+	// Key is the full key of the object.
+	Key string
+	// Size is the length in bytes of the content.
+	Size int64
+	// ModTime is the last time the object was written.
+	ModTime time.Time
+	// Metadata holds the user defined metadata of the object.
+	Metadata map[string]string
+}
+
+// Driver is the access to an object store, as S3 or GCS, where the objects
+// are identified by a flat key. The keys are slash separated paths, without a
+// leading slash.
+type Driver interface {
+	// Get returns a reader of length bytes of the content of the object,
+	// starting at offset, a negative length reads until the end. It returns
+	// os.ErrNotExist if the object doesn't exist. The errors with a
+	// Temporary method returning true, as net.Error, are retried following
+	// Options.Retry, as the errors of the reader returned.
+	Get(key string, offset, length int64) (io.ReadCloser, error)
+	// Put writes the content and the metadata of the object, replacing it if
+	// it exists.
+	Put(key string, r io.Reader, metadata map[string]string) error
+	// Head returns the attributes of the object, or os.ErrNotExist if it
+	// doesn't exist.
+	Head(key string) (*Object, error)
+	// List returns the objects whose key starts with prefix, sorted by key.
+	// If delimiter is not empty, the keys containing it after the prefix
+	// are grouped, and returned as common prefixes ending with delimiter,
+	// instead of as objects.
+	List(prefix, delimiter string) (objects []Object, prefixes []string, err error)
+	// Copy copies the object with key from, and its metadata, to the key to.
+	Copy(from, to string) error
+	// Delete removes the object, it doesn't fail if the object doesn't exist.
+	Delete(key string) error
+}
+
+// BatchDriver is a Driver able to delete many objects with a single request,
+// as the DeleteObjects of S3 does.
+type BatchDriver interface {
+	Driver
+	// DeleteMany removes the objects, returning the error of each key that
+	// couldn't be removed, if any. It doesn't fail for the objects that don't
+	// exist.
+	DeleteMany(keys []string) map[string]error
+}
+
+// ExclusiveDriver is a Driver able to write an object only if it doesn't
+// exist, atomically, as the conditional writes of S3 (If-None-Match) or GCS
+// (generation preconditions) do.
+type ExclusiveDriver interface {
+	Driver
+	// PutIfNotExists writes the content and the metadata of the object, only
+	// if it doesn't exist, returning os.ErrExist otherwise.
+	PutIfNotExists(key string, r io.Reader, metadata map[string]string) error
+}
+
+// ChecksumMode is the checksum sent along with each part of a multipart
+// upload, for the store to verify its content.
+type ChecksumMode int
+
+const (
+	// ChecksumNone sends no checksum.
+	ChecksumNone ChecksumMode = iota
+	// ChecksumMD5 sends the MD5 of the content, as Content-MD5 does.
+	ChecksumMD5
+	// ChecksumSHA256 sends the SHA-256 of the content.
+	ChecksumSHA256
+)
+
+// Sum returns the checksum of b, or nil for ChecksumNone.
+func (m ChecksumMode) Sum(b []byte) []byte {
+	switch m {
+	case ChecksumMD5:
+		s := md5.Sum(b)
+		return s[:]
+	case ChecksumSHA256:
+		s := sha256.Sum256(b)
+		return s[:]
+	default:
+		return nil
+	}
+}
+
+// ErrChecksum is returned by the drivers when the content of a part doesn't
+// match its checksum.
+var ErrChecksum = errors.New("content doesn't match the checksum")
+
+// Part is an uploaded part of a multipart upload.
+type Part struct {
+	// Number is the position of the part in the object, starting at 1.
+	Number int
+	// ID is the identifier of the part returned by UploadPart.
+	ID string
+}
+
+// MultipartDriver is a Driver able to write an object as a sequence of parts
+// uploaded independently, and concurrently, as the multipart uploads of S3 or
+// the composite objects of GCS.
+type MultipartDriver interface {
+	Driver
+	// CreateMultipart starts the upload of the object with the given
+	// metadata, returning its identifier.
+	CreateMultipart(key string, metadata map[string]string) (uploadID string, err error)
+	// UploadPart uploads the content of the part number of the upload,
+	// returning the identifier of the part. If mode isn't ChecksumNone the
+	// store must verify the content against checksum, returning ErrChecksum
+	// if it doesn't match.
+	UploadPart(key, uploadID string, number int, r io.Reader, mode ChecksumMode, checksum []byte) (string, error)
+	// CompleteMultipart writes the object as the concatenation of the given
+	// parts, in order, replacing it if it exists.
+	CompleteMultipart(key, uploadID string, parts []Part) error
+	// AbortMultipart discards the upload and every part uploaded.
+	AbortMultipart(key, uploadID string) error
+}
@@ -0,0 +1,295 @@
+package blobfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryDriver is a Driver storing the objects in memory, it is meant for
+// testing. It's safe for concurrent use by multiple goroutines.
+type MemoryDriver struct {
+	m       sync.RWMutex
+	objects map[string]*memoryObject
+	uploads map[string]*memoryUpload
+	lastID  int
+}
+
+type memoryObject struct {
+	content  []byte
+	modTime  time.Time
+	metadata map[string]string
+}
+
+type memoryUpload struct {
+	key      string
+	metadata map[string]string
+	parts    map[int][]byte
+}
+
+// NewMemoryDriver returns a new empty MemoryDriver.
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{
+		objects: make(map[string]*memoryObject),
+		uploads: make(map[string]*memoryUpload),
+	}
+}
+
+func (d *MemoryDriver) Get(key string, offset, length int64) (io.ReadCloser, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+
+	o, ok := d.objects[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	content := o.content
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+
+	content = content[offset:]
+	if length >= 0 && length < int64(len(content)) {
+		content = content[:length]
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (d *MemoryDriver) Put(key string, r io.Reader, metadata map[string]string) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	d.objects[key] = &memoryObject{
+		content:  content,
+		modTime:  time.Now(),
+		metadata: copyMetadata(metadata),
+	}
+
+	return nil
+}
+
+func (d *MemoryDriver) PutIfNotExists(key string, r io.Reader, metadata map[string]string) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if _, ok := d.objects[key]; ok {
+		return os.ErrExist
+	}
+
+	d.objects[key] = &memoryObject{
+		content:  content,
+		modTime:  time.Now(),
+		metadata: copyMetadata(metadata),
+	}
+
+	return nil
+}
+
+func (d *MemoryDriver) Head(key string) (*Object, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+
+	o, ok := d.objects[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	return o.object(key), nil
+}
+
+func (d *MemoryDriver) List(prefix, delimiter string) ([]Object, []string, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+
+	var objects []Object
+	var prefixes []string
+	seen := make(map[string]bool)
+	for key, o := range d.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				p := key[:len(prefix)+i+len(delimiter)]
+				if !seen[p] {
+					seen[p] = true
+					prefixes = append(prefixes, p)
+				}
+
+				continue
+			}
+		}
+
+		objects = append(objects, *o.object(key))
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	sort.Strings(prefixes)
+	return objects, prefixes, nil
+}
+
+func (d *MemoryDriver) Copy(from, to string) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	o, ok := d.objects[from]
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	d.objects[to] = &memoryObject{
+		content:  o.content,
+		modTime:  time.Now(),
+		metadata: o.metadata,
+	}
+
+	return nil
+}
+
+func (d *MemoryDriver) Delete(key string) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	delete(d.objects, key)
+	return nil
+}
+
+func (d *MemoryDriver) DeleteMany(keys []string) map[string]error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	for _, key := range keys {
+		delete(d.objects, key)
+	}
+
+	return nil
+}
+
+func (d *MemoryDriver) CreateMultipart(key string, metadata map[string]string) (string, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	d.lastID++
+	id := strconv.Itoa(d.lastID)
+	d.uploads[id] = &memoryUpload{
+		key:      key,
+		metadata: copyMetadata(metadata),
+		parts:    make(map[int][]byte),
+	}
+
+	return id, nil
+}
+
+func (d *MemoryDriver) UploadPart(key, uploadID string, number int, r io.Reader, mode ChecksumMode, checksum []byte) (string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	if mode != ChecksumNone && !bytes.Equal(mode.Sum(content), checksum) {
+		return "", ErrChecksum
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	u, err := d.upload(key, uploadID)
+	if err != nil {
+		return "", err
+	}
+
+	u.parts[number] = content
+	return strconv.Itoa(number), nil
+}
+
+func (d *MemoryDriver) CompleteMultipart(key, uploadID string, parts []Part) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	u, err := d.upload(key, uploadID)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	for _, p := range parts {
+		part, ok := u.parts[p.Number]
+		if !ok || p.ID != strconv.Itoa(p.Number) {
+			return fmt.Errorf("invalid part %d", p.Number)
+		}
+
+		content = append(content, part...)
+	}
+
+	delete(d.uploads, uploadID)
+	d.objects[key] = &memoryObject{
+		content:  content,
+		modTime:  time.Now(),
+		metadata: u.metadata,
+	}
+
+	return nil
+}
+
+func (d *MemoryDriver) AbortMultipart(key, uploadID string) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if _, err := d.upload(key, uploadID); err != nil {
+		return err
+	}
+
+	delete(d.uploads, uploadID)
+	return nil
+}
+
+func (d *MemoryDriver) upload(key, uploadID string) (*memoryUpload, error) {
+	u, ok := d.uploads[uploadID]
+	if !ok || u.key != key {
+		return nil, fmt.Errorf("unknown upload %q", uploadID)
+	}
+
+	return u, nil
+}
+
+func (o *memoryObject) object(key string) *Object {
+	return &Object{
+		Key:      key,
+		Size:     int64(len(o.content)),
+		ModTime:  o.modTime,
+		Metadata: copyMetadata(o.metadata),
+	}
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+
+	c := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		c[k] = v
+	}
+
+	return c
+}
@@ -0,0 +1,76 @@
+package blobfs
+
+import "gopkg.in/src-d/go-billy.v4/transport"
+
+const (
+	// DefaultPartSize is the default size of the parts of the multipart
+	// uploads.
+	DefaultPartSize = 16 << 20
+	// DefaultConcurrency is the default number of parts uploaded at the same
+	// time.
+	DefaultConcurrency = 4
+)
+
+// Options holds the settings of a Blob filesystem.
+type Options struct {
+	// PartSize is the size of the parts of the multipart uploads, the files
+	// bigger than it are written with a multipart upload, if the driver
+	// implements MultipartDriver.
+	PartSize int64
+	// Concurrency is the maximum number of parts of a file uploaded at the
+	// same time.
+	Concurrency int
+	// Checksum is the checksum sent with each part, for the store to verify
+	// it.
+	Checksum ChecksumMode
+	// Retry is the retry policy of the reads interrupted while the content
+	// of an object is read, resumed from the last offset read.
+	Retry transport.RetryPolicy
+}
+
+// Option configures an Options.
+type Option func(*Options)
+
+// WithPartSize sets the size of the parts of the multipart uploads.
+func WithPartSize(size int64) Option {
+	return func(o *Options) { o.PartSize = size }
+}
+
+// WithConcurrency sets the maximum number of parts uploaded at the same time.
+func WithConcurrency(n int) Option {
+	return func(o *Options) { o.Concurrency = n }
+}
+
+// WithChecksum sets the checksum sent with each part.
+func WithChecksum(mode ChecksumMode) Option {
+	return func(o *Options) { o.Checksum = mode }
+}
+
+// WithRetry sets the retry policy of the interrupted reads.
+func WithRetry(p transport.RetryPolicy) Option {
+	return func(o *Options) { o.Retry = p }
+}
+
+// NewOptions returns the Options resulting of applying opts to the defaults.
+// The settings out of range are replaced by the defaults.
+func NewOptions(opts ...Option) *Options {
+	o := &Options{
+		PartSize:    DefaultPartSize,
+		Concurrency: DefaultConcurrency,
+		Retry:       transport.DefaultRetryPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.PartSize <= 0 {
+		o.PartSize = DefaultPartSize
+	}
+
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultConcurrency
+	}
+
+	return o
+}
@@ -0,0 +1,121 @@
+package blobfs
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// UploadError reports the failure of a multipart upload.
+type UploadError struct {
+	// Key is the key of the object being written.
+	Key string
+	// Parts holds the error of each part that couldn't be uploaded, by
+	// number.
+	Parts map[int]error
+	// Err is the error starting or completing the upload, if any.
+	Err error
+	// AbortErr is the error discarding the failed upload, if any.
+	AbortErr error
+}
+
+// Numbers returns the numbers of the failed parts, sorted.
+func (e *UploadError) Numbers() []int {
+	numbers := make([]int, 0, len(e.Parts))
+	for n := range e.Parts {
+		numbers = append(numbers, n)
+	}
+
+	sort.Ints(numbers)
+	return numbers
+}
+
+func (e *UploadError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("upload of %q failed: %s", e.Key, e.Err)
+	}
+
+	numbers := e.Numbers()
+	if len(numbers) == 1 {
+		return fmt.Sprintf("upload of %q failed, part %d: %s",
+			e.Key, numbers[0], e.Parts[numbers[0]])
+	}
+
+	return fmt.Sprintf("upload of %q failed, %d parts, first %d: %s",
+		e.Key, len(numbers), numbers[0], e.Parts[numbers[0]])
+}
+
+// upload writes the content of the file to the store, with a multipart upload
+// if the driver supports it and the content is bigger than the part size.
+func (f *writer) upload() error {
+	md, ok := f.d.(MultipartDriver)
+	if !ok || f.content.Len() <= f.o.PartSize {
+		return f.d.Put(f.key, bytes.NewReader(f.content.Bytes()), f.metadata)
+	}
+
+	id, err := md.CreateMultipart(f.key, f.metadata)
+	if err != nil {
+		return &UploadError{Key: f.key, Err: err}
+	}
+
+	parts, errs := f.uploadParts(md, id)
+	if len(errs) == 0 {
+		err = md.CompleteMultipart(f.key, id, parts)
+		if err == nil {
+			return nil
+		}
+	}
+
+	return &UploadError{
+		Key:      f.key,
+		Parts:    errs,
+		Err:      err,
+		AbortErr: md.AbortMultipart(f.key, id),
+	}
+}
+
+// uploadParts uploads the content in parts of PartSize bytes, up to
+// Concurrency at the same time, returning the uploaded parts in order and the
+// errors of the failed ones, if any.
+func (f *writer) uploadParts(md MultipartDriver, id string) ([]Part, map[int]error) {
+	data := f.content.Bytes()
+	size := f.o.PartSize
+	count := int((int64(len(data)) + size - 1) / size)
+	parts := make([]Part, count)
+	errs := make(map[int]error)
+
+	var m sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, f.o.Concurrency)
+	for i := 0; i < count; i++ {
+		end := int64(i+1) * size
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+
+		content := data[int64(i)*size : end]
+		number := i + 1
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+
+			partID, err := md.UploadPart(f.key, id, number, bytes.NewReader(content),
+				f.o.Checksum, f.o.Checksum.Sum(content))
+
+			m.Lock()
+			defer m.Unlock()
+			if err != nil {
+				errs[number] = err
+				return
+			}
+
+			parts[i] = Part{Number: number, ID: partID}
+		}(i)
+	}
+
+	wg.Wait()
+	return parts, errs
+}
@@ -0,0 +1,78 @@
+// Command billy-serve serves a directory over HTTP, read-only, through the
+// public API of billy. The directory is served as a http.FileSystem, with the
+// listings and the range requests read by httpfs, along with the handshake of
+// the remote protocol.
+//
+// Usage:
+//
+//	billy-serve [-addr :8080] [-mem] <directory>
+//
+// The directory is opened with osfs.NewRooted, so the symbolic links can't
+// reach any file outside of it. With -mem, the directory is copied to memory
+// first, and served from memfs, with the permissions of the files kept but not
+// enforced.
+package main // import "gopkg.in/src-d/go-billy.v4/cmd/billy-serve"
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/spf13/afero"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/interop"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-billy.v4/remote"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	mem := flag.Bool("mem", false, "copy the directory to memory and serve it from there")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: billy-serve [flags] <directory>\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fs, err := open(flag.Arg(0), *mem)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("serving %s on %s", flag.Arg(0), *addr)
+	log.Fatal(http.ListenAndServe(*addr, newHandler(fs)))
+}
+
+// open returns the filesystem of dir, rooted at it, or a copy of it in memory
+// if mem is true.
+func open(dir string, mem bool) (billy.Filesystem, error) {
+	fs, err := osfs.NewRooted(dir)
+	if err != nil || !mem {
+		return fs, err
+	}
+
+	m := memfs.NewWithOptions(memfs.WithoutPermissions())
+	if err := util.CopyDir(m, fs, "/"); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// newHandler returns a handler serving fs, read-only, and the handshake of the
+// remote protocol at remote.HandshakePath.
+func newHandler(fs billy.Filesystem) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(remote.HandshakePath, remote.NewServer(fs))
+	mux.Handle("/", http.FileServer(afero.NewHttpFs(interop.ToAfero(fs)).Dir("/")))
+	return mux
+}
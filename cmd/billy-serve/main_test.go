@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4/httpfs"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/remote"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&ServeSuite{})
+
+type ServeSuite struct{}
+
+func (s *ServeSuite) TestServe(c *C) {
+	src := memfs.New()
+	c.Assert(util.WriteFile(src, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(src, "bar/qux", []byte("qux"), 0644), IsNil)
+
+	fs := memfs.NewWithOptions(memfs.WithoutPermissions())
+	c.Assert(util.CopyDir(fs, src, "/"), IsNil)
+
+	server := httptest.NewServer(newHandler(fs))
+	defer server.Close()
+
+	w, err := remote.Handshake(nil, server.URL)
+	c.Assert(err, IsNil)
+	c.Assert(w.Version, Equals, remote.MaxVersion)
+
+	client, err := httpfs.New(server.URL, nil)
+	c.Assert(err, IsNil)
+
+	entries, err := client.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+
+	f, err := client.Open("bar/qux")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "qux")
+
+	b := make([]byte, 2)
+	n, err := f.ReadAt(b, 1)
+	c.Assert(err, IsNil)
+	c.Assert(string(b[:n]), Equals, "ux")
+}
+
+func (s *ServeSuite) TestServeSymlinkOutside(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("symbolic links require privileges on windows")
+	}
+
+	outside := filepath.Join(c.MkDir(), "secret")
+	c.Assert(ioutil.WriteFile(outside, []byte("secret"), 0644), IsNil)
+
+	dir := c.MkDir()
+	c.Assert(os.Symlink(outside, filepath.Join(dir, "link")), IsNil)
+
+	fs, err := open(dir, false)
+	c.Assert(err, IsNil)
+
+	server := httptest.NewServer(newHandler(fs))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/link")
+	c.Assert(err, IsNil)
+	defer res.Body.Close()
+
+	content, err := ioutil.ReadAll(res.Body)
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Not(Equals), http.StatusOK)
+	c.Assert(string(content), Not(Equals), "secret")
+}
+
+func (s *ServeSuite) TestOpenMemReadOnlyDir(c *C) {
+	dir := c.MkDir()
+	c.Assert(os.Mkdir(filepath.Join(dir, "ro"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "ro", "file"), []byte("foo"), 0644), IsNil)
+	c.Assert(os.Chmod(filepath.Join(dir, "ro"), 0555), IsNil)
+	defer os.Chmod(filepath.Join(dir, "ro"), 0755)
+
+	fs, err := open(dir, true)
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("ro/file")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
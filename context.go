@@ -0,0 +1,25 @@
+package billy
+
+import "context"
+
+// Contexter interface can bind the operations of a filesystem to a context, as
+// an extension to the Filesystem interface. It allows to cancel, or to set a
+// deadline to, the operations of the filesystems accessed through a network.
+type Contexter interface {
+	// WithContext returns a view of the filesystem whose operations, and the
+	// operations of the files opened through it, fail with the error of ctx
+	// once it's done. The operations in progress may be interrupted, or may
+	// complete if the filesystem can't interrupt them.
+	WithContext(ctx context.Context) Filesystem
+}
+
+// WithContext returns a view of fs bound to ctx, if fs implements the
+// Contexter interface, otherwise it returns fs, ignoring ctx.
+func WithContext(fs Filesystem, ctx context.Context) Filesystem {
+	c, ok := fs.(Contexter)
+	if !ok {
+		return fs
+	}
+
+	return c.WithContext(ctx)
+}
@@ -0,0 +1,78 @@
+package billy
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// The canonical errors of the filesystems. The operations return them wrapped
+// in a *os.PathError, or a *os.LinkError for the ones taking two paths, so
+// they can be classified with os.IsNotExist, os.IsExist, os.IsPermission,
+// IsNotDir, IsDir, IsNotEmpty and IsTooManyLinks, regardless of the
+// filesystem. The errors of osfs are the ones of the OS, classified as well.
+var (
+	// ErrNotExist is returned when a file doesn't exist.
+	ErrNotExist = os.ErrNotExist
+	// ErrExist is returned when a file already exists.
+	ErrExist = os.ErrExist
+	// ErrPermission is returned when an operation isn't permitted.
+	ErrPermission = os.ErrPermission
+	// ErrNotDir is returned when a directory is expected, e.g. as a parent
+	// of a path, and a file is found.
+	ErrNotDir = errors.New("not a directory")
+	// ErrIsDir is returned when a file is expected, e.g. to be opened for
+	// writing, and a directory is found.
+	ErrIsDir = errors.New("is a directory")
+	// ErrNotEmpty is returned when removing a directory that isn't empty.
+	ErrNotEmpty = errors.New("directory not empty")
+	// ErrTooManyLinks is returned when resolving a path follows more than
+	// MaxSymlinks symbolic links, as a cycle of them does.
+	ErrTooManyLinks = errors.New("too many levels of symbolic links")
+)
+
+// MaxSymlinks is the maximum number of symbolic links followed resolving a
+// path, the limit of Linux.
+const MaxSymlinks = 40
+
+// IsNotDir returns a boolean indicating whether the error is known to report
+// that a directory was expected and a file was found.
+func IsNotDir(err error) bool {
+	err = underlyingError(err)
+	return err == ErrNotDir || err == syscall.ENOTDIR
+}
+
+// IsDir returns a boolean indicating whether the error is known to report that
+// a file was expected and a directory was found.
+func IsDir(err error) bool {
+	err = underlyingError(err)
+	return err == ErrIsDir || err == syscall.EISDIR
+}
+
+// IsNotEmpty returns a boolean indicating whether the error is known to report
+// that a directory isn't empty.
+func IsNotEmpty(err error) bool {
+	err = underlyingError(err)
+	return err == ErrNotEmpty || isNotEmptyErrno(err)
+}
+
+// IsTooManyLinks returns a boolean indicating whether the error is known to
+// report that too many symbolic links were followed resolving a path.
+func IsTooManyLinks(err error) bool {
+	err = underlyingError(err)
+	return err == ErrTooManyLinks || err == syscall.ELOOP
+}
+
+// underlyingError returns the error wrapped by the errors of the os package.
+func underlyingError(err error) error {
+	switch e := err.(type) {
+	case *os.PathError:
+		return e.Err
+	case *os.LinkError:
+		return e.Err
+	case *os.SyscallError:
+		return e.Err
+	}
+
+	return err
+}
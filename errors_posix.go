@@ -0,0 +1,11 @@
+// +build !windows
+
+package billy
+
+import "syscall"
+
+// isNotEmptyErrno returns true for the errors of rmdir on a directory not
+// empty, some systems return EEXIST instead of ENOTEMPTY.
+func isNotEmptyErrno(err error) bool {
+	return err == syscall.ENOTEMPTY || err == syscall.EEXIST
+}
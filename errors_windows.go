@@ -0,0 +1,12 @@
+// +build windows
+
+package billy
+
+import "syscall"
+
+// errorDirNotEmpty is ERROR_DIR_NOT_EMPTY, returned by RemoveDirectory.
+const errorDirNotEmpty syscall.Errno = 145
+
+func isNotEmptyErrno(err error) bool {
+	return err == syscall.ENOTEMPTY || err == errorDirNotEmpty
+}
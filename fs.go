@@ -4,6 +4,8 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,8 @@ var (
 	ErrReadOnly        = errors.New("read-only filesystem")
 	ErrNotSupported    = errors.New("feature not supported")
 	ErrCrossedBoundary = errors.New("chroot boundary crossed")
+	ErrNameTooLong     = errors.New("file name too long")
+	ErrPathTooLong     = errors.New("path too long")
 )
 
 // Capability holds the supported features of a billy filesystem. This does
@@ -32,6 +36,19 @@ const (
 	TruncateCapability
 	// LockCapability is the ability to lock a file.
 	LockCapability
+	// SymlinkCapability means that symbolic links can be created and read.
+	SymlinkCapability
+	// CreateExclusiveCapability means that opening a file with O_CREATE and
+	// O_EXCL is atomic, it fails if the file exists even if it is created
+	// concurrently.
+	CreateExclusiveCapability
+	// PermissionCapability means that the permissions of the files and
+	// directories are enforced, as the ones of their owner: a file can't be
+	// opened for reading or writing without the read or write permission, the
+	// entries of a directory can't be listed without its read permission, or
+	// changed without its write and search permissions, and the files under
+	// a directory can't be reached without its search permission.
+	PermissionCapability
 
 	// DefaultCapabilities lists all capable features supported by filesystems
 	// without Capability interface. This list should not be changed until a
@@ -43,7 +60,8 @@ const (
 	// AllCapabilities lists all capable features.
 	AllCapabilities Capability = WriteCapability | ReadCapability |
 		ReadAndWriteCapability | SeekCapability | TruncateCapability |
-		LockCapability
+		LockCapability | SymlinkCapability | CreateExclusiveCapability |
+		PermissionCapability
 )
 
 // Filesystem abstract the operations in a storage-agnostic interface.
@@ -72,6 +90,14 @@ type Basic interface {
 	// instead. It opens the named file with specified flag (O_RDONLY etc.) and
 	// perm, (0666 etc.) if applicable. If successful, methods on the returned
 	// File can be used for I/O.
+	//
+	// The flags follow the POSIX semantics: O_RDONLY, O_WRONLY and O_RDWR set
+	// the access mode, O_CREATE creates the file with perm if it doesn't
+	// exist, O_EXCL along with O_CREATE fails with an error satisfying
+	// os.IsExist if the file, or a symbolic link, exists, O_TRUNC truncates a
+	// file opened for writing, and O_APPEND makes every write happen at the
+	// end of the file. perm is ignored for the existing files. The
+	// OpenFileSuite of the test package validates them.
 	OpenFile(filename string, flag int, perm os.FileMode) (File, error)
 	// Stat returns a FileInfo describing the named file.
 	Stat(filename string) (os.FileInfo, error)
@@ -98,6 +124,13 @@ type TempFile interface {
 	// It is the caller's responsibility to remove the file when no longer
 	// needed.
 	TempFile(dir, prefix string) (File, error)
+	// TempDir creates a new temporary directory in the directory dir with a
+	// name beginning with prefix and returns the path of the new directory.
+	// If dir is the empty string, TempDir uses the default directory for
+	// temporary files (see os.TempDir). Multiple programs calling TempDir
+	// simultaneously will not choose the same directory. It is the caller's
+	// responsibility to remove the directory when no longer needed.
+	TempDir(dir, prefix string) (string, error)
 }
 
 // Dir abstract the dir related operations in a storage-agnostic interface as
@@ -160,6 +193,14 @@ type Chroot interface {
 }
 
 // File represent a file, being a subset of the os.File
+//
+// Seek follows the semantics of os.File: the offset is relative to the start,
+// the current offset or the end of the file, seeking past the end is allowed,
+// and seeking to a negative offset or with an unknown whence fails, keeping
+// the current offset. Reading past the end returns io.EOF, and writing past
+// the end fills the gap with zeros. The writes of a file opened with
+// os.O_APPEND are done at the end, regardless of the offset. The SeekSuite of
+// the test package validates them.
 type File interface {
 	// Name returns the name of the file as presented to Open.
 	Name() string
@@ -177,6 +218,44 @@ type File interface {
 	Truncate(size int64) error
 }
 
+// Syncer interface can commit the content of a file to stable storage, as an
+// extension to the File interface.
+type Syncer interface {
+	// Sync commits the current content of the file to stable storage.
+	Sync() error
+}
+
+// Sync commits the content of the file to stable storage, if the file
+// implements the Syncer interface, otherwise it does nothing.
+func Sync(f File) error {
+	s, ok := f.(Syncer)
+	if !ok {
+		return nil
+	}
+
+	return s.Sync()
+}
+
+// Linker interface can create hard links, as an extension to the Basic
+// interface.
+type Linker interface {
+	// Link creates newname as a hard link to the oldname file, sharing its
+	// content. If oldname is a symbolic link, the link itself is linked.
+	Link(oldname, newname string) error
+}
+
+// Link creates newname as a hard link to the oldname file, if the FS
+// implements the Linker interface, otherwise it returns a *os.LinkError with
+// ErrNotSupported.
+func Link(fs Basic, oldname, newname string) error {
+	l, ok := fs.(Linker)
+	if !ok {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: ErrNotSupported}
+	}
+
+	return l.Link(oldname, newname)
+}
+
 // Capable interface can return the available features of a filesystem.
 type Capable interface {
 	// Capabilities returns the capabilities of a filesystem in bit flags.
@@ -184,14 +263,19 @@ type Capable interface {
 }
 
 // Capabilities returns the features supported by a filesystem. If the FS
-// does not implement Capable interface it returns all features.
+// does not implement Capable interface it returns DefaultCapabilities, plus
+// SymlinkCapability if it implements the Symlink interface.
 func Capabilities(fs Basic) Capability {
 	capable, ok := fs.(Capable)
-	if !ok {
-		return DefaultCapabilities
+	if ok {
+		return capable.Capabilities()
 	}
 
-	return capable.Capabilities()
+	if _, ok := fs.(Symlink); ok {
+		return DefaultCapabilities | SymlinkCapability
+	}
+
+	return DefaultCapabilities
 }
 
 // CapabilityCheck tests the filesystem for the provided capabilities and
@@ -200,3 +284,83 @@ func CapabilityCheck(fs Basic, capabilities Capability) bool {
 	fsCaps := Capabilities(fs)
 	return fsCaps&capabilities == capabilities
 }
+
+// Normalization is the unicode normalization form applied by a filesystem to
+// the names of its files.
+type Normalization int
+
+const (
+	// NoNormalization means that names are stored as given.
+	NoNormalization Normalization = iota
+	// NFC means that names are stored in unicode Normalization Form C.
+	NFC
+	// NFD means that names are stored in unicode Normalization Form D.
+	NFD
+)
+
+// Description holds the path related properties of a billy filesystem, it
+// allows to validate names before trying to write them. As with Capability,
+// it describes the billy filesystem and not necessarily the storage behind it.
+type Description struct {
+	// CaseSensitive is true when names differing only in case refer to
+	// different files.
+	CaseSensitive bool
+	// CasePreserving is true when names keep the case given at creation.
+	CasePreserving bool
+	// Normalization is the unicode normalization applied to names.
+	Normalization Normalization
+	// MaxPathLength is the maximum length in bytes of a path, 0 means
+	// unlimited.
+	MaxPathLength int
+	// MaxNameLength is the maximum length in bytes of a path element, 0
+	// means unlimited.
+	MaxNameLength int
+}
+
+// DefaultDescription is the description of filesystems without Describer
+// interface.
+var DefaultDescription = Description{
+	CaseSensitive:  true,
+	CasePreserving: true,
+}
+
+// Validate checks the given path against the length limits of the
+// description, returning ErrPathTooLong or ErrNameTooLong if any is exceeded.
+func (d Description) Validate(path string) error {
+	if d.MaxPathLength > 0 && len(path) > d.MaxPathLength {
+		return ErrPathTooLong
+	}
+
+	if d.MaxNameLength <= 0 {
+		return nil
+	}
+
+	for _, name := range strings.FieldsFunc(path, isSeparator) {
+		if len(name) > d.MaxNameLength {
+			return ErrNameTooLong
+		}
+	}
+
+	return nil
+}
+
+func isSeparator(r rune) bool {
+	return r == '/' || r == filepath.Separator
+}
+
+// Describer interface can return the path related properties of a filesystem.
+type Describer interface {
+	// Describe returns the description of a filesystem.
+	Describe() Description
+}
+
+// Describe returns the path related properties of a filesystem. If the FS
+// does not implement Describer interface it returns DefaultDescription.
+func Describe(fs Basic) Description {
+	describer, ok := fs.(Describer)
+	if !ok {
+		return DefaultDescription
+	}
+
+	return describer.Describe()
+}
@@ -1,10 +1,17 @@
 package billy_test
 
 import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"syscall"
 	"testing"
 
 	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
 	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
 
 	. "gopkg.in/check.v1"
 )
@@ -37,4 +44,208 @@ func (s *FSSuite) TestCapabilities(c *C) {
 
 	dummy := new(test.BasicMock)
 	c.Assert(Capabilities(dummy), Equals, DefaultCapabilities)
+
+	symlink := new(test.SymlinkMock)
+	c.Assert(Capabilities(symlink), Equals, DefaultCapabilities|SymlinkCapability)
+	c.Assert(CapabilityCheck(symlink, SymlinkCapability), Equals, true)
+}
+
+func (s *FSSuite) TestDescribe(c *C) {
+	c.Assert(Describe(new(test.BasicMock)), Equals, DefaultDescription)
+
+	d := Description{CaseSensitive: false, MaxNameLength: 255}
+	fs := &test.DescribedFs{Description: d}
+	c.Assert(Describe(fs), Equals, d)
+}
+
+func (s *FSSuite) TestDescriptionValidate(c *C) {
+	d := Description{MaxPathLength: 10, MaxNameLength: 3}
+
+	c.Assert(d.Validate("foo/bar"), IsNil)
+	c.Assert(d.Validate("foo/barr"), Equals, ErrNameTooLong)
+	c.Assert(d.Validate("foo/bar/qux"), Equals, ErrPathTooLong)
+	c.Assert(DefaultDescription.Validate(strings.Repeat("a", 1024)), IsNil)
+}
+
+type optionKey struct{}
+
+func (s *FSSuite) TestNewOpenOptions(c *C) {
+	o := NewOpenOptions(
+		WithBufferSize(1024), WithMmap(), WithDirectIO(), WithSnapshot(),
+		WithValue(optionKey{}, "foo"),
+	)
+
+	c.Assert(o.BufferSize, Equals, 1024)
+	c.Assert(o.Mmap, Equals, true)
+	c.Assert(o.DirectIO, Equals, true)
+	c.Assert(o.Snapshot, Equals, true)
+	c.Assert(o.Value(optionKey{}), Equals, "foo")
+	c.Assert(NewOpenOptions().Value(optionKey{}), IsNil)
+}
+
+func (s *FSSuite) TestOpenFileOpt(c *C) {
+	var filtered []string
+	filter := func(name string) OpenOption {
+		return WithFilter(func(f File) (File, error) {
+			filtered = append(filtered, name)
+			return f, nil
+		})
+	}
+
+	fs := new(test.BasicMock)
+	f, err := OpenFileOpt(fs, "foo", 0, 0, WithMmap(), filter("a"), filter("b"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, "foo")
+	c.Assert(filtered, DeepEquals, []string{"a", "b"})
+	c.Assert(fs.OpenFileArgs, HasLen, 1)
+
+	opener := new(test.OptionOpenerFs)
+	_, err = OpenFileOpt(opener, "foo", 0, 0, WithMmap())
+	c.Assert(err, IsNil)
+	c.Assert(opener.OpenFileOptArgs, HasLen, 1)
+	c.Assert(opener.OpenFileOptArgs[0], HasLen, 1)
+}
+
+type syncerFile struct {
+	test.FileMock
+	synced bool
+}
+
+func (f *syncerFile) Sync() error {
+	f.synced = true
+	return nil
+}
+
+func (s *FSSuite) TestSync(c *C) {
+	c.Assert(Sync(&test.FileMock{}), IsNil)
+
+	f := &syncerFile{}
+	c.Assert(Sync(f), IsNil)
+	c.Assert(f.synced, Equals, true)
+}
+
+func (s *FSSuite) TestRemoveMany(c *C) {
+	m := &test.BasicMock{}
+	c.Assert(RemoveMany(m, []string{"foo", "bar"}), IsNil)
+	c.Assert(m.RemoveArgs, DeepEquals, []string{"foo", "bar"})
+
+	fs := memfs.New()
+	c.Assert(util.WriteFile(fs, "foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "bar/qux", nil, 0644), IsNil)
+
+	err := RemoveMany(fs, []string{"foo", "missing", "bar"})
+	c.Assert(err, FitsTypeOf, &RemoveManyError{})
+	c.Assert(err.(*RemoveManyError).Paths(), DeepEquals, []string{"bar"})
+
+	_, err = fs.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *FSSuite) TestWithContext(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fs := WithContext(memfs.New(), ctx)
+	_, err := fs.Create("foo")
+	c.Assert(err, Equals, context.Canceled)
+}
+
+func (s *FSSuite) TestWithContextNotSupported(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fs := &noContextFS{memfs.New()}
+	c.Assert(WithContext(fs, ctx), Equals, fs)
+}
+
+type noContextFS struct {
+	Filesystem
+}
+
+func (s *FSSuite) TestNewDirIter(c *C) {
+	fs := memfs.New()
+	c.Assert(util.WriteFile(fs, "foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "bar", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "qux", nil, 0644), IsNil)
+
+	entries, err := fs.ReadDir("/")
+	c.Assert(err, IsNil)
+
+	i := NewDirIter(entries)
+	page, err := i.Next(2)
+	c.Assert(err, IsNil)
+	c.Assert(page, DeepEquals, entries[:2])
+
+	page, err = i.Next(2)
+	c.Assert(err, IsNil)
+	c.Assert(page, DeepEquals, entries[2:])
+
+	page, err = i.Next(2)
+	c.Assert(err, Equals, io.EOF)
+	c.Assert(page, HasLen, 0)
+	c.Assert(i.Close(), IsNil)
+}
+
+func (s *FSSuite) TestErrorPredicates(c *C) {
+	cases := []struct {
+		err                     error
+		notDir, isDir, notEmpty bool
+	}{
+		{&os.PathError{Op: "open", Path: "foo", Err: ErrNotDir}, true, false, false},
+		{&os.PathError{Op: "open", Path: "foo", Err: syscall.ENOTDIR}, true, false, false},
+		{&os.PathError{Op: "open", Path: "foo", Err: ErrIsDir}, false, true, false},
+		{&os.PathError{Op: "open", Path: "foo", Err: syscall.EISDIR}, false, true, false},
+		{&os.PathError{Op: "remove", Path: "foo", Err: ErrNotEmpty}, false, false, true},
+		{&os.LinkError{Op: "rename", Old: "foo", New: "bar", Err: ErrNotDir}, true, false, false},
+		{ErrIsDir, false, true, false},
+		{os.ErrNotExist, false, false, false},
+		{nil, false, false, false},
+	}
+
+	for _, tc := range cases {
+		c.Assert(IsNotDir(tc.err), Equals, tc.notDir, Commentf("%v", tc.err))
+		c.Assert(IsDir(tc.err), Equals, tc.isDir, Commentf("%v", tc.err))
+		c.Assert(IsNotEmpty(tc.err), Equals, tc.notEmpty, Commentf("%v", tc.err))
+	}
+
+	c.Assert(IsTooManyLinks(&os.PathError{Op: "stat", Path: "foo", Err: ErrTooManyLinks}), Equals, true)
+	c.Assert(IsTooManyLinks(&os.PathError{Op: "stat", Path: "foo", Err: syscall.ELOOP}), Equals, true)
+	c.Assert(IsTooManyLinks(ErrNotDir), Equals, false)
+
+	c.Assert(os.IsNotExist(&os.PathError{Op: "stat", Path: "foo", Err: ErrNotExist}), Equals, true)
+	c.Assert(os.IsExist(&os.PathError{Op: "open", Path: "foo", Err: ErrExist}), Equals, true)
+	c.Assert(os.IsPermission(&os.PathError{Op: "open", Path: "foo", Err: ErrPermission}), Equals, true)
+}
+
+func (s *FSSuite) TestSplitPath(c *C) {
+	for path, expected := range map[string][]string{
+		"":            nil,
+		"/":           nil,
+		".":           nil,
+		"foo":         {"foo"},
+		"/foo//bar/":  {"foo", "bar"},
+		"./foo/./bar": {"foo", "bar"},
+		"foo/../bar":  {"foo", "..", "bar"},
+	} {
+		c.Assert(SplitPath(path), DeepEquals, expected, Commentf("%s", path))
+	}
+}
+
+func (s *FSSuite) TestSlashPath(c *C) {
+	for path, expected := range map[string]string{
+		"":           ".",
+		"/":          ".",
+		"foo":        "foo",
+		"/foo//bar/": "foo/bar",
+		"foo/../bar": "bar",
+		"../foo":     "../foo",
+	} {
+		c.Assert(SlashPath(path), Equals, expected, Commentf("%s", path))
+	}
+}
+
+func (s *FSSuite) TestIsAbs(c *C) {
+	c.Assert(IsAbs("/foo"), Equals, true)
+	c.Assert(IsAbs("foo"), Equals, false)
+	c.Assert(IsAbs("./foo"), Equals, false)
 }
@@ -1,7 +1,11 @@
 module gopkg.in/src-d/go-billy.v4
 
+go 1.13
+
 require (
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/kr/pretty v0.1.0 // indirect
-	golang.org/x/sys v0.0.0-20190726091711-fc99dfbffb4e
+	github.com/spf13/afero v1.2.2
+	golang.org/x/sys v0.0.0-20191005200804-aed5e4c7ecf9
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127
 )
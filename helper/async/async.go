@@ -0,0 +1,463 @@
+// Package async provides a helper that writes the files to a billy filesystem
+// asynchronously, with a bounded number of writes in flight.
+package async // import "gopkg.in/src-d/go-billy.v4/helper/async"
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/internal/buffer"
+)
+
+// WriteError is the error of a write done asynchronously.
+type WriteError struct {
+	Filename string
+	Err      error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("async write of %s: %s", e.Filename, e.Err)
+}
+
+// Async is a helper that buffers in memory the files created, or truncated,
+// and writes them to the underlying filesystem in the background once closed.
+// At most a given number of writes are in flight, when the budget is
+// exhausted closing a file blocks until one of them completes.
+//
+// The rest of the operations are done synchronously, after waiting for the
+// writes in flight of the paths involved, any path inside them, or any of
+// their parents, so the files written are always visible through Async,
+// except through symbolic links created before them. Creating a file over a
+// directory, or inside a file, fails on open. Any other failure of the
+// asynchronous writes is returned, once, by the next operation, or by Flush.
+type Async struct {
+	underlying billy.Filesystem
+	budget     chan struct{}
+
+	m       sync.Mutex
+	cond    *sync.Cond
+	pending map[string]int
+	err     error
+}
+
+// New creates a new filesystem wrapping up fs, with at most maxInFlight
+// asynchronous writes in flight.
+func New(fs billy.Filesystem, maxInFlight int) *Async {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+
+	h := &Async{
+		underlying: fs,
+		budget:     make(chan struct{}, maxInFlight),
+		pending:    make(map[string]int),
+	}
+
+	h.cond = sync.NewCond(&h.m)
+	return h
+}
+
+func (h *Async) Create(filename string) (billy.File, error) {
+	return h.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (h *Async) Open(filename string) (billy.File, error) {
+	return h.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+// OpenFile opens the named file, if it is created or truncated the file is
+// buffered and written asynchronously when closed.
+func (h *Async) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	return h.OpenFileOpt(filename, flag, perm)
+}
+
+// OpenFileOpt implements the OptionOpener interface. The options of the
+// buffered files are used when they are written to the underlying filesystem.
+func (h *Async) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	if err := h.wait(filename); err != nil {
+		return nil, err
+	}
+
+	if !isBuffered(flag) {
+		return billy.OpenFileOpt(h.underlying, filename, flag, perm, opts...)
+	}
+
+	if err := h.checkCreate(filename); err != nil {
+		return nil, err
+	}
+
+	w := &write{filename: filename, flag: flag, perm: perm, opts: opts, content: buffer.New(nil)}
+	return &file{name: relative(filename), h: h, w: w}, nil
+}
+
+// checkCreate returns an error if a file can't be created at filename, being
+// a directory, or having a file as any of its parents.
+func (h *Async) checkCreate(filename string) error {
+	fi, err := h.underlying.Stat(filename)
+	if err == nil && fi.IsDir() {
+		return &os.PathError{Op: "open", Path: filename, Err: billy.ErrIsDir}
+	}
+
+	for dir := filepath.Dir(filename); ; dir = filepath.Dir(dir) {
+		fi, err := h.underlying.Stat(dir)
+		switch {
+		case err == nil && !fi.IsDir():
+			return &os.PathError{Op: "open", Path: filename, Err: billy.ErrNotDir}
+		case err == nil:
+			return nil
+		case !os.IsNotExist(err):
+			return err
+		}
+
+		if parent := filepath.Dir(dir); parent == dir {
+			return nil
+		}
+	}
+}
+
+func isBuffered(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR) != 0 &&
+		flag&os.O_CREATE != 0 && flag&os.O_TRUNC != 0 && flag&os.O_EXCL == 0
+}
+
+func (h *Async) Stat(filename string) (os.FileInfo, error) {
+	if err := h.wait(filename); err != nil {
+		return nil, err
+	}
+
+	return h.underlying.Stat(filename)
+}
+
+func (h *Async) Lstat(filename string) (os.FileInfo, error) {
+	if err := h.wait(filename); err != nil {
+		return nil, err
+	}
+
+	return h.underlying.Lstat(filename)
+}
+
+func (h *Async) ReadDir(path string) ([]os.FileInfo, error) {
+	if err := h.wait(path); err != nil {
+		return nil, err
+	}
+
+	return h.underlying.ReadDir(path)
+}
+
+func (h *Async) Readlink(link string) (string, error) {
+	if err := h.wait(link); err != nil {
+		return "", err
+	}
+
+	return h.underlying.Readlink(link)
+}
+
+func (h *Async) Rename(from, to string) error {
+	if err := h.wait(from, to); err != nil {
+		return err
+	}
+
+	return h.underlying.Rename(from, to)
+}
+
+func (h *Async) Remove(filename string) error {
+	if err := h.wait(filename); err != nil {
+		return err
+	}
+
+	return h.underlying.Remove(filename)
+}
+
+func (h *Async) MkdirAll(filename string, perm os.FileMode) error {
+	if err := h.wait(filename); err != nil {
+		return err
+	}
+
+	return h.underlying.MkdirAll(filename, perm)
+}
+
+// Symlink creates a symbolic link, after waiting for all the writes in flight,
+// since any of them may be the target.
+func (h *Async) Symlink(target, link string) error {
+	if err := h.wait(); err != nil {
+		return err
+	}
+
+	return h.underlying.Symlink(target, link)
+}
+
+// TempFile creates a temporary file synchronously, since its name must be
+// unique.
+func (h *Async) TempFile(dir, prefix string) (billy.File, error) {
+	if err := h.wait(); err != nil {
+		return nil, err
+	}
+
+	return h.underlying.TempFile(dir, prefix)
+}
+
+// TempDir creates a temporary directory synchronously, since its name must be
+// unique.
+func (h *Async) TempDir(dir, prefix string) (string, error) {
+	if err := h.wait(); err != nil {
+		return "", err
+	}
+
+	return h.underlying.TempDir(dir, prefix)
+}
+
+func (h *Async) Join(elem ...string) string {
+	return h.underlying.Join(elem...)
+}
+
+// Chroot returns a chroot of the async filesystem, sharing the writes in
+// flight and their budget.
+func (h *Async) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Async) Root() string {
+	return h.underlying.Root()
+}
+
+// Capabilities implements the Capable interface. The permissions denying a
+// write are only reported once it's done, so PermissionCapability isn't
+// declared.
+func (h *Async) Capabilities() billy.Capability {
+	return billy.Capabilities(h.underlying) &^ billy.PermissionCapability
+}
+
+// Describe implements the Describer interface.
+func (h *Async) Describe() billy.Description {
+	return billy.Describe(h.underlying)
+}
+
+// Flush waits for all the writes in flight, returning the first failure since
+// the last time an error was returned.
+func (h *Async) Flush() error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	for len(h.pending) > 0 {
+		h.cond.Wait()
+	}
+
+	return h.takeError()
+}
+
+// Close flushes the filesystem.
+func (h *Async) Close() error {
+	return h.Flush()
+}
+
+// wait waits for the writes in flight of the given paths, any path inside
+// them, or any of their parents, or of every path if none is given. It returns the first failure of
+// the asynchronous writes since the last time an error was returned.
+func (h *Async) wait(paths ...string) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	for h.isPending(paths) {
+		h.cond.Wait()
+	}
+
+	return h.takeError()
+}
+
+func (h *Async) isPending(paths []string) bool {
+	if len(paths) == 0 {
+		return len(h.pending) > 0
+	}
+
+	for _, p := range paths {
+		p = normalize(p)
+		for pending := range h.pending {
+			if p == "/" || pending == p || strings.HasPrefix(pending, p+"/") ||
+				strings.HasPrefix(p, pending+"/") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (h *Async) takeError() error {
+	err := h.err
+	h.err = nil
+	return err
+}
+
+// enqueue writes w in the background, blocking while the budget is exhausted.
+func (h *Async) enqueue(w *write) {
+	h.budget <- struct{}{}
+
+	p := normalize(w.filename)
+	h.m.Lock()
+	for h.pending[p] > 0 {
+		h.cond.Wait()
+	}
+
+	h.pending[p]++
+	h.m.Unlock()
+
+	go func() {
+		err := w.do(h.underlying)
+
+		h.m.Lock()
+		if err != nil && h.err == nil {
+			h.err = &WriteError{Filename: w.filename, Err: err}
+		}
+
+		if h.pending[p]--; h.pending[p] == 0 {
+			delete(h.pending, p)
+		}
+
+		h.cond.Broadcast()
+		h.m.Unlock()
+		<-h.budget
+	}()
+}
+
+func normalize(path string) string {
+	return filepath.ToSlash(filepath.Join(string(filepath.Separator), path))
+}
+
+// relative returns the given path cleaned and relative to the root, as the
+// names of the files returned by the chroot helper.
+func relative(path string) string {
+	return filepath.FromSlash(normalize(path)[1:])
+}
+
+// write is a file buffered in memory, to be written to a filesystem.
+type write struct {
+	filename string
+	flag     int
+	perm     os.FileMode
+	opts     []billy.OpenOption
+	content  *buffer.Buffer
+}
+
+func (w *write) do(fs billy.Filesystem) error {
+	f, err := billy.OpenFileOpt(fs, w.filename, w.flag, w.perm, w.opts...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(w.content.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// file is a file buffered in memory, enqueued to be written when closed.
+type file struct {
+	name     string
+	h        *Async
+	w        *write
+	isClosed bool
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+func (f *file) Read(b []byte) (int, error) {
+	if err := f.checkRead(); err != nil {
+		return 0, err
+	}
+
+	return f.w.content.Read(b)
+}
+
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if err := f.checkRead(); err != nil {
+		return 0, err
+	}
+
+	n, err := f.w.content.ReadAt(b, off)
+	if err != nil && err != io.EOF {
+		err = &os.PathError{Op: "readat", Path: f.name, Err: err}
+	}
+
+	return n, err
+}
+
+func (f *file) checkRead() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	if f.w.flag&os.O_WRONLY != 0 {
+		return &os.PathError{Op: "read", Path: f.name, Err: os.ErrPermission}
+	}
+
+	return nil
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	if f.w.flag&os.O_APPEND != 0 {
+		f.w.content.Seek(0, io.SeekEnd)
+	}
+
+	return f.w.content.Write(p)
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	offset, err := f.w.content.Seek(offset, whence)
+	if err != nil {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: err}
+	}
+
+	return offset, nil
+}
+
+func (f *file) Truncate(size int64) error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	if err := f.w.content.Truncate(size); err != nil {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: err}
+	}
+
+	return nil
+}
+
+// Close enqueues the file to be written, blocking while the budget of writes
+// in flight is exhausted.
+func (f *file) Close() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	f.isClosed = true
+	f.h.enqueue(f.w)
+	return nil
+}
+
+// Lock is a no-op, the file is only written when closed.
+func (f *file) Lock() error {
+	return nil
+}
+
+// Unlock is a no-op, the file is only written when closed.
+func (f *file) Unlock() error {
+	return nil
+}
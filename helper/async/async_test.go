@@ -0,0 +1,150 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&AsyncSuite{})
+
+type AsyncSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *AsyncSuite) SetUpTest(c *C) {
+	s.FilesystemSuite = test.NewFilesystemSuite(New(memfs.New(), 4))
+}
+
+// blockingFS blocks the writes until released.
+type blockingFS struct {
+	billy.Filesystem
+	release chan struct{}
+	fail    bool
+}
+
+func (fs *blockingFS) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	<-fs.release
+	if fs.fail {
+		return nil, errors.New("foo")
+	}
+
+	return fs.Filesystem.OpenFile(filename, flag, perm)
+}
+
+func (s *AsyncSuite) TestWriteIsAsync(c *C) {
+	underlying := &blockingFS{Filesystem: memfs.New(), release: make(chan struct{})}
+	fs := New(underlying, 2)
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+
+	_, err := underlying.Filesystem.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	close(underlying.release)
+	c.Assert(fs.Flush(), IsNil)
+
+	_, err = underlying.Filesystem.Stat("foo")
+	c.Assert(err, IsNil)
+}
+
+func (s *AsyncSuite) TestBackpressure(c *C) {
+	underlying := &blockingFS{Filesystem: memfs.New(), release: make(chan struct{})}
+	fs := New(underlying, 2)
+
+	c.Assert(util.WriteFile(fs, "foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "bar", nil, 0644), IsNil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	done := make(chan struct{})
+	go func() {
+		defer wg.Done()
+		util.WriteFile(fs, "qux", nil, 0644)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		c.Fatal("write not blocked with the budget exhausted")
+	default:
+	}
+
+	underlying.release <- struct{}{}
+	wg.Wait()
+
+	close(underlying.release)
+	c.Assert(fs.Flush(), IsNil)
+}
+
+func (s *AsyncSuite) TestErrorSurfacing(c *C) {
+	underlying := &blockingFS{Filesystem: memfs.New(), release: make(chan struct{}), fail: true}
+	close(underlying.release)
+	fs := New(underlying, 2)
+
+	c.Assert(util.WriteFile(fs, "foo", nil, 0644), IsNil)
+
+	err := fs.Flush()
+	c.Assert(err, FitsTypeOf, &WriteError{})
+	c.Assert(err.(*WriteError).Filename, Equals, "foo")
+	c.Assert(fs.Flush(), IsNil)
+
+	c.Assert(util.WriteFile(fs, "bar", nil, 0644), IsNil)
+	_, err = fs.Stat("bar")
+	c.Assert(err, FitsTypeOf, &WriteError{})
+}
+
+func (s *AsyncSuite) TestReadAfterWrite(c *C) {
+	fs := New(memfs.New(), 8)
+	for i := 0; i < 100; i++ {
+		err := util.WriteFile(fs, fmt.Sprintf("foo/%d", i), []byte("foo"), 0644)
+		c.Assert(err, IsNil)
+	}
+
+	entries, err := fs.ReadDir("foo")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 100)
+	c.Assert(fs.Close(), IsNil)
+}
+
+func (s *AsyncSuite) TestTruncateNegative(c *C) {
+	fs := New(memfs.New(), 4)
+	f, err := fs.Create("foo")
+	c.Assert(err, IsNil)
+
+	err = f.Truncate(-1)
+	c.Assert(err, FitsTypeOf, &os.PathError{})
+	c.Assert(err.(*os.PathError).Err, Equals, os.ErrInvalid)
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *AsyncSuite) TestCreateInPendingFile(c *C) {
+	underlying := &blockingFS{Filesystem: memfs.New(), release: make(chan struct{})}
+	fs := New(underlying, 2)
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+	close(underlying.release)
+
+	_, err := fs.Create("foo/bar")
+	c.Assert(billy.IsNotDir(err), Equals, true, Commentf("%v", err))
+	c.Assert(fs.Close(), IsNil)
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs, 4)
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
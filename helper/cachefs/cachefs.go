@@ -0,0 +1,446 @@
+// Package cachefs provides a helper caching the contents and the metadata of
+// a slow billy filesystem into a fast one.
+package cachefs // import "gopkg.in/src-d/go-billy.v4/helper/cachefs"
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+const (
+	// DefaultMaxBytes is the default maximum size of the contents cached.
+	DefaultMaxBytes = 64 << 20
+	// DefaultMaxEntries is the default maximum number of paths whose
+	// metadata is cached.
+	DefaultMaxEntries = 4096
+)
+
+// Options are the options of a Cache, the zero values mean the default ones.
+type Options struct {
+	// MaxBytes is the maximum size of the contents cached in the fast
+	// filesystem. The files bigger than it are never cached.
+	MaxBytes int64
+	// MaxEntries is the maximum number of paths whose results of Stat, Lstat
+	// and ReadDir are cached.
+	MaxEntries int
+}
+
+// Cache is a helper that caches the contents of the files read from a slow
+// filesystem, as a remote one, into a fast one, as memfs, and the results of
+// Stat, Lstat and ReadDir in memory. Both caches are bounded, evicting the
+// least recently used entries first.
+//
+// The entries of a path are invalidated by the changes done through Cache,
+// but not by the ones done to the slow filesystem directly. The paths are
+// cached as given, so the changes done through a path don't invalidate the
+// entries cached through other paths reaching the same file, as symbolic
+// links.
+type Cache struct {
+	slow, fast billy.Filesystem
+
+	m        sync.Mutex
+	contents *lru
+	metadata *lru
+	// gen is increased by every invalidation, so the contents fetched
+	// meanwhile aren't cached.
+	gen  uint64
+	next uint64
+}
+
+// New creates a new filesystem wrapping up slow, caching its files into fast.
+// fast must be used only by the cache, the contents are stored in its root.
+func New(slow, fast billy.Filesystem, o Options) billy.Filesystem {
+	if o.MaxBytes == 0 {
+		o.MaxBytes = DefaultMaxBytes
+	}
+
+	if o.MaxEntries == 0 {
+		o.MaxEntries = DefaultMaxEntries
+	}
+
+	h := &Cache{slow: slow, fast: fast}
+	h.contents = newLRU(o.MaxBytes, func(v interface{}) {
+		fast.Remove(v.(string))
+	})
+
+	h.metadata = newLRU(int64(o.MaxEntries), nil)
+	return h
+}
+
+// metadata are the results cached of a path.
+type metadata struct {
+	stat, lstat *info
+	entries     []os.FileInfo
+	hasEntries  bool
+}
+
+type info struct {
+	fi  os.FileInfo
+	err error
+}
+
+func (h *Cache) Create(filename string) (billy.File, error) {
+	return h.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (h *Cache) Open(filename string) (billy.File, error) {
+	return h.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (h *Cache) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	return h.OpenFileOpt(filename, flag, perm)
+}
+
+// OpenFileOpt implements the OptionOpener interface. The files opened for
+// reading with options are opened from the slow filesystem, with the options,
+// bypassing the cache of contents.
+func (h *Cache) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	isWrite := flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0
+	switch {
+	case !isWrite && len(opts) == 0:
+		return h.open(filename)
+	case !isWrite:
+		return billy.OpenFileOpt(h.slow, filename, flag, perm, opts...)
+	}
+
+	h.invalidateParents(filename)
+	f, err := billy.OpenFileOpt(h.slow, filename, flag, perm, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &writeFile{File: f, h: h, name: filename}, nil
+}
+
+// open opens filename for reading from the fast filesystem, fetching it if
+// it's not cached yet.
+func (h *Cache) open(filename string) (billy.File, error) {
+	key := billy.SlashPath(filename)
+
+	h.m.Lock()
+	name, ok := h.contents.get(key)
+	gen := h.gen
+	h.m.Unlock()
+
+	if ok {
+		if f, err := h.fast.Open(name.(string)); err == nil {
+			return &file{File: f, name: filename}, nil
+		}
+	}
+
+	fi, err := h.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fi.Mode().IsRegular() || fi.Size() > h.contents.max {
+		return h.slow.Open(filename)
+	}
+
+	name, err = h.fetch(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := h.fast.Open(name.(string))
+	if err != nil {
+		return h.slow.Open(filename)
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.gen == gen {
+		h.contents.add(key, name, fi.Size())
+	}
+
+	return &file{File: f, name: filename}, nil
+}
+
+// fetch copies filename from the slow filesystem to a new file of the fast
+// one, returning its name.
+func (h *Cache) fetch(filename string) (string, error) {
+	src, err := h.slow.Open(filename)
+	if err != nil {
+		return "", err
+	}
+
+	defer src.Close()
+
+	h.m.Lock()
+	h.next++
+	name := strconv.FormatUint(h.next, 10)
+	h.m.Unlock()
+
+	dst, err := h.fast.Create(name)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(dst, src)
+	if err1 := dst.Close(); err == nil {
+		err = err1
+	}
+
+	if err != nil {
+		h.fast.Remove(name)
+		return "", err
+	}
+
+	return name, nil
+}
+
+func (h *Cache) Stat(filename string) (os.FileInfo, error) {
+	return h.stat(filename, false)
+}
+
+func (h *Cache) Lstat(filename string) (os.FileInfo, error) {
+	return h.stat(filename, true)
+}
+
+func (h *Cache) stat(filename string, lstat bool) (os.FileInfo, error) {
+	key := billy.SlashPath(filename)
+
+	h.m.Lock()
+	m := h.get(key)
+	i := m.stat
+	if lstat {
+		i = m.lstat
+	}
+
+	gen := h.gen
+	h.m.Unlock()
+
+	if i != nil {
+		return i.fi, i.err
+	}
+
+	var fi os.FileInfo
+	var err error
+	if lstat {
+		fi, err = h.slow.Lstat(filename)
+	} else {
+		fi, err = h.slow.Stat(filename)
+	}
+
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.gen == gen {
+		m := h.get(key)
+		if lstat {
+			m.lstat = &info{fi, err}
+		} else {
+			m.stat = &info{fi, err}
+		}
+	}
+
+	return fi, err
+}
+
+func (h *Cache) ReadDir(path string) ([]os.FileInfo, error) {
+	key := billy.SlashPath(path)
+
+	h.m.Lock()
+	m := h.get(key)
+	entries, ok := m.entries, m.hasEntries
+	gen := h.gen
+	h.m.Unlock()
+
+	if ok {
+		return append([]os.FileInfo(nil), entries...), nil
+	}
+
+	entries, err := h.slow.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.gen == gen {
+		m := h.get(key)
+		m.entries, m.hasEntries = entries, true
+	}
+
+	return append([]os.FileInfo(nil), entries...), nil
+}
+
+// get returns the metadata of key, adding an empty one if it's not cached. It
+// must be called with the lock held.
+func (h *Cache) get(key string) *metadata {
+	if m, ok := h.metadata.get(key); ok {
+		return m.(*metadata)
+	}
+
+	m := &metadata{}
+	h.metadata.add(key, m, 1)
+	return m
+}
+
+func (h *Cache) Rename(from, to string) error {
+	defer h.invalidateTree(from, to)
+	return h.slow.Rename(from, to)
+}
+
+func (h *Cache) Remove(filename string) error {
+	defer h.invalidateTree(filename)
+	return h.slow.Remove(filename)
+}
+
+// RemoveAll implements the RemoverAll interface.
+func (h *Cache) RemoveAll(path string) error {
+	defer h.invalidateTree(path)
+	return util.RemoveAll(h.slow, path)
+}
+
+func (h *Cache) Join(elem ...string) string {
+	return h.slow.Join(elem...)
+}
+
+func (h *Cache) TempFile(dir, prefix string) (billy.File, error) {
+	f, err := h.slow.TempFile(dir, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	h.invalidateParents(f.Name())
+	return &writeFile{File: f, h: h, name: f.Name()}, nil
+}
+
+func (h *Cache) TempDir(dir, prefix string) (string, error) {
+	name, err := h.slow.TempDir(dir, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	h.invalidateParents(name)
+	return name, nil
+}
+
+func (h *Cache) MkdirAll(filename string, perm os.FileMode) error {
+	defer h.invalidateParents(filename)
+	return h.slow.MkdirAll(filename, perm)
+}
+
+func (h *Cache) Symlink(target, link string) error {
+	defer h.invalidateParents(link)
+	return h.slow.Symlink(target, link)
+}
+
+func (h *Cache) Readlink(link string) (string, error) {
+	return h.slow.Readlink(link)
+}
+
+// Chroot returns a chroot of the cached filesystem, sharing the cache.
+func (h *Cache) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Cache) Root() string {
+	return h.slow.Root()
+}
+
+// Capabilities implements the Capable interface.
+func (h *Cache) Capabilities() billy.Capability {
+	return billy.Capabilities(h.slow)
+}
+
+// Describe implements the Describer interface.
+func (h *Cache) Describe() billy.Description {
+	return billy.Describe(h.slow)
+}
+
+// invalidate removes the entries of filename, and the ones of its parent
+// directory, which lists it.
+func (h *Cache) invalidate(filename string) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	key := billy.SlashPath(filename)
+	h.gen++
+	h.contents.remove(key)
+	h.metadata.remove(key)
+	h.metadata.remove(path.Dir(key))
+}
+
+// invalidateParents invalidates filename and all its parents, which may be
+// created with it.
+func (h *Cache) invalidateParents(filename string) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.gen++
+	key := billy.SlashPath(filename)
+	h.contents.remove(key)
+	for ; key != "." && key != "/"; key = path.Dir(key) {
+		h.metadata.remove(key)
+	}
+
+	h.metadata.remove(".")
+}
+
+// invalidateTree invalidates the given paths, and all the paths under them.
+func (h *Cache) invalidateTree(paths ...string) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.gen++
+	for _, p := range paths {
+		key := billy.SlashPath(p)
+		h.contents.removeTree(key)
+		h.metadata.removeTree(key)
+		h.metadata.remove(path.Dir(key))
+	}
+}
+
+// file is a file read from the fast filesystem.
+type file struct {
+	billy.File
+	name string
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+// writeFile is a file opened for writing in the slow filesystem, its entries
+// are invalidated by every write.
+type writeFile struct {
+	billy.File
+	h    *Cache
+	name string
+}
+
+func (f *writeFile) Write(p []byte) (int, error) {
+	defer f.h.invalidate(f.name)
+	return f.File.Write(p)
+}
+
+func (f *writeFile) Truncate(size int64) error {
+	defer f.h.invalidate(f.name)
+	return f.File.Truncate(size)
+}
+
+func (f *writeFile) Close() error {
+	defer f.h.invalidate(f.name)
+	return f.File.Close()
+}
+
+// Sync implements the Syncer interface.
+func (f *writeFile) Sync() error {
+	return billy.Sync(f.File)
+}
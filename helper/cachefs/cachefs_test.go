@@ -0,0 +1,264 @@
+package cachefs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&CacheSuite{})
+var _ = Suite(&FilesystemSuite{})
+
+type FilesystemSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *FilesystemSuite) SetUpTest(c *C) {
+	fs := New(memfs.New(), memfs.New(), Options{MaxBytes: 1 << 10, MaxEntries: 16})
+	s.FilesystemSuite = test.NewFilesystemSuite(fs)
+}
+
+type CacheSuite struct {
+	Slow *countingFS
+	Fast billy.Filesystem
+	FS   billy.Filesystem
+}
+
+func (s *CacheSuite) SetUpTest(c *C) {
+	s.Slow = &countingFS{Filesystem: memfs.New()}
+	s.Fast = memfs.New()
+	s.FS = New(s.Slow, s.Fast, Options{MaxBytes: 10})
+}
+
+func (s *CacheSuite) assertContent(c *C, filename, expected string) {
+	content, err := readFile(s.FS, filename)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, expected)
+}
+
+func (s *CacheSuite) TestOpen(c *C) {
+	err := util.WriteFile(s.Slow, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	s.assertContent(c, "foo", "foo")
+	s.assertContent(c, "/foo", "foo")
+	c.Assert(s.Slow.opens, Equals, 1)
+
+	f, err := s.FS.Open("foo")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, "foo")
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *CacheSuite) TestOpenWrite(c *C) {
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+	s.assertContent(c, "foo", "foo")
+
+	f, err := s.FS.OpenFile("foo", os.O_WRONLY|os.O_APPEND, 0)
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+
+	fi, err := s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Size(), Equals, int64(6))
+	c.Assert(f.Close(), IsNil)
+
+	s.assertContent(c, "foo", "foobar")
+}
+
+func (s *CacheSuite) TestOpenFileOpt(c *C) {
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+	s.assertContent(c, "foo", "foo")
+
+	f, err := billy.OpenFileOpt(s.FS, "foo", os.O_WRONLY|os.O_TRUNC, 0, billy.WithSnapshot())
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	c.Assert(s.Slow.opts, HasLen, 2)
+	c.Assert(s.Slow.opts[1], HasLen, 1)
+	s.assertContent(c, "foo", "bar")
+
+	f, err = billy.OpenFileOpt(s.FS, "foo", os.O_RDONLY, 0, billy.WithSnapshot())
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+	c.Assert(s.Slow.opts, HasLen, 3)
+	c.Assert(s.Slow.opts[2], HasLen, 1)
+}
+
+func (s *CacheSuite) TestEviction(c *C) {
+	for _, name := range []string{"foo", "bar"} {
+		err := util.WriteFile(s.Slow, name, []byte("123456"), 0644)
+		c.Assert(err, IsNil)
+	}
+
+	s.assertContent(c, "foo", "123456")
+	s.assertContent(c, "bar", "123456")
+	c.Assert(s.Slow.opens, Equals, 2)
+
+	l, err := s.Fast.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+
+	s.assertContent(c, "bar", "123456")
+	c.Assert(s.Slow.opens, Equals, 2)
+	s.assertContent(c, "foo", "123456")
+	c.Assert(s.Slow.opens, Equals, 3)
+}
+
+func (s *CacheSuite) TestOpenBigFile(c *C) {
+	err := util.WriteFile(s.Slow, "foo", []byte("01234567890"), 0644)
+	c.Assert(err, IsNil)
+
+	s.assertContent(c, "foo", "01234567890")
+	s.assertContent(c, "foo", "01234567890")
+	c.Assert(s.Slow.opens, Equals, 2)
+
+	l, err := s.Fast.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 0)
+}
+
+func (s *CacheSuite) TestStat(c *C) {
+	err := util.WriteFile(s.Slow, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 2; i++ {
+		fi, err := s.FS.Stat("foo")
+		c.Assert(err, IsNil)
+		c.Assert(fi.Size(), Equals, int64(3))
+
+		_, err = s.FS.Stat("bar")
+		c.Assert(os.IsNotExist(err), Equals, true)
+	}
+
+	c.Assert(s.Slow.stats, Equals, 2)
+
+	err = util.WriteFile(s.FS, "bar", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("bar")
+	c.Assert(err, IsNil)
+}
+
+func (s *CacheSuite) TestReadDir(c *C) {
+	err := util.WriteFile(s.Slow, "foo/bar", nil, 0644)
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 2; i++ {
+		l, err := s.FS.ReadDir("foo")
+		c.Assert(err, IsNil)
+		c.Assert(l, HasLen, 1)
+	}
+
+	c.Assert(s.Slow.readDirs, Equals, 1)
+
+	err = util.WriteFile(s.FS, "foo/qux/baz", nil, 0644)
+	c.Assert(err, IsNil)
+
+	l, err := s.FS.ReadDir("foo")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 2)
+}
+
+func (s *CacheSuite) TestRename(c *C) {
+	err := util.WriteFile(s.Slow, "foo/bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+	err = util.WriteFile(s.Slow, "qux/bar", []byte("qux"), 0644)
+	c.Assert(err, IsNil)
+
+	s.assertContent(c, "foo/bar", "foo")
+	s.assertContent(c, "qux/bar", "qux")
+
+	c.Assert(s.FS.Remove("foo/bar"), IsNil)
+	c.Assert(s.FS.Rename("qux", "foo/qux"), IsNil)
+
+	_, err = s.FS.Stat("foo/bar")
+	c.Assert(os.IsNotExist(err), Equals, true)
+	_, err = s.FS.Open("qux/bar")
+	c.Assert(os.IsNotExist(err), Equals, true)
+	s.assertContent(c, "foo/qux/bar", "qux")
+}
+
+func (s *CacheSuite) TestRemoveAll(c *C) {
+	err := util.WriteFile(s.Slow, "foo/bar/qux", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	s.assertContent(c, "foo/bar/qux", "foo")
+	_, err = s.FS.ReadDir("foo")
+	c.Assert(err, IsNil)
+
+	c.Assert(util.RemoveAll(s.FS, "foo"), IsNil)
+
+	_, err = s.FS.Open("foo/bar/qux")
+	c.Assert(os.IsNotExist(err), Equals, true)
+	_, err = s.FS.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *CacheSuite) TestChroot(c *C) {
+	err := util.WriteFile(s.Slow, "foo/bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	fs, err := s.FS.Chroot("foo")
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 2; i++ {
+		content, err := readFile(fs, "bar")
+		c.Assert(err, IsNil)
+		c.Assert(string(content), Equals, "foo")
+	}
+
+	s.assertContent(c, "foo/bar", "foo")
+	c.Assert(s.Slow.opens, Equals, 1)
+}
+
+// countingFS counts the reads done to the underlying filesystem.
+type countingFS struct {
+	billy.Filesystem
+	opens, stats, readDirs int
+	opts                   [][]billy.OpenOption
+}
+
+func (fs *countingFS) Open(filename string) (billy.File, error) {
+	fs.opens++
+	return fs.Filesystem.Open(filename)
+}
+
+func (fs *countingFS) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	fs.opts = append(fs.opts, opts)
+	return billy.OpenFileOpt(fs.Filesystem, filename, flag, perm, opts...)
+}
+
+func (fs *countingFS) Stat(filename string) (os.FileInfo, error) {
+	fs.stats++
+	return fs.Filesystem.Stat(filename)
+}
+
+func (fs *countingFS) ReadDir(path string) ([]os.FileInfo, error) {
+	fs.readDirs++
+	return fs.Filesystem.ReadDir(path)
+}
+
+func readFile(fs billy.Basic, filename string) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
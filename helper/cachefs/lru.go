@@ -0,0 +1,82 @@
+package cachefs
+
+import (
+	"container/list"
+	"strings"
+)
+
+// lru is a cache of values bounded by the sum of their sizes, evicting the
+// least recently used ones first.
+type lru struct {
+	max, size int64
+	l         *list.List
+	m         map[string]*list.Element
+
+	// evicted is called with the values removed from the cache.
+	evicted func(value interface{})
+}
+
+type entry struct {
+	key   string
+	value interface{}
+	size  int64
+}
+
+func newLRU(max int64, evicted func(value interface{})) *lru {
+	return &lru{
+		max:     max,
+		l:       list.New(),
+		m:       make(map[string]*list.Element),
+		evicted: evicted,
+	}
+}
+
+// get returns the value of key, marking it as recently used.
+func (c *lru) get(key string) (interface{}, bool) {
+	e, ok := c.m[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.l.MoveToFront(e)
+	return e.Value.(*entry).value, true
+}
+
+// add adds the value of key, replacing the previous one, and evicts the least
+// recently used values exceeding the maximum size.
+func (c *lru) add(key string, value interface{}, size int64) {
+	c.remove(key)
+
+	c.m[key] = c.l.PushFront(&entry{key: key, value: value, size: size})
+	c.size += size
+
+	for c.size > c.max {
+		c.removeElement(c.l.Back())
+	}
+}
+
+func (c *lru) remove(key string) {
+	if e, ok := c.m[key]; ok {
+		c.removeElement(e)
+	}
+}
+
+// removeTree removes the values of the slash separated path and of all the
+// paths under it.
+func (c *lru) removeTree(path string) {
+	for key, e := range c.m {
+		if path == "." || key == path || strings.HasPrefix(key, path+"/") {
+			c.removeElement(e)
+		}
+	}
+}
+
+func (c *lru) removeElement(e *list.Element) {
+	en := c.l.Remove(e).(*entry)
+	delete(c.m, en.key)
+	c.size -= en.size
+
+	if c.evicted != nil {
+		c.evicted(en.value)
+	}
+}
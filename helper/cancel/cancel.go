@@ -0,0 +1,223 @@
+// Package cancel provides a helper that binds the operations of a billy
+// filesystem to a context.
+package cancel // import "gopkg.in/src-d/go-billy.v4/helper/cancel"
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/helper/polyfill"
+)
+
+// Cancel is a helper that checks a context before every operation, failing
+// with its error once it's done. The operations in progress are never
+// interrupted, so it's meant for the filesystems whose operations are fast,
+// as osfs or memfs, to implement billy.Contexter.
+type Cancel struct {
+	underlying billy.Filesystem
+	ctx        context.Context
+}
+
+// New creates a new filesystem wrapping up fs, checking ctx before every
+// operation of it and of the files opened through it.
+func New(fs billy.Basic, ctx context.Context) billy.Filesystem {
+	return &Cancel{underlying: polyfill.New(fs), ctx: ctx}
+}
+
+func (h *Cancel) Create(filename string) (billy.File, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.wrapFile(h.underlying.Create(filename))
+}
+
+func (h *Cancel) Open(filename string) (billy.File, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.wrapFile(h.underlying.Open(filename))
+}
+
+func (h *Cancel) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.wrapFile(h.underlying.OpenFile(filename, flag, perm))
+}
+
+// OpenFileOpt implements the OptionOpener interface.
+func (h *Cancel) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.wrapFile(billy.OpenFileOpt(h.underlying, filename, flag, perm, opts...))
+}
+
+func (h *Cancel) Stat(filename string) (os.FileInfo, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.underlying.Stat(filename)
+}
+
+func (h *Cancel) Lstat(filename string) (os.FileInfo, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.underlying.Lstat(filename)
+}
+
+func (h *Cancel) Rename(from, to string) error {
+	if err := h.ctx.Err(); err != nil {
+		return err
+	}
+
+	return h.underlying.Rename(from, to)
+}
+
+func (h *Cancel) Remove(filename string) error {
+	if err := h.ctx.Err(); err != nil {
+		return err
+	}
+
+	return h.underlying.Remove(filename)
+}
+
+func (h *Cancel) TempFile(dir, prefix string) (billy.File, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.wrapFile(h.underlying.TempFile(dir, prefix))
+}
+
+func (h *Cancel) TempDir(dir, prefix string) (string, error) {
+	if err := h.ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return h.underlying.TempDir(dir, prefix)
+}
+
+func (h *Cancel) ReadDir(path string) ([]os.FileInfo, error) {
+	if err := h.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return h.underlying.ReadDir(path)
+}
+
+func (h *Cancel) MkdirAll(filename string, perm os.FileMode) error {
+	if err := h.ctx.Err(); err != nil {
+		return err
+	}
+
+	return h.underlying.MkdirAll(filename, perm)
+}
+
+func (h *Cancel) Symlink(target, link string) error {
+	if err := h.ctx.Err(); err != nil {
+		return err
+	}
+
+	return h.underlying.Symlink(target, link)
+}
+
+func (h *Cancel) Readlink(link string) (string, error) {
+	if err := h.ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return h.underlying.Readlink(link)
+}
+
+func (h *Cancel) Join(elem ...string) string {
+	return h.underlying.Join(elem...)
+}
+
+func (h *Cancel) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Cancel) Root() string {
+	return h.underlying.Root()
+}
+
+// WithContext implements the Contexter interface, binding the underlying
+// filesystem to ctx instead.
+func (h *Cancel) WithContext(ctx context.Context) billy.Filesystem {
+	return &Cancel{underlying: h.underlying, ctx: ctx}
+}
+
+// Capabilities implements the Capable interface.
+func (h *Cancel) Capabilities() billy.Capability {
+	return billy.Capabilities(h.underlying)
+}
+
+// Describe implements the Describer interface.
+func (h *Cancel) Describe() billy.Description {
+	return billy.Describe(h.underlying)
+}
+
+func (h *Cancel) wrapFile(f billy.File, err error) (billy.File, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{File: f, ctx: h.ctx}, nil
+}
+
+type file struct {
+	billy.File
+	ctx context.Context
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.Read(p)
+}
+
+func (f *file) ReadAt(p []byte, off int64) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.ReadAt(p, off)
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return f.File.Write(p)
+}
+
+func (f *file) Truncate(size int64) error {
+	if err := f.ctx.Err(); err != nil {
+		return err
+	}
+
+	return f.File.Truncate(size)
+}
+
+// Sync implements the Syncer interface.
+func (f *file) Sync() error {
+	if err := f.ctx.Err(); err != nil {
+		return err
+	}
+
+	return billy.Sync(f.File)
+}
@@ -0,0 +1,83 @@
+package cancel_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/cancel"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&CancelSuite{})
+
+type CancelSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *CancelSuite) SetUpTest(c *C) {
+	s.FilesystemSuite = test.NewFilesystemSuite(cancel.New(memfs.New(), context.Background()))
+}
+
+func (s *CancelSuite) TestCanceled(c *C) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	fs := cancel.New(memfs.New(), ctx)
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	cancelFunc()
+
+	_, err = fs.Open("foo")
+	c.Assert(err, Equals, context.Canceled)
+	_, err = fs.Stat("foo")
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(fs.MkdirAll("bar", 0755), Equals, context.Canceled)
+
+	_, err = f.Read(make([]byte, 3))
+	c.Assert(err, Equals, context.Canceled)
+}
+
+func (s *CancelSuite) TestCanceledChroot(c *C) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	fs, err := cancel.New(memfs.New(), ctx).Chroot("foo")
+	c.Assert(err, IsNil)
+
+	cancelFunc()
+
+	_, err = fs.Create("bar")
+	c.Assert(err, Equals, context.Canceled)
+}
+
+func (s *CancelSuite) TestWithContext(c *C) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+
+	underlying := memfs.New()
+	fs := cancel.New(underlying, ctx)
+	c.Assert(fs.MkdirAll("foo", 0755), Equals, context.Canceled)
+
+	fs = billy.WithContext(fs, context.Background())
+	c.Assert(fs.MkdirAll("foo", 0755), IsNil)
+
+	fi, err := underlying.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode()&os.ModeDir != 0, Equals, true)
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return cancel.New(fs, context.Background())
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
@@ -0,0 +1,326 @@
+// Package casefs provides a helper emulating case-insensitive but
+// case-preserving semantics on top of any billy filesystem.
+package casefs // import "gopkg.in/src-d/go-billy.v4/helper/casefs"
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// ErrCaseCollision is returned when a name matches, ignoring case, several
+// entries of a directory of the underlying filesystem and none of them
+// exactly, so it can't be told which one it refers to.
+var ErrCaseCollision = errors.New("name collides ignoring case")
+
+// CaseFS is a helper that makes a case-sensitive filesystem behave as the
+// default ones of macOS and Windows: the names differing only in case refer
+// to the same file, and the files keep the case given at creation.
+//
+// Every path element is resolved to the existing entry matching it ignoring
+// case, an exact match is preferred over a case-insensitive one. So creating
+// or renaming to a name colliding with an existing file replaces that file,
+// keeping its name, as checking out two paths differing in case does on
+// Windows. Renaming a file to a name differing only in case changes its case.
+//
+// The targets of the symbolic links are not resolved, they're followed by
+// the underlying filesystem.
+type CaseFS struct {
+	underlying billy.Filesystem
+}
+
+// New creates a new filesystem wrapping up 'fs', with case-insensitive but
+// case-preserving names.
+func New(fs billy.Filesystem) billy.Filesystem {
+	return &CaseFS{underlying: fs}
+}
+
+func (fs *CaseFS) Create(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (fs *CaseFS) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *CaseFS) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	return fs.OpenFileOpt(filename, flag, perm)
+}
+
+// OpenFileOpt implements the OptionOpener interface.
+func (fs *CaseFS) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	fullpath, err := fs.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return billy.OpenFileOpt(fs.underlying, fullpath, flag, perm, opts...)
+}
+
+func (fs *CaseFS) Stat(filename string) (os.FileInfo, error) {
+	fullpath, err := fs.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.underlying.Stat(fullpath)
+}
+
+func (fs *CaseFS) Lstat(filename string) (os.FileInfo, error) {
+	fullpath, err := fs.resolve(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.underlying.Lstat(fullpath)
+}
+
+// Rename renames from to the existing file matching to, replacing it. If
+// both are the same file, its case is changed to the one of to.
+func (fs *CaseFS) Rename(from, to string) error {
+	from, err := fs.resolve(from)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := fs.resolve(to)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(resolved, from) {
+		resolved = fs.underlying.Join(filepath.Dir(resolved), filepath.Base(to))
+	}
+
+	return fs.underlying.Rename(from, resolved)
+}
+
+// Link implements the Linker interface.
+func (fs *CaseFS) Link(oldname, newname string) error {
+	oldname, err := fs.resolve(oldname)
+	if err != nil {
+		return err
+	}
+
+	newname, err = fs.resolve(newname)
+	if err != nil {
+		return err
+	}
+
+	return billy.Link(fs.underlying, oldname, newname)
+}
+
+// RemoveAll implements the RemoverAll interface.
+func (fs *CaseFS) RemoveAll(path string) error {
+	fullpath, err := fs.resolve(path)
+	if err != nil {
+		return err
+	}
+
+	return util.RemoveAll(fs.underlying, fullpath)
+}
+
+// GetXattr implements the Xattrer interface.
+func (fs *CaseFS) GetXattr(name, attr string) ([]byte, error) {
+	fullpath, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return billy.GetXattr(fs.underlying, fullpath, attr)
+}
+
+// SetXattr implements the Xattrer interface.
+func (fs *CaseFS) SetXattr(name, attr string, value []byte) error {
+	fullpath, err := fs.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	return billy.SetXattr(fs.underlying, fullpath, attr, value)
+}
+
+// ListXattr implements the Xattrer interface.
+func (fs *CaseFS) ListXattr(name string) ([]string, error) {
+	fullpath, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return billy.ListXattr(fs.underlying, fullpath)
+}
+
+// RemoveXattr implements the Xattrer interface.
+func (fs *CaseFS) RemoveXattr(name, attr string) error {
+	fullpath, err := fs.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	return billy.RemoveXattr(fs.underlying, fullpath, attr)
+}
+
+func (fs *CaseFS) Remove(filename string) error {
+	fullpath, err := fs.resolve(filename)
+	if err != nil {
+		return err
+	}
+
+	return fs.underlying.Remove(fullpath)
+}
+
+func (fs *CaseFS) Join(elem ...string) string {
+	return fs.underlying.Join(elem...)
+}
+
+func (fs *CaseFS) TempFile(dir, prefix string) (billy.File, error) {
+	fullpath, err := fs.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.underlying.TempFile(fullpath, prefix)
+}
+
+func (fs *CaseFS) TempDir(dir, prefix string) (string, error) {
+	fullpath, err := fs.resolve(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return fs.underlying.TempDir(fullpath, prefix)
+}
+
+func (fs *CaseFS) ReadDir(path string) ([]os.FileInfo, error) {
+	fullpath, err := fs.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.underlying.ReadDir(fullpath)
+}
+
+// ReadDirIter implements the DirIterator interface.
+func (fs *CaseFS) ReadDirIter(path string) (billy.DirIter, error) {
+	fullpath, err := fs.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return billy.ReadDirIter(fs.underlying, fullpath)
+}
+
+func (fs *CaseFS) MkdirAll(filename string, perm os.FileMode) error {
+	fullpath, err := fs.resolve(filename)
+	if err != nil {
+		return err
+	}
+
+	return fs.underlying.MkdirAll(fullpath, perm)
+}
+
+func (fs *CaseFS) Symlink(target, link string) error {
+	fullpath, err := fs.resolve(link)
+	if err != nil {
+		return err
+	}
+
+	return fs.underlying.Symlink(target, fullpath)
+}
+
+func (fs *CaseFS) Readlink(link string) (string, error) {
+	fullpath, err := fs.resolve(link)
+	if err != nil {
+		return "", err
+	}
+
+	return fs.underlying.Readlink(fullpath)
+}
+
+// Chroot returns a chroot of the filesystem, so its base is resolved ignoring
+// case as well.
+func (fs *CaseFS) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(fs, fs.Join(string(filepath.Separator), path)), nil
+}
+
+func (fs *CaseFS) Root() string {
+	return fs.underlying.Root()
+}
+
+// Capabilities implements the Capable interface.
+func (fs *CaseFS) Capabilities() billy.Capability {
+	return billy.Capabilities(fs.underlying)
+}
+
+// Describe implements the Describer interface.
+func (fs *CaseFS) Describe() billy.Description {
+	d := billy.Describe(fs.underlying)
+	d.CaseSensitive = false
+	d.CasePreserving = true
+	return d
+}
+
+// resolve returns the path of the underlying filesystem matching the given
+// one ignoring case. The elements without a match are kept as given.
+func (fs *CaseFS) resolve(filename string) (string, error) {
+	filename = filepath.Clean(filepath.FromSlash(filename))
+	elems := strings.Split(filename, string(filepath.Separator))
+
+	var resolved []string
+	for i, elem := range elems {
+		if elem == "" || elem == "." || elem == ".." {
+			resolved = append(resolved, elem)
+			continue
+		}
+
+		dir := fs.underlying.Join(resolved...)
+		if _, err := fs.underlying.Lstat(fs.underlying.Join(dir, elem)); err == nil {
+			resolved = append(resolved, elem)
+			continue
+		}
+
+		l, err := fs.underlying.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) || billy.IsNotDir(err) {
+				resolved = append(resolved, elems[i:]...)
+				break
+			}
+
+			return "", err
+		}
+
+		name, err := match(l, elem)
+		if err != nil {
+			return "", &os.PathError{Op: "resolve", Path: filename, Err: err}
+		}
+
+		resolved = append(resolved, name)
+	}
+
+	return fs.underlying.Join(resolved...), nil
+}
+
+// match returns the name of the entry matching name ignoring case, or name if
+// there isn't any. If several match ErrCaseCollision is returned.
+func match(l []os.FileInfo, name string) (string, error) {
+	var found []string
+	for _, fi := range l {
+		if strings.EqualFold(fi.Name(), name) {
+			found = append(found, fi.Name())
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return name, nil
+	case 1:
+		return found[0], nil
+	default:
+		return "", ErrCaseCollision
+	}
+}
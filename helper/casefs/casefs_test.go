@@ -0,0 +1,164 @@
+package casefs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&CaseSuite{})
+var _ = Suite(&FilesystemSuite{})
+
+type FilesystemSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *FilesystemSuite) SetUpTest(c *C) {
+	s.FilesystemSuite = test.NewFilesystemSuite(New(memfs.New()))
+}
+
+type CaseSuite struct {
+	Underlying billy.Filesystem
+	FS         billy.Filesystem
+}
+
+func (s *CaseSuite) SetUpTest(c *C) {
+	s.Underlying = memfs.New()
+	s.FS = New(s.Underlying)
+}
+
+func (s *CaseSuite) TestCaseInsensitive(c *C) {
+	err := util.WriteFile(s.FS, "Foo/Bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	content, err := readFile(s.FS, "foo/BAR")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+
+	fi, err := s.FS.Stat("FOO")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "Foo")
+	c.Assert(fi.IsDir(), Equals, true)
+
+	err = util.WriteFile(s.FS, "FOO/qux", nil, 0644)
+	c.Assert(err, IsNil)
+
+	_, err = s.Underlying.Stat("Foo/qux")
+	c.Assert(err, IsNil)
+}
+
+func (s *CaseSuite) TestCreateCollision(c *C) {
+	err := util.WriteFile(s.FS, "README", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = util.WriteFile(s.FS, "readme", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	l, err := s.Underlying.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "README")
+	c.Assert(l[0].Size(), Equals, int64(3))
+
+	_, err = s.FS.OpenFile("Readme", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(os.IsExist(err), Equals, true)
+}
+
+func (s *CaseSuite) TestRenameCase(c *C) {
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Rename("foo", "FOO")
+	c.Assert(err, IsNil)
+
+	l, err := s.Underlying.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "FOO")
+}
+
+func (s *CaseSuite) TestRenameCollision(c *C) {
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+	err = util.WriteFile(s.FS, "Bar", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Rename("FOO", "bar")
+	c.Assert(err, IsNil)
+
+	l, err := s.Underlying.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "Bar")
+
+	content, err := readFile(s.FS, "BAR")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *CaseSuite) TestAmbiguous(c *C) {
+	err := util.WriteFile(s.Underlying, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+	err = util.WriteFile(s.Underlying, "FOO", nil, 0644)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("Foo")
+	c.Assert(err, NotNil)
+	c.Assert(err.(*os.PathError).Err, Equals, ErrCaseCollision)
+}
+
+func (s *CaseSuite) TestSymlink(c *C) {
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Symlink("foo", "Link")
+	c.Assert(err, IsNil)
+
+	target, err := s.FS.Readlink("LINK")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "foo")
+
+	content, err := readFile(s.FS, "link")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *CaseSuite) TestChroot(c *C) {
+	err := util.WriteFile(s.FS, "Foo/Bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	fs, err := s.FS.Chroot("FOO")
+	c.Assert(err, IsNil)
+
+	content, err := readFile(fs, "bar")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *CaseSuite) TestDescribe(c *C) {
+	d := billy.Describe(s.FS)
+	c.Assert(d.CaseSensitive, Equals, false)
+	c.Assert(d.CasePreserving, Equals, true)
+}
+
+func readFile(fs billy.Basic, filename string) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
@@ -1,15 +1,31 @@
+// Package chroot provides a helper exposing a directory of a billy filesystem
+// as its root.
+//
+// The boundary is only checked lexically: the paths escaping the base, once
+// their ".." elements are resolved, and the symbolic links created pointing
+// outside of it are rejected, but the links already present in the underlying
+// filesystem are followed by it. On the OS a relative link like up -> ../../etc
+// leads out of the base, so a chroot of osfs.New is not an isolation
+// boundary, use osfs.NewRooted to confine the paths to a directory.
 package chroot
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/helper/polyfill"
+	"gopkg.in/src-d/go-billy.v4/util"
 )
 
-// ChrootHelper is a helper to implement billy.Chroot.
+// ChrootHelper is a helper to implement billy.Chroot. The paths escaping the
+// base, once their ".." elements are resolved, and the symbolic links created
+// pointing outside of it, are rejected with billy.ErrCrossedBoundary. The
+// links already present in the underlying filesystem are followed by it, see
+// the package documentation.
 type ChrootHelper struct {
 	underlying billy.Filesystem
 	base       string
@@ -33,11 +49,31 @@ func (fs *ChrootHelper) underlyingPath(filename string) (string, error) {
 	return fs.Join(fs.Root(), filename), nil
 }
 
+// isCrossBoundaries returns true if the given path, relative to the base or
+// absolute from it, escapes the base once its ".." elements are resolved, or
+// it has a volume name. The path isn't cleaned before, since cleaning drops
+// the ".." elements at the beginning of an absolute path, which escape once
+// joined to the base.
 func isCrossBoundaries(path string) bool {
-	path = filepath.ToSlash(path)
-	path = filepath.Clean(path)
+	if billy.HasVolumeName(path) {
+		return true
+	}
+
+	var depth int
+	for _, elem := range strings.Split(filepath.ToSlash(path), "/") {
+		switch elem {
+		case "", ".":
+		case "..":
+			depth--
+			if depth < 0 {
+				return true
+			}
+		default:
+			depth++
+		}
+	}
 
-	return strings.HasPrefix(path, ".."+string(filepath.Separator))
+	return false
 }
 
 func (fs *ChrootHelper) Create(filename string) (billy.File, error) {
@@ -82,6 +118,21 @@ func (fs *ChrootHelper) OpenFile(filename string, flag int, mode os.FileMode) (b
 	return newFile(fs, f, filename), nil
 }
 
+// OpenFileOpt implements the OptionOpener interface.
+func (fs *ChrootHelper) OpenFileOpt(filename string, flag int, mode os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	fullpath, err := fs.underlyingPath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := billy.OpenFileOpt(fs.underlying, fullpath, flag, mode, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFile(fs, f, filename), nil
+}
+
 func (fs *ChrootHelper) Stat(filename string) (os.FileInfo, error) {
 	fullpath, err := fs.underlyingPath(filename)
 	if err != nil {
@@ -106,6 +157,125 @@ func (fs *ChrootHelper) Rename(from, to string) error {
 	return fs.underlying.Rename(from, to)
 }
 
+// Link implements the Linker interface.
+func (fs *ChrootHelper) Link(oldname, newname string) error {
+	var err error
+	oldname, err = fs.underlyingPath(oldname)
+	if err != nil {
+		return err
+	}
+
+	newname, err = fs.underlyingPath(newname)
+	if err != nil {
+		return err
+	}
+
+	return billy.Link(fs.underlying, oldname, newname)
+}
+
+// RemoveAll implements the RemoverAll interface, using the RemoveAll of the
+// underlying filesystem if any.
+func (fs *ChrootHelper) RemoveAll(path string) error {
+	fullpath, err := fs.underlyingPath(path)
+	if err != nil {
+		return err
+	}
+
+	return util.RemoveAll(fs.underlying, fullpath)
+}
+
+// GetXattr implements the Xattrer interface.
+func (fs *ChrootHelper) GetXattr(name, attr string) ([]byte, error) {
+	fullpath, err := fs.underlyingPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return billy.GetXattr(fs.underlying, fullpath, attr)
+}
+
+// SetXattr implements the Xattrer interface.
+func (fs *ChrootHelper) SetXattr(name, attr string, value []byte) error {
+	fullpath, err := fs.underlyingPath(name)
+	if err != nil {
+		return err
+	}
+
+	return billy.SetXattr(fs.underlying, fullpath, attr, value)
+}
+
+// ListXattr implements the Xattrer interface.
+func (fs *ChrootHelper) ListXattr(name string) ([]string, error) {
+	fullpath, err := fs.underlyingPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return billy.ListXattr(fs.underlying, fullpath)
+}
+
+// RemoveXattr implements the Xattrer interface.
+func (fs *ChrootHelper) RemoveXattr(name, attr string) error {
+	fullpath, err := fs.underlyingPath(name)
+	if err != nil {
+		return err
+	}
+
+	return billy.RemoveXattr(fs.underlying, fullpath, attr)
+}
+
+// WithContext implements the Contexter interface, binding the underlying
+// filesystem to ctx.
+func (fs *ChrootHelper) WithContext(ctx context.Context) billy.Filesystem {
+	return New(billy.WithContext(fs.underlying, ctx), fs.base)
+}
+
+// Watch implements the Watcher interface. The paths of the events are
+// relative to the base of the chroot, billy.ErrNotSupported is returned if the
+// underlying filesystem doesn't implement it.
+func (fs *ChrootHelper) Watch(path string, recursive bool) (<-chan billy.Event, billy.CancelFunc, error) {
+	fullpath, err := fs.underlyingPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w, ok := fs.underlying.(billy.Watcher)
+	if !ok {
+		return nil, nil, billy.ErrNotSupported
+	}
+
+	events, cancel, err := w.Watch(fullpath, recursive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make(chan billy.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(out)
+		for e := range events {
+			rel, err := filepath.Rel(fs.base, e.Path)
+			if err != nil || rel == ".." || isCrossBoundaries(rel) {
+				continue
+			}
+
+			select {
+			case out <- billy.Event{Path: rel, Op: e.Op}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return out, func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+	}, nil
+}
+
 func (fs *ChrootHelper) Remove(path string) error {
 	fullpath, err := fs.underlyingPath(path)
 	if err != nil {
@@ -115,6 +285,34 @@ func (fs *ChrootHelper) Remove(path string) error {
 	return fs.underlying.Remove(fullpath)
 }
 
+// RemoveMany implements the BulkRemover interface.
+func (fs *ChrootHelper) RemoveMany(paths []string) error {
+	fullpaths := make([]string, len(paths))
+	given := make(map[string]string, len(paths))
+	for i, path := range paths {
+		fullpath, err := fs.underlyingPath(path)
+		if err != nil {
+			return err
+		}
+
+		fullpaths[i] = fullpath
+		given[fullpath] = path
+	}
+
+	err := billy.RemoveMany(fs.underlying, fullpaths)
+	rerr, ok := err.(*billy.RemoveManyError)
+	if !ok {
+		return err
+	}
+
+	errs := make(map[string]error, len(rerr.Errors))
+	for fullpath, err := range rerr.Errors {
+		errs[given[fullpath]] = err
+	}
+
+	return &billy.RemoveManyError{Errors: errs}
+}
+
 func (fs *ChrootHelper) Join(elem ...string) string {
 	return fs.underlying.Join(elem...)
 }
@@ -133,6 +331,20 @@ func (fs *ChrootHelper) TempFile(dir, prefix string) (billy.File, error) {
 	return newFile(fs, f, fs.Join(dir, filepath.Base(f.Name()))), nil
 }
 
+func (fs *ChrootHelper) TempDir(dir, prefix string) (string, error) {
+	fullpath, err := fs.underlyingPath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := fs.underlying.(billy.TempFile).TempDir(fullpath, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	return fs.Join(dir, filepath.Base(name)), nil
+}
+
 func (fs *ChrootHelper) ReadDir(path string) ([]os.FileInfo, error) {
 	fullpath, err := fs.underlyingPath(path)
 	if err != nil {
@@ -142,6 +354,17 @@ func (fs *ChrootHelper) ReadDir(path string) ([]os.FileInfo, error) {
 	return fs.underlying.(billy.Dir).ReadDir(fullpath)
 }
 
+// ReadDirIter implements the DirIterator interface, iterating the entries
+// returned by ReadDir if the underlying filesystem doesn't implement it.
+func (fs *ChrootHelper) ReadDirIter(path string) (billy.DirIter, error) {
+	fullpath, err := fs.underlyingPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return billy.ReadDirIter(fs.underlying, fullpath)
+}
+
 func (fs *ChrootHelper) MkdirAll(filename string, perm os.FileMode) error {
 	fullpath, err := fs.underlyingPath(filename)
 	if err != nil {
@@ -163,10 +386,17 @@ func (fs *ChrootHelper) Lstat(filename string) (os.FileInfo, error) {
 func (fs *ChrootHelper) Symlink(target, link string) error {
 	target = filepath.FromSlash(target)
 
-	// only rewrite target if it's already absolute
+	// only rewrite target if it's already absolute, the relative ones are
+	// checked from the directory of the link.
 	if filepath.IsAbs(target) || strings.HasPrefix(target, string(filepath.Separator)) {
+		if isCrossBoundaries(target) {
+			return billy.ErrCrossedBoundary
+		}
+
 		target = fs.Join(fs.Root(), target)
 		target = filepath.Clean(filepath.FromSlash(target))
+	} else if isCrossBoundaries(filepath.Dir(link) + string(filepath.Separator) + target) {
+		return billy.ErrCrossedBoundary
 	}
 
 	link, err := fs.underlyingPath(link)
@@ -197,6 +427,10 @@ func (fs *ChrootHelper) Readlink(link string) (string, error) {
 		return "", err
 	}
 
+	if isCrossBoundaries(target) {
+		return "", billy.ErrCrossedBoundary
+	}
+
 	return string(os.PathSeparator) + target, nil
 }
 
@@ -222,6 +456,17 @@ func (fs *ChrootHelper) Capabilities() billy.Capability {
 	return billy.Capabilities(fs.underlying)
 }
 
+// Describe implements the Describer interface. The MaxPathLength is reduced
+// by the length of the base, since it's part of every underlying path.
+func (fs *ChrootHelper) Describe() billy.Description {
+	d := billy.Describe(fs.underlying)
+	if d.MaxPathLength > 0 {
+		d.MaxPathLength -= len(fs.base) + 1
+	}
+
+	return d
+}
+
 type file struct {
 	billy.File
 	name string
@@ -240,3 +485,8 @@ func newFile(fs billy.Filesystem, f billy.File, filename string) billy.File {
 func (f *file) Name() string {
 	return f.name
 }
+
+// Sync implements the Syncer interface.
+func (f *file) Sync() error {
+	return billy.Sync(f.File)
+}
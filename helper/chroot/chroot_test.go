@@ -37,6 +37,34 @@ func (s *ChrootSuite) TestCreateErrCrossedBoundary(c *C) {
 	c.Assert(err, Equals, billy.ErrCrossedBoundary)
 }
 
+func (s *ChrootSuite) TestCreateErrCrossedBoundaryAbsolute(c *C) {
+	m := &test.BasicMock{}
+
+	fs := New(m, "/foo")
+	_, err := fs.Create("/../../etc/passwd")
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+	c.Assert(m.CreateArgs, HasLen, 0)
+}
+
+func (s *ChrootSuite) TestIsCrossBoundaries(c *C) {
+	for path, expected := range map[string]bool{
+		"foo":           false,
+		"/foo":          false,
+		"foo/../bar":    false,
+		"foo/..":        false,
+		"..foo":         false,
+		"foo/..bar/..":  false,
+		"..":            true,
+		"../foo":        true,
+		"/..":           true,
+		"/../foo":       true,
+		"foo/../../bar": true,
+		"./foo/../..":   true,
+	} {
+		c.Assert(isCrossBoundaries(path), Equals, expected, Commentf("%s", path))
+	}
+}
+
 func (s *ChrootSuite) TestLeadingPeriodsPathNotCrossedBoundary(c *C) {
 	m := &test.BasicMock{}
 
@@ -166,6 +194,29 @@ func (s *ChrootSuite) TestRemoveErrCrossedBoundary(c *C) {
 	c.Assert(err, Equals, billy.ErrCrossedBoundary)
 }
 
+func (s *ChrootSuite) TestRemoveMany(c *C) {
+	m := &test.BulkRemoverMock{Errors: map[string]error{
+		"/foo/qux": os.ErrPermission,
+	}}
+
+	fs := New(m, "/foo")
+	err := billy.RemoveMany(fs, []string{"bar", "qux"})
+	c.Assert(err, DeepEquals, &billy.RemoveManyError{Errors: map[string]error{
+		"qux": os.ErrPermission,
+	}})
+
+	c.Assert(m.RemoveManyArgs, DeepEquals, [][]string{{"/foo/bar", "/foo/qux"}})
+}
+
+func (s *ChrootSuite) TestRemoveManyErrCrossedBoundary(c *C) {
+	m := &test.BulkRemoverMock{}
+
+	fs := New(m, "/foo")
+	err := billy.RemoveMany(fs, []string{"bar", "../foo"})
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+	c.Assert(m.RemoveManyArgs, HasLen, 0)
+}
+
 func (s *ChrootSuite) TestTempFile(c *C) {
 	m := &test.TempFileMock{}
 
@@ -189,8 +240,32 @@ func (s *ChrootSuite) TestTempFileWithBasic(c *C) {
 	m := &test.BasicMock{}
 
 	fs := New(m, "/foo")
-	_, err := fs.TempFile("", "")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	f, err := fs.TempFile("bar", "qux")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Matches, filepath.Join("bar", "qux")+"[0-9]+")
+
+	c.Assert(m.OpenFileArgs, HasLen, 1)
+	c.Assert(filepath.Dir(m.OpenFileArgs[0][0].(string)), Equals, "/foo/bar")
+}
+
+func (s *ChrootSuite) TestTempDir(c *C) {
+	m := &test.TempFileMock{}
+
+	fs := New(m, "/foo")
+	name, err := fs.TempDir("bar", "qux")
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, filepath.Join("bar", "tempdir"))
+
+	c.Assert(m.TempDirArgs, HasLen, 1)
+	c.Assert(m.TempDirArgs[0], Equals, [2]string{"/foo/bar", "qux"})
+}
+
+func (s *ChrootSuite) TestTempDirErrCrossedBoundary(c *C) {
+	m := &test.TempFileMock{}
+
+	fs := New(m, "/foo")
+	_, err := fs.TempDir("../foo", "qux")
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
 }
 
 func (s *ChrootSuite) TestReadDir(c *C) {
@@ -270,8 +345,11 @@ func (s *ChrootSuite) TestLstatWithBasic(c *C) {
 	m := &test.BasicMock{}
 
 	fs := New(m, "/foo")
-	_, err := fs.Lstat("")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	_, err := fs.Lstat("bar")
+	c.Assert(err, IsNil)
+
+	c.Assert(m.StatArgs, HasLen, 1)
+	c.Assert(m.StatArgs[0], Equals, "/foo/bar")
 }
 
 func (s *ChrootSuite) TestSymlink(c *C) {
@@ -309,7 +387,7 @@ func (s *ChrootSuite) TestSymlinkWithBasic(c *C) {
 
 	fs := New(m, "/foo")
 	err := fs.Symlink("qux", "bar")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	c.Assert(err.(*os.LinkError).Err, Equals, billy.ErrNotSupported)
 }
 
 func (s *ChrootSuite) TestReadlink(c *C) {
@@ -349,7 +427,7 @@ func (s *ChrootSuite) TestReadlinkWithBasic(c *C) {
 
 	fs := New(m, "/foo")
 	_, err := fs.Readlink("")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	c.Assert(err.(*os.PathError).Err, Equals, billy.ErrNotSupported)
 }
 
 func (s *ChrootSuite) TestCapabilities(c *C) {
@@ -366,3 +444,40 @@ func testCapabilities(c *C, basic billy.Basic) {
 
 	c.Assert(capabilities, Equals, baseCapabilities)
 }
+
+func (s *ChrootSuite) TestDescribe(c *C) {
+	m := &test.DescribedFs{Description: billy.Description{
+		CaseSensitive: true,
+		MaxPathLength: 100,
+		MaxNameLength: 10,
+	}}
+
+	d := billy.Describe(New(m, "/foo"))
+	c.Assert(d.CaseSensitive, Equals, true)
+	c.Assert(d.MaxPathLength, Equals, 95)
+	c.Assert(d.MaxNameLength, Equals, 10)
+
+	d = billy.Describe(New(&test.BasicMock{}, "/foo"))
+	c.Assert(d, Equals, billy.DefaultDescription)
+}
+
+func (s *ChrootSuite) TestOpenFileOpt(c *C) {
+	m := &test.OptionOpenerFs{}
+
+	fs := New(m, "/foo")
+	f, err := billy.OpenFileOpt(fs, "bar/qux", 42, 0777, billy.WithSnapshot())
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, filepath.Join("bar", "qux"))
+
+	c.Assert(m.OpenFileOptArgs, HasLen, 1)
+	c.Assert(m.OpenFileArgs, HasLen, 1)
+	c.Assert(m.OpenFileArgs[0], Equals, [3]interface{}{"/foo/bar/qux", 42, os.FileMode(0777)})
+}
+
+func (s *ChrootSuite) TestWatchNotSupported(c *C) {
+	underlying := struct{ billy.Filesystem }{New(&test.BasicMock{}, "/")}
+
+	fs := New(underlying, "/foo")
+	_, _, err := fs.(billy.Watcher).Watch("bar", false)
+	c.Assert(err, Equals, billy.ErrNotSupported)
+}
@@ -0,0 +1,58 @@
+//go:build windows
+// +build windows
+
+package chroot
+
+import (
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/test"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ChrootSuite) TestIsCrossBoundariesVolumeName(c *C) {
+	for path, expected := range map[string]bool{
+		`C:\foo`:           true,
+		`C:foo`:            true,
+		`c:/foo`:           true,
+		`\\host\share\foo`: true,
+		`//host/share/foo`: true,
+		`\foo`:             false,
+		`foo\C:`:           false,
+	} {
+		c.Assert(isCrossBoundaries(path), Equals, expected, Commentf("%s", path))
+	}
+}
+
+func (s *ChrootSuite) TestCreateVolumeName(c *C) {
+	m := &test.BasicMock{}
+
+	fs := New(m, `C:\foo`)
+	for _, path := range []string{`D:\bar`, `C:\foo\bar`, `\\host\share\bar`} {
+		_, err := fs.Create(path)
+		c.Assert(err, Equals, billy.ErrCrossedBoundary, Commentf("%s", path))
+	}
+
+	c.Assert(m.CreateArgs, HasLen, 0)
+
+	_, err := fs.Create(`\bar`)
+	c.Assert(err, IsNil)
+	c.Assert(m.CreateArgs, DeepEquals, []string{`C:\foo\bar`})
+}
+
+func (s *ChrootSuite) TestCreateUNCBase(c *C) {
+	m := &test.BasicMock{}
+
+	fs := New(m, `\\host\share\foo`)
+	_, err := fs.Create("bar/qux")
+	c.Assert(err, IsNil)
+	c.Assert(m.CreateArgs, DeepEquals, []string{`\\host\share\foo\bar\qux`})
+}
+
+func (s *ChrootSuite) TestSymlinkVolumeName(c *C) {
+	m := &test.SymlinkMock{}
+
+	fs := New(m, `C:\foo`)
+	err := fs.Symlink(`C:\foo\bar`, "qux")
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+}
@@ -0,0 +1,18 @@
+package chroot_test
+
+import (
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return chroot.New(fs, "/")
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
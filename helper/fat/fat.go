@@ -0,0 +1,177 @@
+// Package fat provides a helper emulating the semantics of FAT-like
+// filesystems on top of any billy filesystem.
+package fat // import "gopkg.in/src-d/go-billy.v4/helper/fat"
+
+import (
+	"os"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/casefs"
+	"gopkg.in/src-d/go-billy.v4/helper/timeskew"
+)
+
+const (
+	// Resolution is the resolution of the modification times.
+	Resolution = 2 * time.Second
+	// MaxNameLength is the maximum length of a path element.
+	MaxNameLength = 255
+
+	dirMode  = os.ModeDir | 0777
+	fileMode = 0666
+)
+
+// FAT is a helper that emulates the constraints of simple filesystems, such
+// as FAT or exFAT, over any filesystem: symlinks are not supported, names are
+// case-insensitive but case-preserving, as with casefs, and limited to 255
+// bytes, modification times have a resolution of 2 seconds and permissions
+// are ignored.
+type FAT struct {
+	underlying billy.Filesystem
+}
+
+// New creates a new filesystem wrapping up 'fs' that behaves as a FAT volume.
+// It allows to test scenarios such as checking out a repository onto an USB
+// stick purely in memory.
+func New(fs billy.Filesystem) billy.Filesystem {
+	return timeskew.New(&FAT{underlying: casefs.New(fs)}, 0, Resolution)
+}
+
+func (fs *FAT) Create(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fileMode)
+}
+
+func (fs *FAT) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *FAT) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	return fs.OpenFileOpt(filename, flag, perm)
+}
+
+// OpenFileOpt implements the OptionOpener interface.
+func (fs *FAT) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	if flag&os.O_CREATE != 0 {
+		if err := fs.Describe().Validate(filename); err != nil {
+			return nil, err
+		}
+	}
+
+	return billy.OpenFileOpt(fs.underlying, filename, flag, fileMode, opts...)
+}
+
+func (fs *FAT) Stat(filename string) (os.FileInfo, error) {
+	fi, err := fs.underlying.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFileInfo(fi), nil
+}
+
+func (fs *FAT) Rename(from, to string) error {
+	if err := fs.Describe().Validate(to); err != nil {
+		return err
+	}
+
+	return fs.underlying.Rename(from, to)
+}
+
+func (fs *FAT) Remove(filename string) error {
+	return fs.underlying.Remove(filename)
+}
+
+func (fs *FAT) Join(elem ...string) string {
+	return fs.underlying.Join(elem...)
+}
+
+func (fs *FAT) TempFile(dir, prefix string) (billy.File, error) {
+	return fs.underlying.TempFile(dir, prefix)
+}
+
+func (fs *FAT) TempDir(dir, prefix string) (string, error) {
+	return fs.underlying.TempDir(dir, prefix)
+}
+
+func (fs *FAT) ReadDir(path string) ([]os.FileInfo, error) {
+	l, err := fs.underlying.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, fi := range l {
+		l[i] = newFileInfo(fi)
+	}
+
+	return l, nil
+}
+
+func (fs *FAT) MkdirAll(filename string, perm os.FileMode) error {
+	if err := fs.Describe().Validate(filename); err != nil {
+		return err
+	}
+
+	return fs.underlying.MkdirAll(filename, dirMode.Perm())
+}
+
+// Lstat behaves as Stat, since symlinks are not supported.
+func (fs *FAT) Lstat(filename string) (os.FileInfo, error) {
+	return fs.Stat(filename)
+}
+
+// Symlink always returns billy.ErrNotSupported.
+func (fs *FAT) Symlink(target, link string) error {
+	return billy.ErrNotSupported
+}
+
+// Readlink always returns billy.ErrNotSupported.
+func (fs *FAT) Readlink(link string) (string, error) {
+	return "", billy.ErrNotSupported
+}
+
+func (fs *FAT) Chroot(path string) (billy.Filesystem, error) {
+	chroot, err := fs.underlying.Chroot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FAT{underlying: chroot}, nil
+}
+
+func (fs *FAT) Root() string {
+	return fs.underlying.Root()
+}
+
+// Capabilities implements the Capable interface.
+func (fs *FAT) Capabilities() billy.Capability {
+	return billy.Capabilities(fs.underlying) &^ billy.SymlinkCapability
+}
+
+// Describe implements the Describer interface.
+func (fs *FAT) Describe() billy.Description {
+	d := billy.Describe(fs.underlying)
+	d.CaseSensitive = false
+	d.CasePreserving = true
+	if d.MaxNameLength == 0 || d.MaxNameLength > MaxNameLength {
+		d.MaxNameLength = MaxNameLength
+	}
+
+	return d
+}
+
+type fileInfo struct {
+	os.FileInfo
+}
+
+func newFileInfo(fi os.FileInfo) os.FileInfo {
+	return &fileInfo{FileInfo: fi}
+}
+
+// Mode returns the same permissions for all the files and directories.
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.IsDir() {
+		return dirMode
+	}
+
+	return fileMode
+}
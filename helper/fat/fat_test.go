@@ -0,0 +1,165 @@
+package fat
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&FATSuite{})
+
+type FATSuite struct {
+	FS billy.Filesystem
+}
+
+func (s *FATSuite) SetUpTest(c *C) {
+	s.FS = New(memfs.New())
+}
+
+func (s *FATSuite) TestCaseInsensitive(c *C) {
+	err := util.WriteFile(s.FS, "Foo/Bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.Open("foo/BAR")
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+	c.Assert(f.Close(), IsNil)
+
+	fi, err := s.FS.Stat("FOO")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "Foo")
+	c.Assert(fi.IsDir(), Equals, true)
+}
+
+func (s *FATSuite) TestCasePreserving(c *C) {
+	err := util.WriteFile(s.FS, "README", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = util.WriteFile(s.FS, "readme", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	l, err := s.FS.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "README")
+	c.Assert(l[0].Size(), Equals, int64(3))
+}
+
+func (s *FATSuite) TestRenameCase(c *C) {
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Rename("foo", "FOO")
+	c.Assert(err, IsNil)
+
+	l, err := s.FS.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "FOO")
+}
+
+func (s *FATSuite) TestRemove(c *C) {
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Remove("FOO")
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *FATSuite) TestNameTooLong(c *C) {
+	name := strings.Repeat("a", MaxNameLength+1)
+
+	_, err := s.FS.Create(name)
+	c.Assert(err, Equals, billy.ErrNameTooLong)
+
+	err = s.FS.MkdirAll(s.FS.Join(name, "foo"), 0755)
+	c.Assert(err, Equals, billy.ErrNameTooLong)
+
+	err = util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Rename("foo", name)
+	c.Assert(err, Equals, billy.ErrNameTooLong)
+}
+
+func (s *FATSuite) TestSymlink(c *C) {
+	err := s.FS.Symlink("foo", "bar")
+	c.Assert(err, Equals, billy.ErrNotSupported)
+
+	_, err = s.FS.Readlink("bar")
+	c.Assert(err, Equals, billy.ErrNotSupported)
+}
+
+func (s *FATSuite) TestPermissions(c *C) {
+	err := util.WriteFile(s.FS, "foo/bar", nil, 0700)
+	c.Assert(err, IsNil)
+
+	fi, err := s.FS.Stat("foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode(), Equals, os.FileMode(0666))
+
+	fi, err = s.FS.Lstat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode(), Equals, os.ModeDir|0777)
+}
+
+func (s *FATSuite) TestModTime(c *C) {
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	fi, err := s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.ModTime().Equal(fi.ModTime().Truncate(Resolution)), Equals, true)
+	c.Assert(fi.ModTime().Nanosecond(), Equals, 0)
+}
+
+func (s *FATSuite) TestChroot(c *C) {
+	err := util.WriteFile(s.FS, "Foo/Bar", nil, 0644)
+	c.Assert(err, IsNil)
+
+	fs, err := s.FS.Chroot("FOO")
+	c.Assert(err, IsNil)
+
+	fi, err := fs.Stat("bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "Bar")
+	c.Assert(fi.ModTime(), Equals, fi.ModTime().Truncate(time.Second))
+}
+
+func (s *FATSuite) TestCapabilities(c *C) {
+	caps := billy.Capabilities(s.FS)
+	c.Assert(caps&billy.SymlinkCapability, Equals, billy.Capability(0))
+	c.Assert(billy.CapabilityCheck(s.FS, billy.WriteCapability), Equals, true)
+}
+
+func (s *FATSuite) TestDescribe(c *C) {
+	d := billy.Describe(s.FS)
+	c.Assert(d.CaseSensitive, Equals, false)
+	c.Assert(d.CasePreserving, Equals, true)
+	c.Assert(d.MaxNameLength, Equals, MaxNameLength)
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs)
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
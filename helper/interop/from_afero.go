@@ -0,0 +1,183 @@
+// Package interop provides adapters between billy filesystems and the
+// filesystems of github.com/spf13/afero, allowing to share backends between
+// both worlds.
+package interop // import "gopkg.in/src-d/go-billy.v4/helper/interop"
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+const defaultDirectoryMode = 0755
+
+// Afero is a billy filesystem backed by an afero.Fs.
+type Afero struct {
+	fs afero.Fs
+}
+
+// FromAfero returns a billy.Filesystem backed by the given afero.Fs.
+//
+// afero has no notion of symbolic links beyond the optional afero.Lstater, so
+// Lstat is only able to report links when the afero.Fs implements it, and
+// Symlink and Readlink always return billy.ErrNotSupported. TempFile is
+// implemented on top of OpenFile using util.TempFile, so it is available on
+// any afero.Fs. Locking is not supported by afero, Lock and Unlock are no-ops.
+func FromAfero(fs afero.Fs) billy.Filesystem {
+	return chroot.New(&Afero{fs: fs}, string(filepath.Separator))
+}
+
+func (fs *Afero) Create(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (fs *Afero) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *Afero) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if flag&os.O_CREATE != 0 {
+		if err := fs.createDir(filename); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := fs.fs.OpenFile(filename, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aferoFile{File: f, name: filename}, nil
+}
+
+func (fs *Afero) createDir(fullpath string) error {
+	dir := filepath.Dir(fullpath)
+	if dir == "." || dir == string(filepath.Separator) {
+		return nil
+	}
+
+	return fs.fs.MkdirAll(dir, defaultDirectoryMode)
+}
+
+func (fs *Afero) Stat(filename string) (os.FileInfo, error) {
+	return fs.fs.Stat(filename)
+}
+
+// Lstat returns the FileInfo of the named file without following symbolic
+// links, when the afero.Fs implements afero.Lstater, otherwise it behaves as
+// Stat.
+func (fs *Afero) Lstat(filename string) (os.FileInfo, error) {
+	if l, ok := fs.fs.(afero.Lstater); ok {
+		fi, _, err := l.LstatIfPossible(filename)
+		return fi, err
+	}
+
+	return fs.fs.Stat(filename)
+}
+
+func (fs *Afero) ReadDir(path string) ([]os.FileInfo, error) {
+	return afero.ReadDir(fs.fs, path)
+}
+
+func (fs *Afero) MkdirAll(filename string, perm os.FileMode) error {
+	return fs.fs.MkdirAll(filename, perm)
+}
+
+func (fs *Afero) Rename(from, to string) error {
+	if err := fs.createDir(to); err != nil {
+		return err
+	}
+
+	return fs.fs.Rename(from, to)
+}
+
+func (fs *Afero) Remove(filename string) error {
+	return fs.fs.Remove(filename)
+}
+
+// RemoveAll removes a path and any children it contains, it is used by
+// util.RemoveAll.
+func (fs *Afero) RemoveAll(path string) error {
+	return fs.fs.RemoveAll(path)
+}
+
+func (fs *Afero) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (fs *Afero) TempFile(dir, prefix string) (billy.File, error) {
+	return util.TempFile(fs, dir, prefix)
+}
+
+func (fs *Afero) TempDir(dir, prefix string) (string, error) {
+	if dir != "" {
+		if err := fs.fs.MkdirAll(dir, defaultDirectoryMode); err != nil {
+			return "", err
+		}
+	}
+
+	return afero.TempDir(fs.fs, dir, prefix)
+}
+
+// Symlink is not supported by afero.
+func (fs *Afero) Symlink(target, link string) error {
+	return billy.ErrNotSupported
+}
+
+// Readlink is not supported by afero.
+func (fs *Afero) Readlink(link string) (string, error) {
+	return "", billy.ErrNotSupported
+}
+
+// Chmod changes the mode of the named file.
+func (fs *Afero) Chmod(name string, mode os.FileMode) error {
+	return fs.fs.Chmod(name, mode)
+}
+
+// Lchown is not supported by afero.
+func (fs *Afero) Lchown(name string, uid, gid int) error {
+	return billy.ErrNotSupported
+}
+
+// Chown is not supported by afero.
+func (fs *Afero) Chown(name string, uid, gid int) error {
+	return billy.ErrNotSupported
+}
+
+// Chtimes changes the access and modification times of the named file.
+func (fs *Afero) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return fs.fs.Chtimes(name, atime, mtime)
+}
+
+// Capabilities implements the Capable interface.
+func (fs *Afero) Capabilities() billy.Capability {
+	return billy.WriteCapability | billy.ReadCapability |
+		billy.ReadAndWriteCapability | billy.SeekCapability |
+		billy.TruncateCapability
+}
+
+// aferoFile is a billy.File backed by an afero.File.
+type aferoFile struct {
+	afero.File
+	name string
+}
+
+// Name returns the name of the file as presented to Open.
+func (f *aferoFile) Name() string {
+	return f.name
+}
+
+// Lock is a no-op, afero doesn't support locking.
+func (f *aferoFile) Lock() error {
+	return nil
+}
+
+// Unlock is a no-op, afero doesn't support locking.
+func (f *aferoFile) Unlock() error {
+	return nil
+}
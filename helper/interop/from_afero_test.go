@@ -0,0 +1,57 @@
+package interop
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&FromAferoSuite{})
+
+type FromAferoSuite struct {
+	test.FilesystemSuite
+	path string
+}
+
+func (s *FromAferoSuite) SetUpTest(c *C) {
+	s.path, _ = ioutil.TempDir(os.TempDir(), "go-billy-interop-test")
+	fs := afero.NewBasePathFs(afero.NewOsFs(), s.path)
+	s.FilesystemSuite = test.NewFilesystemSuite(FromAfero(fs))
+}
+
+func (s *FromAferoSuite) TearDownTest(c *C) {
+	err := os.RemoveAll(s.path)
+	c.Assert(err, IsNil)
+}
+
+func (s *FromAferoSuite) TestSharedBackend(c *C) {
+	backend := afero.NewMemMapFs()
+	fs := FromAfero(backend)
+
+	err := util.WriteFile(fs, "foo/bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	content, err := afero.ReadFile(backend, "/foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *FromAferoSuite) TestSymlinkNotSupported(c *C) {
+	fs := FromAfero(afero.NewMemMapFs())
+	c.Assert(billy.CapabilityCheck(fs, billy.SymlinkCapability), Equals, false)
+
+	err := fs.Symlink("foo", "bar")
+	c.Assert(err, Equals, billy.ErrNotSupported)
+
+	_, err = fs.Readlink("bar")
+	c.Assert(err, Equals, billy.ErrNotSupported)
+}
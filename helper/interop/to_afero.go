@@ -0,0 +1,291 @@
+package interop
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// Billy is an afero.Fs backed by a billy.Filesystem.
+type Billy struct {
+	fs billy.Filesystem
+}
+
+// ToAfero returns an afero.Fs backed by the given billy.Filesystem.
+//
+// afero has no API to create symbolic links, the links already present in the
+// billy.Filesystem are followed on Open and Stat, and reported as such by
+// LstatIfPossible. billy's TempFile has no afero counterpart, afero.TempFile
+// works on top of OpenFile, as with any other afero.Fs. Chmod and Chtimes are
+// only supported if the billy.Filesystem implements billy.Change.
+func ToAfero(fs billy.Filesystem) afero.Fs {
+	return &Billy{fs: fs}
+}
+
+func (fs *Billy) Create(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// Mkdir creates a directory, unlike MkdirAll it fails if the directory
+// already exists or its parent doesn't.
+func (fs *Billy) Mkdir(name string, perm os.FileMode) error {
+	if _, err := fs.fs.Lstat(name); err == nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+
+	parent, err := fs.fs.Stat(filepath.Dir(name))
+	if err != nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrNotExist}
+	}
+
+	if !parent.IsDir() {
+		return &os.PathError{Op: "mkdir", Path: name, Err: billy.ErrNotDir}
+	}
+
+	return fs.fs.MkdirAll(name, perm)
+}
+
+func (fs *Billy) MkdirAll(path string, perm os.FileMode) error {
+	return fs.fs.MkdirAll(path, perm)
+}
+
+func (fs *Billy) Open(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile opens the named file, directories can be opened in read-only mode
+// to be listed with Readdir.
+func (fs *Billy) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) == 0 {
+		fi, err := fs.fs.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if fi.IsDir() {
+			return &dir{fs: fs.fs, name: name, info: fi}, nil
+		}
+	}
+
+	f, err := fs.fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{File: f, fs: fs.fs, name: name}, nil
+}
+
+func (fs *Billy) Remove(name string) error {
+	return fs.fs.Remove(name)
+}
+
+func (fs *Billy) RemoveAll(path string) error {
+	return util.RemoveAll(fs.fs, path)
+}
+
+func (fs *Billy) Rename(oldname, newname string) error {
+	return fs.fs.Rename(oldname, newname)
+}
+
+func (fs *Billy) Stat(name string) (os.FileInfo, error) {
+	return fs.fs.Stat(name)
+}
+
+// LstatIfPossible implements the afero.Lstater interface.
+func (fs *Billy) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	fi, err := fs.fs.Lstat(name)
+	return fi, true, err
+}
+
+func (fs *Billy) Name() string {
+	return "billy"
+}
+
+// Chmod changes the mode of the named file, it returns billy.ErrNotSupported
+// if the billy.Filesystem doesn't implement billy.Change.
+func (fs *Billy) Chmod(name string, mode os.FileMode) error {
+	c, ok := fs.fs.(billy.Change)
+	if !ok {
+		return billy.ErrNotSupported
+	}
+
+	return c.Chmod(name, mode)
+}
+
+// Chtimes changes the access and modification times of the named file, it
+// returns billy.ErrNotSupported if the billy.Filesystem doesn't implement
+// billy.Change.
+func (fs *Billy) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	c, ok := fs.fs.(billy.Change)
+	if !ok {
+		return billy.ErrNotSupported
+	}
+
+	return c.Chtimes(name, atime, mtime)
+}
+
+// file is an afero.File backed by a billy.File.
+type file struct {
+	billy.File
+	fs   billy.Filesystem
+	name string
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+// WriteAt writes at the given offset, restoring the current offset afterwards.
+func (f *file) WriteAt(p []byte, off int64) (int, error) {
+	if w, ok := f.File.(io.WriterAt); ok {
+		return w.WriteAt(p, off)
+	}
+
+	cur, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := f.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := f.Write(p)
+	if _, serr := f.Seek(cur, io.SeekStart); err == nil {
+		err = serr
+	}
+
+	return n, err
+}
+
+func (f *file) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *file) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, &os.PathError{Op: "readdir", Path: f.name, Err: billy.ErrNotDir}
+}
+
+func (f *file) Readdirnames(n int) ([]string, error) {
+	return nil, &os.PathError{Op: "readdir", Path: f.name, Err: billy.ErrNotDir}
+}
+
+func (f *file) Stat() (os.FileInfo, error) {
+	return f.fs.Stat(f.name)
+}
+
+// Sync commits the content of the file, if the billy.File supports it.
+func (f *file) Sync() error {
+	return billy.Sync(f.File)
+}
+
+// dir is a read-only afero.File representing a directory.
+type dir struct {
+	fs      billy.Filesystem
+	name    string
+	info    os.FileInfo
+	entries []os.FileInfo
+	read    bool
+	closed  bool
+}
+
+func (d *dir) Name() string {
+	return d.name
+}
+
+func (d *dir) Readdir(count int) ([]os.FileInfo, error) {
+	if d.closed {
+		return nil, os.ErrClosed
+	}
+
+	if !d.read {
+		entries, err := d.fs.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+
+		d.entries, d.read = entries, true
+	}
+
+	if count <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if count > len(d.entries) {
+		count = len(d.entries)
+	}
+
+	entries := d.entries[:count]
+	d.entries = d.entries[count:]
+	return entries, nil
+}
+
+func (d *dir) Readdirnames(n int) ([]string, error) {
+	entries, err := d.Readdir(n)
+	names := make([]string, len(entries))
+	for i, fi := range entries {
+		names[i] = fi.Name()
+	}
+
+	return names, err
+}
+
+func (d *dir) Stat() (os.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dir) Close() error {
+	if d.closed {
+		return os.ErrClosed
+	}
+
+	d.closed = true
+	return nil
+}
+
+func (d *dir) Sync() error {
+	return nil
+}
+
+func (d *dir) Read(p []byte) (int, error) {
+	return 0, d.isDir("read")
+}
+
+func (d *dir) ReadAt(p []byte, off int64) (int, error) {
+	return 0, d.isDir("read")
+}
+
+func (d *dir) Seek(offset int64, whence int) (int64, error) {
+	return 0, d.isDir("seek")
+}
+
+func (d *dir) Write(p []byte) (int, error) {
+	return 0, d.isDir("write")
+}
+
+func (d *dir) WriteAt(p []byte, off int64) (int, error) {
+	return 0, d.isDir("write")
+}
+
+func (d *dir) WriteString(s string) (int, error) {
+	return 0, d.isDir("write")
+}
+
+func (d *dir) Truncate(size int64) error {
+	return d.isDir("truncate")
+}
+
+func (d *dir) isDir(op string) error {
+	return &os.PathError{Op: op, Path: d.name, Err: billy.ErrIsDir}
+}
@@ -0,0 +1,150 @@
+package interop
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/afero"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&ToAferoSuite{})
+
+type ToAferoSuite struct {
+	backend billy.Filesystem
+	fs      afero.Fs
+}
+
+func (s *ToAferoSuite) SetUpTest(c *C) {
+	s.backend = memfs.New()
+	s.fs = ToAfero(s.backend)
+}
+
+func (s *ToAferoSuite) TestWriteFile(c *C) {
+	err := afero.WriteFile(s.fs, "foo/bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	content, err := readFile(s.backend, "foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *ToAferoSuite) TestMkdir(c *C) {
+	err := s.fs.Mkdir("foo/bar", 0755)
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	err = s.fs.Mkdir("foo", 0755)
+	c.Assert(err, IsNil)
+
+	err = s.fs.Mkdir("foo", 0755)
+	c.Assert(os.IsExist(err), Equals, true)
+
+	fi, err := s.backend.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+}
+
+func (s *ToAferoSuite) TestReaddir(c *C) {
+	for _, name := range []string{"foo/a", "foo/b", "foo/c"} {
+		c.Assert(util.WriteFile(s.backend, name, nil, 0644), IsNil)
+	}
+
+	f, err := s.fs.Open("foo")
+	c.Assert(err, IsNil)
+
+	names, err := f.Readdirnames(2)
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"a", "b"})
+
+	names, err = f.Readdirnames(2)
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"c"})
+
+	_, err = f.Readdirnames(2)
+	c.Assert(err, Equals, io.EOF)
+
+	_, err = f.Read(make([]byte, 1))
+	c.Assert(err, NotNil)
+	c.Assert(f.Close(), IsNil)
+
+	entries, err := afero.ReadDir(s.fs, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+}
+
+func (s *ToAferoSuite) TestWriteAt(c *C) {
+	f, err := s.fs.Create("foo")
+	c.Assert(err, IsNil)
+
+	_, err = f.WriteString("foo bar")
+	c.Assert(err, IsNil)
+
+	_, err = f.WriteAt([]byte("qux"), 4)
+	c.Assert(err, IsNil)
+
+	_, err = f.WriteString("!")
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	content, err := readFile(s.backend, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo qux!")
+}
+
+func (s *ToAferoSuite) TestRemoveAll(c *C) {
+	c.Assert(util.WriteFile(s.backend, "foo/bar/qux", nil, 0644), IsNil)
+
+	err := s.fs.RemoveAll("foo")
+	c.Assert(err, IsNil)
+
+	_, err = s.backend.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *ToAferoSuite) TestLstatIfPossible(c *C) {
+	c.Assert(util.WriteFile(s.backend, "foo", nil, 0644), IsNil)
+	c.Assert(s.backend.Symlink("foo", "bar"), IsNil)
+
+	fi, ok, err := s.fs.(afero.Lstater).LstatIfPossible("bar")
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	c.Assert(fi.Mode()&os.ModeSymlink, Equals, os.ModeSymlink)
+}
+
+func (s *ToAferoSuite) TestChmodNotSupported(c *C) {
+	c.Assert(util.WriteFile(s.backend, "foo", nil, 0644), IsNil)
+	c.Assert(s.fs.Chmod("foo", 0600), Equals, billy.ErrNotSupported)
+}
+
+func (s *ToAferoSuite) TestRoundTrip(c *C) {
+	fs := FromAfero(ToAfero(s.backend))
+	c.Assert(util.WriteFile(fs, "foo/bar", []byte("foo"), 0644), IsNil)
+
+	content, err := readFile(s.backend, "foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func readFile(fs billy.Basic, filename string) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return FromAfero(ToAfero(fs))
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
@@ -0,0 +1,393 @@
+// Package limit provides a helper that bounds the usage of a billy filesystem,
+// failing the operations exceeding the limits.
+package limit // import "gopkg.in/src-d/go-billy.v4/helper/limit"
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/helper/polyfill"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// ErrQuotaExceeded is returned, wrapped in a *os.PathError, by the operations
+// that would exceed any of the limits.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Limits are the limits of the usage of a filesystem, a zero value means no
+// limit.
+type Limits struct {
+	// MaxTotalBytes is the maximum size of all the files together.
+	MaxTotalBytes int64
+	// MaxFileSize is the maximum size of any file.
+	MaxFileSize int64
+	// MaxFiles is the maximum number of files, directories and symbolic
+	// links together.
+	MaxFiles int
+}
+
+// Usage is the usage of a filesystem.
+type Usage struct {
+	// Bytes is the size of all the files together.
+	Bytes int64
+	// Files is the number of files, directories and symbolic links.
+	Files int
+}
+
+// Limit is a helper that tracks the usage of the underlying filesystem, and
+// fails the creations and the writes that would exceed the limits with
+// ErrQuotaExceeded. The usage is tracked from the operations done through
+// Limit, the changes done to the underlying filesystem directly aren't
+// accounted, and the hard links and the files opened in a path renamed
+// afterwards may be miscounted.
+type Limit struct {
+	underlying billy.Filesystem
+	limits     Limits
+
+	m     sync.Mutex
+	usage Usage
+}
+
+// New creates a new filesystem wrapping up fs, enforcing the given limits. The
+// initial usage is computed walking the whole fs.
+func New(fs billy.Basic, l Limits) (*Limit, error) {
+	h := &Limit{underlying: polyfill.New(fs), limits: l}
+
+	root := string(filepath.Separator)
+	err := util.Walk(h.underlying, root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		h.usage.Files++
+		if fi.Mode().IsRegular() {
+			h.usage.Bytes += fi.Size()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Usage returns the current usage of the filesystem.
+func (h *Limit) Usage() Usage {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.usage
+}
+
+// reserve adds the given number of files and bytes to the usage, failing if
+// the limits would be exceeded.
+func (h *Limit) reserve(op, path string, files int, bytes int64) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.limits.MaxFiles > 0 && files > 0 && h.usage.Files+files > h.limits.MaxFiles ||
+		h.limits.MaxTotalBytes > 0 && bytes > 0 && h.usage.Bytes+bytes > h.limits.MaxTotalBytes {
+		return &os.PathError{Op: op, Path: path, Err: ErrQuotaExceeded}
+	}
+
+	h.usage.Files += files
+	h.usage.Bytes += bytes
+	return nil
+}
+
+// release subtracts the given number of files and bytes from the usage.
+func (h *Limit) release(files int, bytes int64) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.usage.Files -= files
+	h.usage.Bytes -= bytes
+}
+
+// missing returns the number of directories that would be created to create
+// path as a directory.
+func (h *Limit) missing(path string) int {
+	var n int
+	for path != "." && path != string(filepath.Separator) && path != "" {
+		if _, err := h.underlying.Stat(path); err == nil {
+			break
+		}
+
+		n++
+		path = filepath.Dir(path)
+	}
+
+	return n
+}
+
+func (h *Limit) Create(filename string) (billy.File, error) {
+	return h.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (h *Limit) Open(filename string) (billy.File, error) {
+	return h.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (h *Limit) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	return h.OpenFileOpt(filename, flag, perm)
+}
+
+// OpenFileOpt implements the OptionOpener interface, the options are passed
+// to the underlying filesystem.
+func (h *Limit) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	var files int
+	var truncated int64
+	fi, err := h.underlying.Stat(filename)
+	switch {
+	case os.IsNotExist(err) && flag&os.O_CREATE != 0:
+		files = 1 + h.missing(filepath.Dir(filename))
+	case err == nil && flag&os.O_TRUNC != 0 && fi.Mode().IsRegular():
+		truncated = fi.Size()
+	}
+
+	if err := h.reserve("open", filename, files, 0); err != nil {
+		return nil, err
+	}
+
+	f, err := billy.OpenFileOpt(h.underlying, filename, flag, perm, opts...)
+	if err != nil {
+		h.release(files, 0)
+		return nil, err
+	}
+
+	h.release(0, truncated)
+	return &file{File: f, h: h, name: filename, append: flag&os.O_APPEND != 0}, nil
+}
+
+func (h *Limit) Stat(filename string) (os.FileInfo, error) {
+	return h.underlying.Stat(filename)
+}
+
+func (h *Limit) Lstat(filename string) (os.FileInfo, error) {
+	return h.underlying.Lstat(filename)
+}
+
+func (h *Limit) Rename(from, to string) error {
+	fi, err := h.underlying.Lstat(to)
+	if err := h.underlying.Rename(from, to); err != nil {
+		return err
+	}
+
+	if err == nil {
+		h.release(1, regularSize(fi))
+	}
+
+	return nil
+}
+
+func (h *Limit) Remove(filename string) error {
+	fi, err := h.underlying.Lstat(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := h.underlying.Remove(filename); err != nil {
+		return err
+	}
+
+	h.release(1, regularSize(fi))
+	return nil
+}
+
+func (h *Limit) TempFile(dir, prefix string) (billy.File, error) {
+	files := 1 + h.missing(dir)
+	if err := h.reserve("open", dir, files, 0); err != nil {
+		return nil, err
+	}
+
+	f, err := h.underlying.TempFile(dir, prefix)
+	if err != nil {
+		h.release(files, 0)
+		return nil, err
+	}
+
+	return &file{File: f, h: h, name: f.Name()}, nil
+}
+
+func (h *Limit) TempDir(dir, prefix string) (string, error) {
+	files := 1 + h.missing(dir)
+	if err := h.reserve("mkdir", dir, files, 0); err != nil {
+		return "", err
+	}
+
+	name, err := h.underlying.TempDir(dir, prefix)
+	if err != nil {
+		h.release(files, 0)
+	}
+
+	return name, err
+}
+
+func (h *Limit) ReadDir(path string) ([]os.FileInfo, error) {
+	return h.underlying.ReadDir(path)
+}
+
+func (h *Limit) MkdirAll(filename string, perm os.FileMode) error {
+	files := h.missing(filename)
+	if err := h.reserve("mkdir", filename, files, 0); err != nil {
+		return err
+	}
+
+	if err := h.underlying.MkdirAll(filename, perm); err != nil {
+		h.release(files, 0)
+		return err
+	}
+
+	return nil
+}
+
+func (h *Limit) Symlink(target, link string) error {
+	files := 1 + h.missing(filepath.Dir(link))
+	if err := h.reserve("symlink", link, files, 0); err != nil {
+		return err
+	}
+
+	if err := h.underlying.Symlink(target, link); err != nil {
+		h.release(files, 0)
+		return err
+	}
+
+	return nil
+}
+
+func (h *Limit) Readlink(link string) (string, error) {
+	return h.underlying.Readlink(link)
+}
+
+func (h *Limit) Join(elem ...string) string {
+	return h.underlying.Join(elem...)
+}
+
+func (h *Limit) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Limit) Root() string {
+	return h.underlying.Root()
+}
+
+// Capabilities implements the Capable interface.
+func (h *Limit) Capabilities() billy.Capability {
+	return billy.Capabilities(h.underlying)
+}
+
+// Describe implements the Describer interface.
+func (h *Limit) Describe() billy.Description {
+	return billy.Describe(h.underlying)
+}
+
+func regularSize(fi os.FileInfo) int64 {
+	if !fi.Mode().IsRegular() {
+		return 0
+	}
+
+	return fi.Size()
+}
+
+type file struct {
+	billy.File
+	h      *Limit
+	name   string
+	append bool
+}
+
+// size returns the current size of the file.
+func (f *file) size() (int64, error) {
+	fi, err := f.h.underlying.Stat(f.name)
+	if err != nil {
+		return 0, err
+	}
+
+	return fi.Size(), nil
+}
+
+// resize reserves the bytes needed to grow the file from size to end, failing
+// if the file or the filesystem would exceed their limits. It returns the
+// bytes reserved.
+func (f *file) resize(op string, size, end int64) (int64, error) {
+	if end <= size {
+		return 0, nil
+	}
+
+	if f.h.limits.MaxFileSize > 0 && end > f.h.limits.MaxFileSize {
+		return 0, &os.PathError{Op: op, Path: f.name, Err: ErrQuotaExceeded}
+	}
+
+	return end - size, f.h.reserve(op, f.name, 0, end-size)
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	size, err := f.size()
+	if err != nil {
+		return 0, err
+	}
+
+	pos := size
+	if !f.append {
+		if pos, err = f.File.Seek(0, io.SeekCurrent); err != nil {
+			pos = size
+		}
+	}
+
+	reserved, err := f.resize("write", size, pos+int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := f.File.Write(p)
+	if end := pos + int64(n); end < pos+int64(len(p)) {
+		grown := end - size
+		if grown < 0 {
+			grown = 0
+		}
+
+		f.h.release(0, reserved-grown)
+	}
+
+	return n, err
+}
+
+func (f *file) Truncate(size int64) error {
+	current, err := f.size()
+	if err != nil {
+		return err
+	}
+
+	reserved, err := f.resize("truncate", current, size)
+	if err != nil {
+		return err
+	}
+
+	if err := f.File.Truncate(size); err != nil {
+		f.h.release(0, reserved)
+		return err
+	}
+
+	if size < current {
+		f.h.release(0, current-size)
+	}
+
+	return nil
+}
+
+// Sync implements the Syncer interface.
+func (f *file) Sync() error {
+	return billy.Sync(f.File)
+}
@@ -0,0 +1,187 @@
+package limit
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&LimitSuite{})
+
+type LimitSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *LimitSuite) SetUpTest(c *C) {
+	fs, err := New(memfs.New(), Limits{})
+	c.Assert(err, IsNil)
+	s.FilesystemSuite = test.NewFilesystemSuite(fs)
+}
+
+func isQuotaExceeded(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded)
+}
+
+func (s *LimitSuite) TestInitialUsage(c *C) {
+	underlying := memfs.New()
+	c.Assert(util.WriteFile(underlying, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(underlying, "bar/qux", []byte("quux"), 0644), IsNil)
+
+	fs, err := New(underlying, Limits{})
+	c.Assert(err, IsNil)
+	c.Assert(fs.Usage(), Equals, Usage{Bytes: 7, Files: 3})
+}
+
+func (s *LimitSuite) TestMaxFiles(c *C) {
+	fs, err := New(memfs.New(), Limits{MaxFiles: 3})
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(fs, "foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "bar/qux", nil, 0644), IsNil)
+	c.Assert(fs.Usage().Files, Equals, 3)
+
+	err = util.WriteFile(fs, "baz", nil, 0644)
+	c.Assert(isQuotaExceeded(err), Equals, true)
+	c.Assert(isQuotaExceeded(fs.MkdirAll("qux", 0755)), Equals, true)
+	c.Assert(isQuotaExceeded(fs.Symlink("foo", "link")), Equals, true)
+	_, err = fs.TempFile("", "tmp")
+	c.Assert(isQuotaExceeded(err), Equals, true)
+	c.Assert(fs.Usage().Files, Equals, 3)
+
+	_, err = fs.Stat("baz")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	// opening an existing file doesn't count.
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+
+	c.Assert(fs.Remove("foo"), IsNil)
+	c.Assert(util.WriteFile(fs, "baz", nil, 0644), IsNil)
+	c.Assert(fs.Usage().Files, Equals, 3)
+}
+
+func (s *LimitSuite) TestMaxFilesMkdirAll(c *C) {
+	fs, err := New(memfs.New(), Limits{MaxFiles: 2})
+	c.Assert(err, IsNil)
+
+	c.Assert(isQuotaExceeded(fs.MkdirAll("foo/bar/qux", 0755)), Equals, true)
+	c.Assert(fs.MkdirAll("foo/bar", 0755), IsNil)
+	c.Assert(fs.MkdirAll("foo/bar", 0755), IsNil)
+	c.Assert(fs.Usage().Files, Equals, 2)
+}
+
+func (s *LimitSuite) TestMaxFileSize(c *C) {
+	fs, err := New(memfs.New(), Limits{MaxFileSize: 4})
+	c.Assert(err, IsNil)
+
+	f, err := fs.Create("foo")
+	c.Assert(err, IsNil)
+	n, err := f.Write([]byte("foo"))
+	c.Assert(n, Equals, 3)
+	c.Assert(err, IsNil)
+
+	n, err = f.Write([]byte("ba"))
+	c.Assert(n, Equals, 0)
+	c.Assert(isQuotaExceeded(err), Equals, true)
+
+	n, err = f.Write([]byte("b"))
+	c.Assert(n, Equals, 1)
+	c.Assert(err, IsNil)
+
+	c.Assert(isQuotaExceeded(f.Truncate(5)), Equals, true)
+	c.Assert(f.Truncate(2), IsNil)
+	c.Assert(f.Close(), IsNil)
+	c.Assert(fs.Usage().Bytes, Equals, int64(2))
+}
+
+func (s *LimitSuite) TestMaxTotalBytes(c *C) {
+	fs, err := New(memfs.New(), Limits{MaxTotalBytes: 6})
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "bar", []byte("bar"), 0644), IsNil)
+	err = util.WriteFile(fs, "qux", []byte("q"), 0644)
+	c.Assert(isQuotaExceeded(err), Equals, true)
+
+	// rewriting a file in place doesn't grow it.
+	c.Assert(util.WriteFile(fs, "foo", []byte("FOO"), 0644), IsNil)
+
+	f, err := fs.OpenFile("bar", os.O_WRONLY, 0)
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("BAR"))
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("!"))
+	c.Assert(isQuotaExceeded(err), Equals, true)
+	c.Assert(f.Close(), IsNil)
+
+	c.Assert(util.RemoveAll(fs, "foo"), IsNil)
+	c.Assert(fs.Usage(), Equals, Usage{Bytes: 3, Files: 2})
+	c.Assert(util.WriteFile(fs, "qux", []byte("qux"), 0644), IsNil)
+}
+
+func (s *LimitSuite) TestRenameOverExisting(c *C) {
+	fs, err := New(memfs.New(), Limits{})
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "bar", []byte("barbar"), 0644), IsNil)
+	c.Assert(fs.Rename("foo", "bar"), IsNil)
+	c.Assert(fs.Usage(), Equals, Usage{Bytes: 3, Files: 1})
+}
+
+func (s *LimitSuite) TestErrorIsPathError(c *C) {
+	fs, err := New(memfs.New(), Limits{MaxFiles: 1})
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(fs, "foo", nil, 0644), IsNil)
+	_, err = fs.Create("bar")
+	c.Assert(err, DeepEquals, &os.PathError{Op: "open", Path: "bar", Err: ErrQuotaExceeded})
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	h, err := New(fs, Limits{MaxTotalBytes: 1 << 20, MaxFiles: 100})
+	if err != nil {
+		panic(err)
+	}
+
+	return h
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
+
+// optionsFS records the options of OpenFileOpt.
+type optionsFS struct {
+	billy.Filesystem
+	opts [][]billy.OpenOption
+}
+
+func (fs *optionsFS) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	fs.opts = append(fs.opts, opts)
+	return fs.Filesystem.OpenFile(filename, flag, perm)
+}
+
+func (s *LimitSuite) TestOpenFileOpt(c *C) {
+	underlying := &optionsFS{Filesystem: memfs.New()}
+	fs, err := New(underlying, Limits{MaxFiles: 1})
+	c.Assert(err, IsNil)
+
+	f, err := billy.OpenFileOpt(fs, "foo", os.O_RDWR|os.O_CREATE, 0644, billy.WithSnapshot())
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+	c.Assert(underlying.opts, HasLen, 1)
+	c.Assert(underlying.opts[0], HasLen, 1)
+
+	_, err = billy.OpenFileOpt(fs, "bar", os.O_RDWR|os.O_CREATE, 0644, billy.WithSnapshot())
+	c.Assert(isQuotaExceeded(err), Equals, true)
+	c.Assert(underlying.opts, HasLen, 1)
+}
@@ -10,6 +10,7 @@ import (
 
 	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/helper/polyfill"
+	"gopkg.in/src-d/go-billy.v4/util"
 )
 
 var separator = string(filepath.Separator)
@@ -63,16 +64,31 @@ func (h *Mount) OpenFile(path string, flag int, mode os.FileMode) (billy.File, e
 	return wrapFile(f, path), err
 }
 
+// OpenFileOpt implements the OptionOpener interface.
+func (h *Mount) OpenFileOpt(path string, flag int, mode os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	fs, fullpath := h.getBasicAndPath(path)
+	if fullpath == "." {
+		return nil, os.ErrInvalid
+	}
+
+	f, err := billy.OpenFileOpt(fs, fullpath, flag, mode, opts...)
+	return wrapFile(f, path), err
+}
+
 func (h *Mount) Rename(from, to string) error {
 	fromInSource := h.isMountpoint(from)
 	toInSource := h.isMountpoint(to)
 
-	var fromFS, toFS billy.Basic
+	var fromFS, toFS billy.Filesystem
 
 	switch {
 	case fromInSource && toInSource:
 		from = h.mustRelToMountpoint(from)
 		to = h.mustRelToMountpoint(to)
+		if from == "." || to == "." {
+			return os.ErrInvalid
+		}
+
 		return h.source.Rename(from, to)
 	case !fromInSource && !toInSource:
 		return h.underlying.Rename(from, to)
@@ -88,11 +104,60 @@ func (h *Mount) Rename(from, to string) error {
 		to = h.mustRelToMountpoint(to)
 	}
 
-	if err := copyPath(fromFS, toFS, from, to); err != nil {
+	if from == "." || to == "." {
+		return os.ErrInvalid
+	}
+
+	if err := checkReplace(fromFS, toFS, from, to); err != nil {
+		return err
+	}
+
+	if err := copyTree(fromFS, toFS, from, to); err != nil {
+		return err
+	}
+
+	return util.RemoveAll(fromFS, from)
+}
+
+// checkReplace returns an error if the file at from of src can't replace the
+// one at to of dst, as os.Rename does: a file replaces a file, and a directory
+// replaces an empty directory.
+func checkReplace(src, dst billy.Filesystem, from, to string) error {
+	fi, err := src.Lstat(from)
+	if err != nil {
+		return err
+	}
+
+	dfi, err := dst.Lstat(to)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
 		return err
 	}
 
-	return fromFS.Remove(from)
+	switch {
+	case fi.IsDir() && !dfi.IsDir():
+		err = billy.ErrNotDir
+	case !fi.IsDir() && dfi.IsDir():
+		err = billy.ErrIsDir
+	case dfi.IsDir():
+		entries, rerr := dst.ReadDir(to)
+		if rerr != nil {
+			return rerr
+		}
+
+		if len(entries) != 0 {
+			err = billy.ErrNotEmpty
+		}
+	}
+
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: err}
+	}
+
+	return nil
 }
 
 func (h *Mount) Stat(path string) (os.FileInfo, error) {
@@ -109,6 +174,19 @@ func (h *Mount) Remove(path string) error {
 	return fs.Remove(fullpath)
 }
 
+// RemoveAll removes path and any children it contains, without crossing the
+// mountpoint: a path in the source filesystem is removed from it, but the
+// contents of the source are never removed as children of a path of the
+// underlying filesystem.
+func (h *Mount) RemoveAll(path string) error {
+	fs, fullpath := h.getBasicAndPath(path)
+	if fullpath == "." {
+		return os.ErrInvalid
+	}
+
+	return util.RemoveAll(fs, fullpath)
+}
+
 func (h *Mount) ReadDir(path string) ([]os.FileInfo, error) {
 	fs, fullpath, err := h.getDirAndPath(path)
 	if err != nil {
@@ -141,6 +219,22 @@ func (h *Mount) Symlink(target, link string) error {
 	return fs.Symlink(target, fullpath)
 }
 
+// Link implements the Linker interface, both paths must be in the same
+// filesystem.
+func (h *Mount) Link(oldname, newname string) error {
+	if h.isMountpoint(oldname) != h.isMountpoint(newname) {
+		return fmt.Errorf("invalid link, crossing filesystems")
+	}
+
+	fs, oldpath := h.getBasicAndPath(oldname)
+	_, newpath := h.getBasicAndPath(newname)
+	if oldpath == "." || newpath == "." {
+		return os.ErrInvalid
+	}
+
+	return billy.Link(fs, oldpath, newpath)
+}
+
 func (h *Mount) Join(elem ...string) string {
 	return h.underlying.Join(elem...)
 }
@@ -172,6 +266,36 @@ func (fs *Mount) Capabilities() billy.Capability {
 	return billy.Capabilities(fs.underlying) & billy.Capabilities(fs.source)
 }
 
+// Describe implements the Describer interface. It returns the most restrictive
+// combination of the description of both filesystems.
+func (fs *Mount) Describe() billy.Description {
+	u := billy.Describe(fs.underlying)
+	s := billy.Describe(fs.source)
+
+	d := billy.Description{
+		CaseSensitive:  u.CaseSensitive && s.CaseSensitive,
+		CasePreserving: u.CasePreserving && s.CasePreserving,
+		Normalization:  u.Normalization,
+		MaxPathLength:  minLimit(u.MaxPathLength, s.MaxPathLength),
+		MaxNameLength:  minLimit(u.MaxNameLength, s.MaxNameLength),
+	}
+
+	if d.Normalization == billy.NoNormalization {
+		d.Normalization = s.Normalization
+	}
+
+	return d
+}
+
+// minLimit returns the lowest of two limits, where 0 means unlimited.
+func minLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+
+	return a
+}
+
 func (fs *Mount) getBasicAndPath(path string) (billy.Basic, string) {
 	path = cleanPath(path)
 	if !fs.isMountpoint(path) {
@@ -224,6 +348,46 @@ func cleanPath(path string) string {
 	return filepath.Clean(path)
 }
 
+// copyTree copies a file or a directory tree across filesystems. Unlike a
+// rename within the same filesystem, this is not atomic.
+func copyTree(src, dst billy.Filesystem, srcPath, dstPath string) error {
+	fi, err := src.Lstat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := src.Readlink(srcPath)
+		if err != nil {
+			return err
+		}
+
+		return dst.Symlink(target, dstPath)
+	}
+
+	if !fi.IsDir() {
+		return copyPath(src, dst, srcPath, dstPath)
+	}
+
+	if err := dst.MkdirAll(dstPath, fi.Mode().Perm()); err != nil {
+		return err
+	}
+
+	entries, err := src.ReadDir(srcPath)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		err := copyTree(src, dst, src.Join(srcPath, e.Name()), dst.Join(dstPath, e.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // copyPath copies a file across filesystems.
 func copyPath(src, dst billy.Basic, srcPath, dstPath string) error {
 	dstFile, err := dst.Create(dstPath)
@@ -269,3 +433,8 @@ func wrapFile(f billy.File, filename string) billy.File {
 func (f *file) Name() string {
 	return f.name
 }
+
+// Sync implements the Syncer interface.
+func (f *file) Sync() error {
+	return billy.Sync(f.File)
+}
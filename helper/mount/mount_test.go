@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
 	"gopkg.in/src-d/go-billy.v4/memfs"
 	"gopkg.in/src-d/go-billy.v4/test"
 	"gopkg.in/src-d/go-billy.v4/util"
@@ -168,7 +169,7 @@ func (s *MountSuite) TestRenameCross(c *C) {
 	c.Assert(err, IsNil)
 
 	_, err = underlying.Stat("file")
-	c.Assert(err, Equals, os.ErrNotExist)
+	c.Assert(os.IsNotExist(err), Equals, true)
 
 	_, err = source.Stat("file")
 	c.Assert(err, IsNil)
@@ -180,7 +181,92 @@ func (s *MountSuite) TestRenameCross(c *C) {
 	c.Assert(err, IsNil)
 
 	_, err = source.Stat("file")
-	c.Assert(err, Equals, os.ErrNotExist)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *MountSuite) TestRenameMountpoint(c *C) {
+	underlying := memfs.New()
+	source := memfs.New()
+
+	c.Assert(util.WriteFile(underlying, "dir/foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(source, "bar", nil, 0644), IsNil)
+
+	fs := New(underlying, "/mnt", source)
+	c.Assert(fs.Rename("mnt", "elsewhere"), Equals, os.ErrInvalid)
+	c.Assert(fs.Rename("dir", "mnt"), Equals, os.ErrInvalid)
+	c.Assert(fs.Rename("mnt", "mnt/qux"), Equals, os.ErrInvalid)
+
+	_, err := source.Stat("bar")
+	c.Assert(err, IsNil)
+
+	_, err = underlying.Stat("elsewhere")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = underlying.Stat("dir/foo")
+	c.Assert(err, IsNil)
+
+	_, err = source.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *MountSuite) TestRenameCrossDir(c *C) {
+	underlying := memfs.New()
+	source := memfs.New()
+
+	files := []string{"dir/foo", "dir/bar/qux", "dir/bar/baz/foo"}
+	for _, name := range files {
+		err := util.WriteFile(underlying, name, []byte("foo"), 0644)
+		c.Assert(err, IsNil)
+	}
+
+	c.Assert(underlying.Symlink("foo", "dir/link"), IsNil)
+
+	fs := New(underlying, "/foo", source)
+	err := fs.Rename("dir", "foo/dir")
+	c.Assert(err, IsNil)
+
+	for _, name := range files {
+		_, err = underlying.Stat(name)
+		c.Assert(os.IsNotExist(err), Equals, true)
+
+		fi, err := fs.Stat(filepath.Join("foo", name))
+		c.Assert(err, IsNil)
+		c.Assert(fi.Size(), Equals, int64(3))
+	}
+
+	target, err := source.Readlink("dir/link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "foo")
+
+	_, err = underlying.Stat("dir")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *MountSuite) TestRenameCrossReplace(c *C) {
+	underlying := memfs.New()
+	source := memfs.New()
+
+	c.Assert(util.WriteFile(underlying, "file", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(underlying, "dir/foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(source, "dir/bar", []byte("bar"), 0644), IsNil)
+	c.Assert(util.WriteFile(source, "file", []byte("bar"), 0644), IsNil)
+
+	fs := New(underlying, "/foo", source)
+
+	err := fs.Rename("dir", "foo/dir")
+	c.Assert(billy.IsNotEmpty(err), Equals, true)
+
+	err = fs.Rename("file", "foo/dir")
+	c.Assert(billy.IsDir(err), Equals, true)
+
+	err = fs.Rename("dir", "foo/file")
+	c.Assert(billy.IsNotDir(err), Equals, true)
+
+	_, err = source.Stat("dir/foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = underlying.Stat("dir/foo")
+	c.Assert(err, IsNil)
 }
 
 func (s *MountSuite) TestRemove(c *C) {
@@ -327,7 +413,7 @@ func (s *MountSuite) TestUnderlyingNotSupported(c *C) {
 	_, err := h.ReadDir("qux")
 	c.Assert(err, Equals, billy.ErrNotSupported)
 	_, err = h.Readlink("qux")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	c.Assert(err.(*os.PathError).Err, Equals, billy.ErrNotSupported)
 }
 
 func (s *MountSuite) TestSourceNotSupported(c *C) {
@@ -335,7 +421,7 @@ func (s *MountSuite) TestSourceNotSupported(c *C) {
 	_, err := h.ReadDir("foo")
 	c.Assert(err, Equals, billy.ErrNotSupported)
 	_, err = h.Readlink("foo")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	c.Assert(err.(*os.PathError).Err, Equals, billy.ErrNotSupported)
 }
 
 func (s *MountSuite) TestCapabilities(c *C) {
@@ -368,3 +454,104 @@ func testCapabilities(c *C, a, b billy.Basic) {
 
 	c.Assert(capabilities, Equals, unionCapabilities)
 }
+
+func (s *MountSuite) TestDescribe(c *C) {
+	a := &test.DescribedFs{Description: billy.Description{
+		CaseSensitive:  true,
+		CasePreserving: true,
+		MaxPathLength:  100,
+	}}
+
+	b := &test.DescribedFs{Description: billy.Description{
+		CaseSensitive:  false,
+		CasePreserving: true,
+		Normalization:  billy.NFD,
+		MaxPathLength:  200,
+		MaxNameLength:  10,
+	}}
+
+	c.Assert(New(a, "/foo", b).Describe(), Equals, billy.Description{
+		CaseSensitive:  false,
+		CasePreserving: true,
+		Normalization:  billy.NFD,
+		MaxPathLength:  100,
+		MaxNameLength:  10,
+	})
+}
+
+func (s *MountSuite) TestRemoveAll(c *C) {
+	underlying := memfs.New()
+	source := memfs.New()
+
+	c.Assert(util.WriteFile(underlying, "dir/foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(source, "bar", nil, 0644), IsNil)
+
+	fs := New(underlying, "/dir/mnt", source)
+	c.Assert(util.RemoveAll(fs, "dir"), IsNil)
+
+	_, err := underlying.Stat("dir")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = source.Stat("bar")
+	c.Assert(err, IsNil)
+}
+
+func (s *MountSuite) TestRemoveAllInMount(c *C) {
+	underlying := memfs.New()
+	source := memfs.New()
+
+	c.Assert(util.WriteFile(underlying, "foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(source, "bar/qux", nil, 0644), IsNil)
+
+	fs := New(underlying, "/mnt", source)
+	c.Assert(util.RemoveAll(fs, "mnt/bar"), IsNil)
+
+	_, err := source.Stat("bar")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = underlying.Stat("foo")
+	c.Assert(err, IsNil)
+}
+
+func (s *MountSuite) TestRemoveAllMountPoint(c *C) {
+	err := s.Helper.RemoveAll("foo")
+	c.Assert(err, Equals, os.ErrInvalid)
+}
+
+func (s *MountSuite) TestLink(c *C) {
+	underlying := memfs.New()
+	source := memfs.New()
+	c.Assert(util.WriteFile(underlying, "foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(source, "bar", nil, 0644), IsNil)
+
+	fs := New(underlying, "/mnt", source)
+	c.Assert(billy.Link(fs, "foo", "qux"), IsNil)
+	c.Assert(billy.Link(fs, "mnt/bar", "mnt/qux"), IsNil)
+
+	_, err := underlying.Stat("qux")
+	c.Assert(err, IsNil)
+
+	_, err = source.Stat("qux")
+	c.Assert(err, IsNil)
+}
+
+func (s *MountSuite) TestLinkCrossMount(c *C) {
+	err := s.Helper.Link("foo/bar", "qux")
+	c.Assert(err, NotNil)
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return chroot.New(New(fs, "/dir/empty", memfs.New()), "/")
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
+
+func (s *WrapperSuite) TestDir(c *C) {
+	c.Skip("the mountpoint can't be removed")
+}
+
+func (s *WrapperSuite) TestRemoveAll(c *C) {
+	c.Skip("the mountpoint can't be removed")
+}
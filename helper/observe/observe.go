@@ -0,0 +1,310 @@
+// Package observe provides a helper that reports every operation done on a
+// billy filesystem, and on its files, to an observer.
+package observe // import "gopkg.in/src-d/go-billy.v4/helper/observe"
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/helper/polyfill"
+)
+
+// Op is the name of an operation, as the name of the method of billy.Filesystem
+// or billy.File doing it.
+type Op string
+
+// The operations observed.
+const (
+	Create       Op = "Create"
+	Open         Op = "Open"
+	OpenFile     Op = "OpenFile"
+	Stat         Op = "Stat"
+	Lstat        Op = "Lstat"
+	Rename       Op = "Rename"
+	Remove       Op = "Remove"
+	TempFile     Op = "TempFile"
+	TempDir      Op = "TempDir"
+	ReadDir      Op = "ReadDir"
+	MkdirAll     Op = "MkdirAll"
+	Symlink      Op = "Symlink"
+	Readlink     Op = "Readlink"
+	FileRead     Op = "File.Read"
+	FileReadAt   Op = "File.ReadAt"
+	FileWrite    Op = "File.Write"
+	FileSeek     Op = "File.Seek"
+	FileTruncate Op = "File.Truncate"
+	FileSync     Op = "File.Sync"
+	FileLock     Op = "File.Lock"
+	FileUnlock   Op = "File.Unlock"
+	FileClose    Op = "File.Close"
+)
+
+// Event describes an operation once it's finished.
+type Event struct {
+	// Op is the operation.
+	Op Op
+	// Path is the path the operation was done on, or the name of the file
+	// for the operations of a file. For TempFile and TempDir it's the
+	// directory given.
+	Path string
+	// Target is the second path of the operations having one, the new path
+	// of Rename and the target of Symlink.
+	Target string
+	// Bytes is the number of bytes read or written.
+	Bytes int
+	// Start is the time when the operation started.
+	Start time.Time
+	// Duration is the time the operation took.
+	Duration time.Duration
+	// Err is the error returned by the operation, if any.
+	Err error
+}
+
+// Observer receives the events of the operations done on a filesystem. It's
+// called synchronously, once the operation finishes, so it must be safe for
+// concurrent use and should return quickly. The start time and the duration
+// of the events allow to record them as spans of a tracer after the fact.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc is an adapter to use an ordinary function as an Observer.
+type ObserverFunc func(e Event)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// Observe is a helper that reports every operation of the underlying
+// filesystem, and of the files opened through it, to an observer.
+type Observe struct {
+	underlying billy.Filesystem
+	o          Observer
+}
+
+// New creates a new filesystem wrapping up fs, reporting its operations to o.
+func New(fs billy.Basic, o Observer) billy.Filesystem {
+	return &Observe{underlying: polyfill.New(fs), o: o}
+}
+
+// observe reports an operation started at start.
+func (h *Observe) observe(op Op, path, target string, n int, start time.Time, err error) {
+	h.o.Observe(Event{
+		Op:       op,
+		Path:     path,
+		Target:   target,
+		Bytes:    n,
+		Start:    start,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+}
+
+func (h *Observe) Create(filename string) (billy.File, error) {
+	start := time.Now()
+	f, err := h.underlying.Create(filename)
+	h.observe(Create, filename, "", 0, start, err)
+	return h.wrapFile(f, err)
+}
+
+func (h *Observe) Open(filename string) (billy.File, error) {
+	start := time.Now()
+	f, err := h.underlying.Open(filename)
+	h.observe(Open, filename, "", 0, start, err)
+	return h.wrapFile(f, err)
+}
+
+func (h *Observe) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	start := time.Now()
+	f, err := h.underlying.OpenFile(filename, flag, perm)
+	h.observe(OpenFile, filename, "", 0, start, err)
+	return h.wrapFile(f, err)
+}
+
+// OpenFileOpt implements the OptionOpener interface.
+func (h *Observe) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	start := time.Now()
+	f, err := billy.OpenFileOpt(h.underlying, filename, flag, perm, opts...)
+	h.observe(OpenFile, filename, "", 0, start, err)
+	return h.wrapFile(f, err)
+}
+
+func (h *Observe) Stat(filename string) (os.FileInfo, error) {
+	start := time.Now()
+	fi, err := h.underlying.Stat(filename)
+	h.observe(Stat, filename, "", 0, start, err)
+	return fi, err
+}
+
+func (h *Observe) Lstat(filename string) (os.FileInfo, error) {
+	start := time.Now()
+	fi, err := h.underlying.Lstat(filename)
+	h.observe(Lstat, filename, "", 0, start, err)
+	return fi, err
+}
+
+func (h *Observe) Rename(from, to string) error {
+	start := time.Now()
+	err := h.underlying.Rename(from, to)
+	h.observe(Rename, from, to, 0, start, err)
+	return err
+}
+
+func (h *Observe) Remove(filename string) error {
+	start := time.Now()
+	err := h.underlying.Remove(filename)
+	h.observe(Remove, filename, "", 0, start, err)
+	return err
+}
+
+func (h *Observe) TempFile(dir, prefix string) (billy.File, error) {
+	start := time.Now()
+	f, err := h.underlying.TempFile(dir, prefix)
+	h.observe(TempFile, dir, "", 0, start, err)
+	return h.wrapFile(f, err)
+}
+
+func (h *Observe) TempDir(dir, prefix string) (string, error) {
+	start := time.Now()
+	name, err := h.underlying.TempDir(dir, prefix)
+	h.observe(TempDir, dir, "", 0, start, err)
+	return name, err
+}
+
+func (h *Observe) ReadDir(path string) ([]os.FileInfo, error) {
+	start := time.Now()
+	infos, err := h.underlying.ReadDir(path)
+	h.observe(ReadDir, path, "", 0, start, err)
+	return infos, err
+}
+
+func (h *Observe) MkdirAll(filename string, perm os.FileMode) error {
+	start := time.Now()
+	err := h.underlying.MkdirAll(filename, perm)
+	h.observe(MkdirAll, filename, "", 0, start, err)
+	return err
+}
+
+func (h *Observe) Symlink(target, link string) error {
+	start := time.Now()
+	err := h.underlying.Symlink(target, link)
+	h.observe(Symlink, link, target, 0, start, err)
+	return err
+}
+
+func (h *Observe) Readlink(link string) (string, error) {
+	start := time.Now()
+	target, err := h.underlying.Readlink(link)
+	h.observe(Readlink, link, "", 0, start, err)
+	return target, err
+}
+
+func (h *Observe) Join(elem ...string) string {
+	return h.underlying.Join(elem...)
+}
+
+func (h *Observe) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Observe) Root() string {
+	return h.underlying.Root()
+}
+
+// WithContext implements the Contexter interface, binding the underlying
+// filesystem to ctx.
+func (h *Observe) WithContext(ctx context.Context) billy.Filesystem {
+	return New(billy.WithContext(h.underlying, ctx), h.o)
+}
+
+// Capabilities implements the Capable interface.
+func (h *Observe) Capabilities() billy.Capability {
+	return billy.Capabilities(h.underlying)
+}
+
+// Describe implements the Describer interface.
+func (h *Observe) Describe() billy.Description {
+	return billy.Describe(h.underlying)
+}
+
+func (h *Observe) wrapFile(f billy.File, err error) (billy.File, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{File: f, h: h}, nil
+}
+
+type file struct {
+	billy.File
+	h *Observe
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := f.File.Read(p)
+	f.h.observe(FileRead, f.Name(), "", n, start, err)
+	return n, err
+}
+
+func (f *file) ReadAt(p []byte, off int64) (int, error) {
+	start := time.Now()
+	n, err := f.File.ReadAt(p, off)
+	f.h.observe(FileReadAt, f.Name(), "", n, start, err)
+	return n, err
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := f.File.Write(p)
+	f.h.observe(FileWrite, f.Name(), "", n, start, err)
+	return n, err
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	start := time.Now()
+	pos, err := f.File.Seek(offset, whence)
+	f.h.observe(FileSeek, f.Name(), "", 0, start, err)
+	return pos, err
+}
+
+func (f *file) Truncate(size int64) error {
+	start := time.Now()
+	err := f.File.Truncate(size)
+	f.h.observe(FileTruncate, f.Name(), "", 0, start, err)
+	return err
+}
+
+// Sync implements the Syncer interface.
+func (f *file) Sync() error {
+	start := time.Now()
+	err := billy.Sync(f.File)
+	f.h.observe(FileSync, f.Name(), "", 0, start, err)
+	return err
+}
+
+func (f *file) Lock() error {
+	start := time.Now()
+	err := f.File.Lock()
+	f.h.observe(FileLock, f.Name(), "", 0, start, err)
+	return err
+}
+
+func (f *file) Unlock() error {
+	start := time.Now()
+	err := f.File.Unlock()
+	f.h.observe(FileUnlock, f.Name(), "", 0, start, err)
+	return err
+}
+
+func (f *file) Close() error {
+	start := time.Now()
+	err := f.File.Close()
+	f.h.observe(FileClose, f.Name(), "", 0, start, err)
+	return err
+}
@@ -0,0 +1,119 @@
+package observe
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&ObserveSuite{})
+
+type ObserveSuite struct {
+	test.FilesystemSuite
+	events *recorder
+}
+
+func (s *ObserveSuite) SetUpTest(c *C) {
+	s.events = &recorder{}
+	s.FilesystemSuite = test.NewFilesystemSuite(New(memfs.New(), s.events))
+}
+
+// recorder records the events observed.
+type recorder struct {
+	m      sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Observe(e Event) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) reset() {
+	r.m.Lock()
+	r.events = nil
+	r.m.Unlock()
+}
+
+func (s *ObserveSuite) TestObserve(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(s.FS.Rename("foo", "bar"), IsNil)
+
+	f, err := s.FS.Open("bar")
+	c.Assert(err, IsNil)
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+	c.Assert(f.Close(), IsNil)
+
+	_, err = s.FS.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	ops := make([]string, len(s.events.events))
+	for i, e := range s.events.events {
+		ops[i] = string(e.Op)
+	}
+
+	c.Assert(ops, DeepEquals, []string{
+		"OpenFile", "File.Write", "File.Close",
+		"Rename",
+		"Open", "File.Read", "File.Read", "File.Close",
+		"Stat",
+	})
+
+	events := s.events.events
+	c.Assert(events[1].Path, Equals, "foo")
+	c.Assert(events[1].Bytes, Equals, 3)
+	c.Assert(events[3].Path, Equals, "foo")
+	c.Assert(events[3].Target, Equals, "bar")
+	c.Assert(events[5].Bytes, Equals, 3)
+	c.Assert(events[7].Err, IsNil)
+	c.Assert(os.IsNotExist(events[8].Err), Equals, true)
+}
+
+func (s *ObserveSuite) TestObserverFunc(c *C) {
+	var observed []Event
+	fs := New(memfs.New(), ObserverFunc(func(e Event) {
+		observed = append(observed, e)
+	}))
+
+	c.Assert(fs.MkdirAll("foo", 0755), IsNil)
+	c.Assert(observed, HasLen, 1)
+	c.Assert(observed[0].Op, Equals, MkdirAll)
+	c.Assert(observed[0].Path, Equals, "foo")
+	c.Assert(observed[0].Start.IsZero(), Equals, false)
+}
+
+func (s *ObserveSuite) TestChroot(c *C) {
+	fs, err := s.FS.Chroot("foo")
+	c.Assert(err, IsNil)
+	s.events.reset()
+
+	c.Assert(fs.MkdirAll("bar", 0755), IsNil)
+	c.Assert(s.events.events, HasLen, 1)
+	c.Assert(s.events.events[0].Path, Equals, s.FS.Join("/foo", "bar"))
+}
+
+func (s *ObserveSuite) TestForwardsCapabilities(c *C) {
+	c.Assert(billy.Capabilities(s.FS), Equals, billy.Capabilities(memfs.New()))
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs, ObserverFunc(func(Event) {}))
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
@@ -1,13 +1,20 @@
 package polyfill
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
 	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
 )
 
 // Polyfill is a helper that implements all missing method from billy.Filesystem.
+// The features that can be built on top of billy.Basic are emulated: TempFile
+// creates files with random names, RemoveAll removes recursively, and Lstat is
+// equivalent to Stat when there are no symbolic links. The rest of them fail
+// with billy.ErrNotSupported, wrapped in a *os.PathError or *os.LinkError for
+// Symlink and Readlink.
 type Polyfill struct {
 	billy.Basic
 	c capabilities
@@ -31,14 +38,96 @@ func New(fs billy.Basic) billy.Filesystem {
 	return h
 }
 
+// TempFile creates a temporary file, if the underlying filesystem doesn't
+// support it the file is created with a random name, as util.TempFile does.
 func (h *Polyfill) TempFile(dir, prefix string) (billy.File, error) {
 	if !h.c.tempfile {
-		return nil, billy.ErrNotSupported
+		return util.TempFile(h.Basic, dir, prefix)
 	}
 
 	return h.Basic.(billy.TempFile).TempFile(dir, prefix)
 }
 
+// TempDir creates a temporary directory, if the underlying filesystem doesn't
+// support it the directory is created with a random name, as util.TempDir
+// does.
+func (h *Polyfill) TempDir(dir, prefix string) (string, error) {
+	if h.c.tempfile {
+		return h.Basic.(billy.TempFile).TempDir(dir, prefix)
+	}
+
+	if !h.c.dir {
+		return "", billy.ErrNotSupported
+	}
+
+	return util.TempDir(h.Basic.(billy.Dir), dir, prefix)
+}
+
+// RemoveAll removes path and any children it contains, using the RemoveAll of
+// the underlying filesystem if any, or removing them one by one otherwise.
+func (h *Polyfill) RemoveAll(path string) error {
+	return util.RemoveAll(h.Basic, path)
+}
+
+// Link implements the Linker interface, returning a *os.LinkError with
+// billy.ErrNotSupported if the underlying filesystem doesn't implement it.
+func (h *Polyfill) Link(oldname, newname string) error {
+	return billy.Link(h.Basic, oldname, newname)
+}
+
+// GetXattr implements the Xattrer interface, returning a *os.PathError with
+// billy.ErrNotSupported if the underlying filesystem doesn't implement it.
+func (h *Polyfill) GetXattr(name, attr string) ([]byte, error) {
+	return billy.GetXattr(h.Basic, name, attr)
+}
+
+// SetXattr implements the Xattrer interface, returning a *os.PathError with
+// billy.ErrNotSupported if the underlying filesystem doesn't implement it.
+func (h *Polyfill) SetXattr(name, attr string, value []byte) error {
+	return billy.SetXattr(h.Basic, name, attr, value)
+}
+
+// ListXattr implements the Xattrer interface, returning a *os.PathError with
+// billy.ErrNotSupported if the underlying filesystem doesn't implement it.
+func (h *Polyfill) ListXattr(name string) ([]string, error) {
+	return billy.ListXattr(h.Basic, name)
+}
+
+// RemoveXattr implements the Xattrer interface, returning a *os.PathError
+// with billy.ErrNotSupported if the underlying filesystem doesn't implement
+// it.
+func (h *Polyfill) RemoveXattr(name, attr string) error {
+	return billy.RemoveXattr(h.Basic, name, attr)
+}
+
+// Watch implements the Watcher interface, returning billy.ErrNotSupported if
+// the underlying filesystem doesn't implement it.
+func (h *Polyfill) Watch(path string, recursive bool) (<-chan billy.Event, billy.CancelFunc, error) {
+	w, ok := h.Basic.(billy.Watcher)
+	if !ok {
+		return nil, nil, billy.ErrNotSupported
+	}
+
+	return w.Watch(path, recursive)
+}
+
+// WithContext implements the Contexter interface, returning the filesystem
+// itself, ignoring ctx, if the underlying filesystem doesn't implement it.
+func (h *Polyfill) WithContext(ctx context.Context) billy.Filesystem {
+	c, ok := h.Basic.(billy.Contexter)
+	if !ok {
+		return h
+	}
+
+	return c.WithContext(ctx)
+}
+
+// RemoveMany implements the BulkRemover interface, removing the paths one by
+// one if the underlying filesystem doesn't implement it.
+func (h *Polyfill) RemoveMany(paths []string) error {
+	return billy.RemoveMany(h.Basic, paths)
+}
+
 func (h *Polyfill) ReadDir(path string) ([]os.FileInfo, error) {
 	if !h.c.dir {
 		return nil, billy.ErrNotSupported
@@ -47,6 +136,16 @@ func (h *Polyfill) ReadDir(path string) ([]os.FileInfo, error) {
 	return h.Basic.(billy.Dir).ReadDir(path)
 }
 
+// ReadDirIter implements the DirIterator interface, iterating the entries
+// returned by ReadDir if the underlying filesystem doesn't implement it.
+func (h *Polyfill) ReadDirIter(path string) (billy.DirIter, error) {
+	if !h.c.dir {
+		return nil, billy.ErrNotSupported
+	}
+
+	return billy.ReadDirIter(h.Basic.(billy.Dir), path)
+}
+
 func (h *Polyfill) MkdirAll(filename string, perm os.FileMode) error {
 	if !h.c.dir {
 		return billy.ErrNotSupported
@@ -57,7 +156,7 @@ func (h *Polyfill) MkdirAll(filename string, perm os.FileMode) error {
 
 func (h *Polyfill) Symlink(target, link string) error {
 	if !h.c.symlink {
-		return billy.ErrNotSupported
+		return &os.LinkError{Op: "symlink", Old: target, New: link, Err: billy.ErrNotSupported}
 	}
 
 	return h.Basic.(billy.Symlink).Symlink(target, link)
@@ -65,15 +164,17 @@ func (h *Polyfill) Symlink(target, link string) error {
 
 func (h *Polyfill) Readlink(link string) (string, error) {
 	if !h.c.symlink {
-		return "", billy.ErrNotSupported
+		return "", &os.PathError{Op: "readlink", Path: link, Err: billy.ErrNotSupported}
 	}
 
 	return h.Basic.(billy.Symlink).Readlink(link)
 }
 
+// Lstat returns the FileInfo of path without following symbolic links, if the
+// underlying filesystem doesn't support them it is equivalent to Stat.
 func (h *Polyfill) Lstat(path string) (os.FileInfo, error) {
 	if !h.c.symlink {
-		return nil, billy.ErrNotSupported
+		return h.Basic.Stat(path)
 	}
 
 	return h.Basic.(billy.Symlink).Lstat(path)
@@ -103,3 +204,13 @@ func (h *Polyfill) Underlying() billy.Basic {
 func (h *Polyfill) Capabilities() billy.Capability {
 	return billy.Capabilities(h.Basic)
 }
+
+// OpenFileOpt implements the OptionOpener interface.
+func (h *Polyfill) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	return billy.OpenFileOpt(h.Basic, filename, flag, perm, opts...)
+}
+
+// Describe implements the Describer interface.
+func (h *Polyfill) Describe() billy.Description {
+	return billy.Describe(h.Basic)
+}
@@ -1,6 +1,7 @@
 package polyfill
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -24,10 +25,39 @@ func (s *PolyfillSuite) SetUpTest(c *C) {
 }
 
 func (s *PolyfillSuite) TestTempFile(c *C) {
-	_, err := s.Helper.TempFile("", "")
+	m := &test.BasicMock{}
+	f, err := New(m).TempFile("foo", "bar")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Matches, filepath.Join("foo", "bar")+"[0-9]+")
+
+	c.Assert(m.OpenFileArgs, HasLen, 1)
+	c.Assert(m.OpenFileArgs[0][1], Equals, os.O_RDWR|os.O_CREATE|os.O_EXCL)
+}
+
+func (s *PolyfillSuite) TestTempDir(c *C) {
+	m := &test.DirMock{}
+	name, err := New(m).TempDir("foo", "bar")
+	c.Assert(err, IsNil)
+	c.Assert(name, Matches, filepath.Join("foo", "bar")+"[0-9]+")
+
+	c.Assert(m.MkdirAllArgs, HasLen, 1)
+	c.Assert(m.MkdirAllArgs[0][0], Equals, name)
+}
+
+func (s *PolyfillSuite) TestTempDirNotSupported(c *C) {
+	_, err := s.Helper.TempDir("foo", "bar")
 	c.Assert(err, Equals, billy.ErrNotSupported)
 }
 
+func (s *PolyfillSuite) TestRemoveAll(c *C) {
+	m := &test.DirMock{}
+	err := New(m).(interface {
+		RemoveAll(string) error
+	}).RemoveAll("foo")
+	c.Assert(err, IsNil)
+	c.Assert(m.RemoveArgs, DeepEquals, []string{"foo"})
+}
+
 func (s *PolyfillSuite) TestReadDir(c *C) {
 	_, err := s.Helper.ReadDir("")
 	c.Assert(err, Equals, billy.ErrNotSupported)
@@ -39,18 +69,24 @@ func (s *PolyfillSuite) TestMkdirAll(c *C) {
 }
 
 func (s *PolyfillSuite) TestSymlink(c *C) {
-	err := s.Helper.Symlink("", "")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	err := s.Helper.Symlink("foo", "bar")
+	c.Assert(err, DeepEquals, &os.LinkError{
+		Op: "symlink", Old: "foo", New: "bar", Err: billy.ErrNotSupported,
+	})
 }
 
 func (s *PolyfillSuite) TestReadlink(c *C) {
-	_, err := s.Helper.Readlink("")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	_, err := s.Helper.Readlink("foo")
+	c.Assert(err, DeepEquals, &os.PathError{
+		Op: "readlink", Path: "foo", Err: billy.ErrNotSupported,
+	})
 }
 
 func (s *PolyfillSuite) TestLstat(c *C) {
-	_, err := s.Helper.Lstat("")
-	c.Assert(err, Equals, billy.ErrNotSupported)
+	m := &test.BasicMock{}
+	_, err := New(m).Lstat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(m.StatArgs, DeepEquals, []string{"foo"})
 }
 
 func (s *PolyfillSuite) TestChroot(c *C) {
@@ -76,3 +112,10 @@ func testCapabilities(c *C, basic billy.Basic) {
 
 	c.Assert(capabilities, Equals, baseCapabilities)
 }
+
+func (s *PolyfillSuite) TestLinkNotSupported(c *C) {
+	err := billy.Link(s.Helper, "foo", "bar")
+	c.Assert(err, DeepEquals, &os.LinkError{
+		Op: "link", Old: "foo", New: "bar", Err: billy.ErrNotSupported,
+	})
+}
@@ -0,0 +1,352 @@
+// Package rewrite provides a helper that transparently maps the paths of a
+// billy filesystem to a different layout.
+package rewrite // import "gopkg.in/src-d/go-billy.v4/helper/rewrite"
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+)
+
+// Rule is a path mapping between the layout exposed by Rewrite and the layout
+// of the underlying filesystem. Paths are always slash separated and relative
+// to the root.
+type Rule interface {
+	// Rewrite returns the underlying path of the given one, and true if the
+	// rule applies to it.
+	Rewrite(path string) (string, bool)
+	// Reverse returns the exposed path of the given underlying one, and true
+	// if the rule applies to it.
+	Reverse(path string) (string, bool)
+}
+
+type prefixRule struct {
+	from, to string
+}
+
+// Prefix returns a Rule replacing the leading path elements from with to.
+func Prefix(from, to string) Rule {
+	return &prefixRule{from: billy.SlashPath(from), to: billy.SlashPath(to)}
+}
+
+func (r *prefixRule) Rewrite(path string) (string, bool) {
+	return swapPrefix(path, r.from, r.to)
+}
+
+func (r *prefixRule) Reverse(path string) (string, bool) {
+	return swapPrefix(path, r.to, r.from)
+}
+
+func swapPrefix(path, from, to string) (string, bool) {
+	switch {
+	case from == ".":
+		return billy.SlashPath(to + "/" + path), true
+	case path == from:
+		return to, true
+	case strings.HasPrefix(path, from+"/"):
+		return billy.SlashPath(to + path[len(from):]), true
+	default:
+		return path, false
+	}
+}
+
+type regexpRule struct {
+	forward, reverse         *regexp.Regexp
+	forwardRepl, reverseRepl string
+}
+
+// Regexp returns a Rule replacing the paths matching forward with the
+// template forwardRepl, as regexp.ReplaceAllString does. The reverse
+// expression and template map the underlying paths back, if reverse is nil
+// the paths are reversed unchanged.
+func Regexp(forward *regexp.Regexp, forwardRepl string, reverse *regexp.Regexp, reverseRepl string) Rule {
+	return &regexpRule{
+		forward:     forward,
+		forwardRepl: forwardRepl,
+		reverse:     reverse,
+		reverseRepl: reverseRepl,
+	}
+}
+
+func (r *regexpRule) Rewrite(path string) (string, bool) {
+	return replace(r.forward, r.forwardRepl, path)
+}
+
+func (r *regexpRule) Reverse(path string) (string, bool) {
+	return replace(r.reverse, r.reverseRepl, path)
+}
+
+func replace(re *regexp.Regexp, repl, path string) (string, bool) {
+	if re == nil || !re.MatchString(path) {
+		return path, false
+	}
+
+	return billy.SlashPath(re.ReplaceAllString(path, repl)), true
+}
+
+// Rewrite is a helper that maps every path given to the underlying filesystem
+// using a list of rules, and maps back the names of the returned files. Only
+// the first rule applying to a path is used.
+//
+// The entries returned by ReadDir are the ones whose paths reverse into the
+// listed directory, including the directories mapped into it by a Prefix
+// rule. The files moved to another directory by a Regexp rule aren't listed,
+// since the rule can't be enumerated. The relative targets of the symlinks are
+// not rewritten.
+type Rewrite struct {
+	underlying billy.Filesystem
+	rules      []Rule
+}
+
+// New creates a new filesystem wrapping up 'fs' that rewrites the paths using
+// the given rules.
+func New(fs billy.Filesystem, rules ...Rule) billy.Filesystem {
+	return &Rewrite{underlying: fs, rules: rules}
+}
+
+func (h *Rewrite) Create(filename string) (billy.File, error) {
+	f, err := h.underlying.Create(h.rewrite(filename))
+	return h.wrapFile(f, err)
+}
+
+func (h *Rewrite) Open(filename string) (billy.File, error) {
+	f, err := h.underlying.Open(h.rewrite(filename))
+	return h.wrapFile(f, err)
+}
+
+func (h *Rewrite) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	f, err := h.underlying.OpenFile(h.rewrite(filename), flag, perm)
+	return h.wrapFile(f, err)
+}
+
+// OpenFileOpt implements the OptionOpener interface.
+func (h *Rewrite) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	f, err := billy.OpenFileOpt(h.underlying, h.rewrite(filename), flag, perm, opts...)
+	return h.wrapFile(f, err)
+}
+
+func (h *Rewrite) Stat(filename string) (os.FileInfo, error) {
+	fi, err := h.underlying.Stat(h.rewrite(filename))
+	return h.wrapFileInfo(filename, fi, err)
+}
+
+func (h *Rewrite) Rename(from, to string) error {
+	return h.underlying.Rename(h.rewrite(from), h.rewrite(to))
+}
+
+// Link implements the Linker interface.
+func (h *Rewrite) Link(oldname, newname string) error {
+	return billy.Link(h.underlying, h.rewrite(oldname), h.rewrite(newname))
+}
+
+// GetXattr implements the Xattrer interface.
+func (h *Rewrite) GetXattr(name, attr string) ([]byte, error) {
+	return billy.GetXattr(h.underlying, h.rewrite(name), attr)
+}
+
+// SetXattr implements the Xattrer interface.
+func (h *Rewrite) SetXattr(name, attr string, value []byte) error {
+	return billy.SetXattr(h.underlying, h.rewrite(name), attr, value)
+}
+
+// ListXattr implements the Xattrer interface.
+func (h *Rewrite) ListXattr(name string) ([]string, error) {
+	return billy.ListXattr(h.underlying, h.rewrite(name))
+}
+
+// RemoveXattr implements the Xattrer interface.
+func (h *Rewrite) RemoveXattr(name, attr string) error {
+	return billy.RemoveXattr(h.underlying, h.rewrite(name), attr)
+}
+
+func (h *Rewrite) Remove(filename string) error {
+	return h.underlying.Remove(h.rewrite(filename))
+}
+
+func (h *Rewrite) Join(elem ...string) string {
+	return h.underlying.Join(elem...)
+}
+
+func (h *Rewrite) TempFile(dir, prefix string) (billy.File, error) {
+	f, err := h.underlying.TempFile(h.rewrite(dir), prefix)
+	return h.wrapFile(f, err)
+}
+
+func (h *Rewrite) TempDir(dir, prefix string) (string, error) {
+	name, err := h.underlying.TempDir(h.rewrite(dir), prefix)
+	if err != nil {
+		return "", err
+	}
+
+	return h.reverse(name), nil
+}
+
+// ReadDir lists the entries of the underlying directory that reverse into
+// the given one, and the directories mapped into it by a Prefix rule.
+func (h *Rewrite) ReadDir(dirname string) ([]os.FileInfo, error) {
+	underlying := billy.SlashPath(h.rewrite(dirname))
+	l, err := h.underlying.ReadDir(filepath.FromSlash(underlying))
+	if err != nil {
+		return nil, err
+	}
+
+	dir := billy.SlashPath(dirname)
+	seen := make(map[string]bool, len(l))
+	entries := make([]os.FileInfo, 0, len(l))
+	for _, fi := range l {
+		exposed := billy.SlashPath(h.reverse(path.Join(underlying, fi.Name())))
+		name := path.Base(exposed)
+		if path.Dir(exposed) != dir || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		entries = append(entries, &fileInfo{FileInfo: fi, name: name})
+	}
+
+	for _, r := range h.rules {
+		p, ok := r.(*prefixRule)
+		if !ok || p.from == "." || path.Dir(p.from) != dir {
+			continue
+		}
+
+		name := path.Base(p.from)
+		if seen[name] || billy.SlashPath(h.rewrite(p.from)) != p.to {
+			continue
+		}
+
+		fi, err := h.underlying.Lstat(filepath.FromSlash(p.to))
+		if err != nil {
+			continue
+		}
+
+		seen[name] = true
+		entries = append(entries, &fileInfo{FileInfo: fi, name: name})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+func (h *Rewrite) MkdirAll(filename string, perm os.FileMode) error {
+	return h.underlying.MkdirAll(h.rewrite(filename), perm)
+}
+
+func (h *Rewrite) Lstat(filename string) (os.FileInfo, error) {
+	fi, err := h.underlying.Lstat(h.rewrite(filename))
+	return h.wrapFileInfo(filename, fi, err)
+}
+
+// Symlink creates a symlink at the rewritten link path, absolute targets are
+// rewritten too.
+func (h *Rewrite) Symlink(target, link string) error {
+	if billy.IsAbs(target) {
+		target = filepath.Join(string(filepath.Separator), h.rewrite(target))
+	}
+
+	return h.underlying.Symlink(target, h.rewrite(link))
+}
+
+func (h *Rewrite) Readlink(link string) (string, error) {
+	target, err := h.underlying.Readlink(h.rewrite(link))
+	if err != nil || !billy.IsAbs(target) {
+		return target, err
+	}
+
+	return filepath.Join(string(filepath.Separator), h.reverse(target)), nil
+}
+
+// Chroot returns a chroot of the rewritten filesystem, so the rules keep
+// applying to the full paths.
+func (h *Rewrite) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Rewrite) Root() string {
+	return h.underlying.Root()
+}
+
+// Capabilities implements the Capable interface.
+func (h *Rewrite) Capabilities() billy.Capability {
+	return billy.Capabilities(h.underlying)
+}
+
+// Describe implements the Describer interface.
+func (h *Rewrite) Describe() billy.Description {
+	return billy.Describe(h.underlying)
+}
+
+func (h *Rewrite) rewrite(path string) string {
+	p := billy.SlashPath(path)
+	for _, r := range h.rules {
+		if rewritten, ok := r.Rewrite(p); ok {
+			return filepath.FromSlash(rewritten)
+		}
+	}
+
+	return path
+}
+
+func (h *Rewrite) reverse(path string) string {
+	p := billy.SlashPath(path)
+	for _, r := range h.rules {
+		if reversed, ok := r.Reverse(p); ok {
+			return filepath.FromSlash(reversed)
+		}
+	}
+
+	return path
+}
+
+func (h *Rewrite) wrapFile(f billy.File, err error) (billy.File, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{File: f, name: h.reverse(f.Name())}, nil
+}
+
+func (h *Rewrite) wrapFileInfo(filename string, fi os.FileInfo, err error) (os.FileInfo, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	p := billy.SlashPath(filename)
+	if p == "." {
+		return fi, nil
+	}
+
+	return &fileInfo{FileInfo: fi, name: path.Base(p)}, nil
+}
+
+type fileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+type file struct {
+	billy.File
+	name string
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+// Sync implements the Syncer interface.
+func (f *file) Sync() error {
+	return billy.Sync(f.File)
+}
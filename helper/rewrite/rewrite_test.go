@@ -0,0 +1,229 @@
+package rewrite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&RewriteSuite{})
+var _ = Suite(&FilesystemSuite{})
+
+type FilesystemSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *FilesystemSuite) SetUpTest(c *C) {
+	fs := New(memfs.New(), Prefix("/", "root"))
+	s.FilesystemSuite = test.NewFilesystemSuite(fs)
+}
+
+type RewriteSuite struct {
+	Underlying billy.Filesystem
+}
+
+func (s *RewriteSuite) SetUpTest(c *C) {
+	s.Underlying = memfs.New()
+}
+
+func (s *RewriteSuite) TestPrefix(c *C) {
+	fs := New(s.Underlying, Prefix("vendor", "third_party/go"))
+
+	err := util.WriteFile(fs, "vendor/foo/bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	fi, err := s.Underlying.Stat("third_party/go/foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Size(), Equals, int64(3))
+
+	f, err := fs.Open("vendor/foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, filepath.Join("vendor", "foo", "bar"))
+	c.Assert(f.Close(), IsNil)
+
+	_, err = fs.Stat("vendorfoo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	l, err := fs.ReadDir("vendor")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "foo")
+}
+
+func (s *RewriteSuite) TestRegexp(c *C) {
+	fs := New(s.Underlying, Regexp(
+		regexp.MustCompile(`^objects/([0-9a-f]{2})([0-9a-f]+)$`), "objects/$1/$2",
+		regexp.MustCompile(`^objects/([0-9a-f]{2})/([0-9a-f]+)$`), "objects/$1$2",
+	))
+
+	f, err := fs.Create("objects/abcdef")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, filepath.Join("objects", "abcdef"))
+	c.Assert(f.Close(), IsNil)
+
+	_, err = s.Underlying.Stat("objects/ab/cdef")
+	c.Assert(err, IsNil)
+
+	_, err = fs.Stat("objects/abcdef")
+	c.Assert(err, IsNil)
+}
+
+func (s *RewriteSuite) TestFirstRuleApplies(c *C) {
+	fs := New(s.Underlying, Prefix("foo/bar", "qux"), Prefix("foo", "baz"))
+
+	c.Assert(util.WriteFile(fs, "foo/bar/1", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "foo/2", nil, 0644), IsNil)
+
+	_, err := s.Underlying.Stat("qux/1")
+	c.Assert(err, IsNil)
+
+	_, err = s.Underlying.Stat("baz/2")
+	c.Assert(err, IsNil)
+}
+
+func (s *RewriteSuite) TestRename(c *C) {
+	fs := New(s.Underlying, Prefix("foo", "bar"))
+
+	c.Assert(util.WriteFile(fs, "foo/1", nil, 0644), IsNil)
+	c.Assert(fs.Rename("foo/1", "qux"), IsNil)
+
+	_, err := s.Underlying.Stat("bar/1")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = s.Underlying.Stat("qux")
+	c.Assert(err, IsNil)
+}
+
+func (s *RewriteSuite) TestTempFile(c *C) {
+	fs := New(s.Underlying, Prefix("tmp", "var/tmp"))
+
+	f, err := fs.TempFile("tmp", "foo")
+	c.Assert(err, IsNil)
+	c.Assert(filepath.Dir(f.Name()), Equals, "tmp")
+	c.Assert(f.Close(), IsNil)
+
+	l, err := s.Underlying.ReadDir("var/tmp")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+}
+
+func (s *RewriteSuite) TestChroot(c *C) {
+	fs := New(s.Underlying, Prefix("foo/bar", "qux"))
+
+	chroot, err := fs.Chroot("foo")
+	c.Assert(err, IsNil)
+
+	err = util.WriteFile(chroot, "bar/baz", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.Underlying.Open("qux/baz")
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *RewriteSuite) TestCapabilities(c *C) {
+	fs := New(s.Underlying)
+	c.Assert(billy.Capabilities(fs), Equals, billy.Capabilities(s.Underlying))
+}
+
+func (s *RewriteSuite) TestSymlinkAbsolute(c *C) {
+	fs := New(s.Underlying, Prefix("foo", "bar"))
+
+	c.Assert(fs.Symlink("/foo/file", "foo/link"), IsNil)
+	c.Assert(fs.Symlink("/qux/file", "foo/other"), IsNil)
+
+	target, err := s.Underlying.Readlink("bar/link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, filepath.FromSlash("/bar/file"))
+
+	target, err = fs.Readlink("foo/link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, filepath.FromSlash("/foo/file"))
+
+	target, err = fs.Readlink("foo/other")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, filepath.FromSlash("/qux/file"))
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs)
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
+
+func (s *RewriteSuite) TestStatName(c *C) {
+	fs := New(s.Underlying, Regexp(
+		regexp.MustCompile(`^objects/([0-9a-f]{2})([0-9a-f]+)$`), "objects/$1/$2",
+		regexp.MustCompile(`^objects/([0-9a-f]{2})/([0-9a-f]+)$`), "objects/$1$2",
+	))
+
+	c.Assert(util.WriteFile(fs, "objects/abcdef", nil, 0644), IsNil)
+
+	fi, err := fs.Stat("objects/abcdef")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "abcdef")
+
+	fi, err = fs.Lstat("objects/abcdef")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "abcdef")
+}
+
+func (s *RewriteSuite) TestReadDirPrefix(c *C) {
+	fs := New(s.Underlying, Prefix("vendor", "third_party/go"))
+
+	c.Assert(util.WriteFile(fs, "vendor/foo", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "third_party/bar", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "qux", nil, 0644), IsNil)
+
+	l, err := fs.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(names(l), DeepEquals, []string{"qux", "third_party", "vendor"})
+	c.Assert(l[2].IsDir(), Equals, true)
+
+	l, err = fs.ReadDir("third_party")
+	c.Assert(err, IsNil)
+	c.Assert(names(l), DeepEquals, []string{"bar"})
+
+	fi, err := fs.Stat("vendor")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "vendor")
+}
+
+func (s *RewriteSuite) TestReadDirRegexp(c *C) {
+	fs := New(s.Underlying, Regexp(
+		regexp.MustCompile(`^a/b(/.*)?$`), "c$1",
+		regexp.MustCompile(`^c(/.*)?$`), "a/b$1",
+	))
+
+	c.Assert(util.WriteFile(fs, "a/b/foo", nil, 0644), IsNil)
+
+	l, err := fs.ReadDir("a/b")
+	c.Assert(err, IsNil)
+	c.Assert(names(l), DeepEquals, []string{"foo"})
+}
+
+func names(l []os.FileInfo) []string {
+	var names []string
+	for _, fi := range l {
+		names = append(names, fi.Name())
+	}
+
+	return names
+}
@@ -0,0 +1,250 @@
+// Package split provides a helper that sends the reads and the writes of a
+// billy filesystem to different backends.
+package split // import "gopkg.in/src-d/go-billy.v4/helper/split"
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+)
+
+const writeFlags = os.O_WRONLY | os.O_RDWR | os.O_CREATE | os.O_APPEND | os.O_TRUNC
+
+// Split is a helper that sends the reads to a filesystem, e.g. a fast replica,
+// and the writes to another one, e.g. the authoritative store, which is
+// expected to propagate them to the former.
+//
+// To provide read-after-write consistency, the paths modified through Split
+// are read from the write filesystem during a window of time, long enough for
+// the changes to be propagated. The directories containing them are listed
+// from the write filesystem too, and any path inside a renamed or removed
+// directory is read from it as well.
+type Split struct {
+	read, write billy.Filesystem
+	window      time.Duration
+
+	m       sync.Mutex
+	written map[string]time.Time
+	parents map[string]time.Time
+	pruned  time.Time
+}
+
+// New creates a new filesystem reading from read and writing to write. The
+// paths written are read from write during the given window, a window of 0
+// disables it, always reading from read.
+func New(read, write billy.Filesystem, window time.Duration) billy.Filesystem {
+	return &Split{
+		read:    read,
+		write:   write,
+		window:  window,
+		written: make(map[string]time.Time),
+		parents: make(map[string]time.Time),
+	}
+}
+
+func (h *Split) Create(filename string) (billy.File, error) {
+	defer h.touch(filename)
+	return h.write.Create(filename)
+}
+
+func (h *Split) Open(filename string) (billy.File, error) {
+	return h.reader(filename).Open(filename)
+}
+
+func (h *Split) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if flag&writeFlags == 0 {
+		return h.reader(filename).OpenFile(filename, flag, perm)
+	}
+
+	defer h.touch(filename)
+	return h.write.OpenFile(filename, flag, perm)
+}
+
+// OpenFileOpt implements the OptionOpener interface.
+func (h *Split) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	if flag&writeFlags == 0 {
+		return billy.OpenFileOpt(h.reader(filename), filename, flag, perm, opts...)
+	}
+
+	defer h.touch(filename)
+	return billy.OpenFileOpt(h.write, filename, flag, perm, opts...)
+}
+
+func (h *Split) Stat(filename string) (os.FileInfo, error) {
+	return h.reader(filename).Stat(filename)
+}
+
+func (h *Split) Lstat(filename string) (os.FileInfo, error) {
+	return h.reader(filename).Lstat(filename)
+}
+
+func (h *Split) ReadDir(path string) ([]os.FileInfo, error) {
+	return h.reader(path).ReadDir(path)
+}
+
+func (h *Split) Readlink(link string) (string, error) {
+	return h.reader(link).Readlink(link)
+}
+
+func (h *Split) Rename(from, to string) error {
+	defer h.touch(from, to)
+	return h.write.Rename(from, to)
+}
+
+func (h *Split) Remove(filename string) error {
+	defer h.touch(filename)
+	return h.write.Remove(filename)
+}
+
+func (h *Split) MkdirAll(filename string, perm os.FileMode) error {
+	defer h.touch(filename)
+	return h.write.MkdirAll(filename, perm)
+}
+
+func (h *Split) Symlink(target, link string) error {
+	defer h.touch(link)
+	return h.write.Symlink(target, link)
+}
+
+func (h *Split) TempFile(dir, prefix string) (billy.File, error) {
+	f, err := h.write.TempFile(dir, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	h.touch(f.Name())
+	return f, nil
+}
+
+func (h *Split) TempDir(dir, prefix string) (string, error) {
+	name, err := h.write.TempDir(dir, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	h.touch(name)
+	return name, nil
+}
+
+func (h *Split) Join(elem ...string) string {
+	return h.write.Join(elem...)
+}
+
+// Chroot returns a chroot of the split filesystem, so the window of the
+// written paths keeps applying.
+func (h *Split) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Split) Root() string {
+	return h.write.Root()
+}
+
+// Capabilities implements the Capable interface. It returns the capabilities
+// supported by both filesystems.
+func (h *Split) Capabilities() billy.Capability {
+	return billy.Capabilities(h.read) & billy.Capabilities(h.write)
+}
+
+// Describe implements the Describer interface.
+func (h *Split) Describe() billy.Description {
+	return billy.Describe(h.write)
+}
+
+// touch records the given paths as written, as well as the directories
+// containing them.
+func (h *Split) touch(paths ...string) {
+	if h.window <= 0 {
+		return
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	now := time.Now()
+	h.prune(now)
+	for _, p := range paths {
+		p = normalize(p)
+		h.written[p] = now
+		for p != "/" {
+			p = parent(p)
+			h.parents[p] = now
+		}
+	}
+}
+
+// prune removes the expired entries, at most once per window.
+func (h *Split) prune(now time.Time) {
+	if now.Sub(h.pruned) < h.window {
+		return
+	}
+
+	for _, m := range []map[string]time.Time{h.written, h.parents} {
+		for p, t := range m {
+			if now.Sub(t) >= h.window {
+				delete(m, p)
+			}
+		}
+	}
+
+	h.pruned = now
+}
+
+// reader returns the filesystem that must serve the reads of the given path.
+func (h *Split) reader(path string) billy.Filesystem {
+	if h.isRecent(path) {
+		return h.write
+	}
+
+	return h.read
+}
+
+func (h *Split) isRecent(path string) bool {
+	if h.window <= 0 {
+		return false
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	now := time.Now()
+	recent := func(m map[string]time.Time, p string) bool {
+		t, ok := m[p]
+		return ok && now.Sub(t) < h.window
+	}
+
+	p := normalize(path)
+	if recent(h.parents, p) {
+		return true
+	}
+
+	for {
+		if recent(h.written, p) {
+			return true
+		}
+
+		if p == "/" {
+			return false
+		}
+
+		p = parent(p)
+	}
+}
+
+func normalize(path string) string {
+	return filepath.ToSlash(filepath.Join(string(filepath.Separator), path))
+}
+
+func parent(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+
+	return path[:i]
+}
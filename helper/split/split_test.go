@@ -0,0 +1,109 @@
+package split
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/polyfill"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&SplitSuite{})
+
+type SplitSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *SplitSuite) SetUpTest(c *C) {
+	fs := memfs.New()
+	s.FilesystemSuite = test.NewFilesystemSuite(New(fs, fs, time.Hour))
+}
+
+func (s *SplitSuite) TestWriteGoesToWrite(c *C) {
+	read, write := memfs.New(), memfs.New()
+	fs := New(read, write, 0)
+
+	err := util.WriteFile(fs, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = write.Stat("foo")
+	c.Assert(err, IsNil)
+
+	_, err = read.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = fs.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *SplitSuite) TestReadAfterWrite(c *C) {
+	read, write := memfs.New(), memfs.New()
+	fs := New(read, write, time.Hour)
+
+	c.Assert(util.WriteFile(read, "bar", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "foo/qux", nil, 0644), IsNil)
+
+	_, err := fs.Stat("foo/qux")
+	c.Assert(err, IsNil)
+
+	_, err = fs.Stat("bar")
+	c.Assert(err, IsNil)
+
+	entries, err := fs.ReadDir("foo")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+
+	entries, err = fs.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Name(), Equals, "foo")
+}
+
+func (s *SplitSuite) TestReadAfterRenameDir(c *C) {
+	read, write := memfs.New(), memfs.New()
+	c.Assert(util.WriteFile(read, "foo/qux", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(write, "foo/qux", nil, 0644), IsNil)
+
+	fs := New(read, write, time.Hour)
+	c.Assert(fs.Rename("foo", "bar"), IsNil)
+
+	_, err := fs.Stat("foo/qux")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = fs.Stat("bar/qux")
+	c.Assert(err, IsNil)
+}
+
+func (s *SplitSuite) TestWindowExpires(c *C) {
+	read, write := memfs.New(), memfs.New()
+	fs := New(read, write, 10*time.Millisecond)
+
+	c.Assert(util.WriteFile(fs, "foo", nil, 0644), IsNil)
+	_, err := fs.Stat("foo")
+	c.Assert(err, IsNil)
+
+	time.Sleep(20 * time.Millisecond)
+	_, err = fs.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *SplitSuite) TestCapabilities(c *C) {
+	fs := New(polyfill.New(&test.OnlyReadCapFs{}), memfs.New(), 0)
+	c.Assert(billy.CapabilityCheck(fs, billy.WriteCapability), Equals, false)
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs, fs, time.Minute)
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
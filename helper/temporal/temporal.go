@@ -28,3 +28,21 @@ func (h *Temporal) TempFile(dir, prefix string) (billy.File, error) {
 
 	return util.TempFile(h.Filesystem, dir, prefix)
 }
+
+func (h *Temporal) TempDir(dir, prefix string) (string, error) {
+	if dir == "" {
+		dir = h.defaultDir
+	}
+
+	return h.Filesystem.TempDir(dir, prefix)
+}
+
+// Capabilities implements the Capable interface.
+func (h *Temporal) Capabilities() billy.Capability {
+	return billy.Capabilities(h.Filesystem)
+}
+
+// Describe implements the Describer interface.
+func (h *Temporal) Describe() billy.Description {
+	return billy.Describe(h.Filesystem)
+}
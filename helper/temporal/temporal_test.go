@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/memfs"
 	"gopkg.in/src-d/go-billy.v4/test"
 
@@ -31,3 +32,11 @@ func (s *TemporalSuite) TestTempFileDefaultPath(c *C) {
 
 	c.Assert(strings.HasPrefix(f.Name(), fs.Join("foo", "bar")), Equals, true)
 }
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs, "dir")
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
@@ -0,0 +1,104 @@
+// Package timeskew provides a helper to alter the modification times reported
+// by a billy filesystem.
+package timeskew // import "gopkg.in/src-d/go-billy.v4/helper/timeskew"
+
+import (
+	"os"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// Skew is a helper that shifts and quantizes the modification times reported
+// by any filesystem. It allows to test mtime comparison logic against clock
+// skew between machines, timestamps in the future or the coarse resolution of
+// some filesystems, such as the 2 seconds of FAT.
+type Skew struct {
+	billy.Filesystem
+	offset     time.Duration
+	resolution time.Duration
+}
+
+// New creates a new filesystem wrapping up 'fs' that adds the given offset to
+// every modification time and then truncates it to a multiple of resolution.
+// A resolution of zero keeps the times unquantized.
+func New(fs billy.Filesystem, offset, resolution time.Duration) billy.Filesystem {
+	return &Skew{
+		Filesystem: fs,
+		offset:     offset,
+		resolution: resolution,
+	}
+}
+
+// OpenFileOpt implements the OptionOpener interface.
+func (h *Skew) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	return billy.OpenFileOpt(h.Filesystem, filename, flag, perm, opts...)
+}
+
+func (h *Skew) Stat(filename string) (os.FileInfo, error) {
+	fi, err := h.Filesystem.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.skew(fi), nil
+}
+
+func (h *Skew) Lstat(filename string) (os.FileInfo, error) {
+	fi, err := h.Filesystem.Lstat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.skew(fi), nil
+}
+
+func (h *Skew) ReadDir(path string) ([]os.FileInfo, error) {
+	l, err := h.Filesystem.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, fi := range l {
+		l[i] = h.skew(fi)
+	}
+
+	return l, nil
+}
+
+func (h *Skew) Chroot(path string) (billy.Filesystem, error) {
+	fs, err := h.Filesystem.Chroot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(fs, h.offset, h.resolution), nil
+}
+
+// Capabilities implements the Capable interface.
+func (h *Skew) Capabilities() billy.Capability {
+	return billy.Capabilities(h.Filesystem)
+}
+
+// Describe implements the Describer interface.
+func (h *Skew) Describe() billy.Description {
+	return billy.Describe(h.Filesystem)
+}
+
+func (h *Skew) skew(fi os.FileInfo) os.FileInfo {
+	t := fi.ModTime().Add(h.offset)
+	if h.resolution > 0 {
+		t = t.Truncate(h.resolution)
+	}
+
+	return &fileInfo{FileInfo: fi, modTime: t}
+}
+
+type fileInfo struct {
+	os.FileInfo
+	modTime time.Time
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.modTime
+}
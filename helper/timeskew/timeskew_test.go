@@ -0,0 +1,89 @@
+package timeskew
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&SkewSuite{})
+
+type SkewSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *SkewSuite) SetUpTest(c *C) {
+	fs := New(memfs.New(), time.Hour, 2*time.Second)
+	s.FilesystemSuite = test.NewFilesystemSuite(fs)
+}
+
+func (s *SkewSuite) TestStatOffset(c *C) {
+	fs := New(memfs.New(), 24*time.Hour, 0)
+	err := util.WriteFile(fs, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	fi, err := fs.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.ModTime().After(time.Now().Add(23*time.Hour)), Equals, true)
+}
+
+func (s *SkewSuite) TestStatResolution(c *C) {
+	fs := New(memfs.New(), 0, 2*time.Second)
+	err := util.WriteFile(fs, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	fi, err := fs.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.ModTime().Equal(fi.ModTime().Truncate(2*time.Second)), Equals, true)
+
+	fi, err = fs.Lstat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.ModTime().Equal(fi.ModTime().Truncate(2*time.Second)), Equals, true)
+}
+
+func (s *SkewSuite) TestReadDir(c *C) {
+	fs := New(memfs.New(), -time.Hour, time.Minute)
+	err := util.WriteFile(fs, "foo/bar", nil, 0644)
+	c.Assert(err, IsNil)
+
+	l, err := fs.ReadDir("foo")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "bar")
+	c.Assert(l[0].ModTime().Before(time.Now().Add(-59*time.Minute)), Equals, true)
+	c.Assert(l[0].ModTime().Second(), Equals, 0)
+}
+
+func (s *SkewSuite) TestChroot(c *C) {
+	fs := New(memfs.New(), 0, time.Minute)
+	qux, err := fs.Chroot("qux")
+	c.Assert(err, IsNil)
+
+	err = util.WriteFile(qux, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	fi, err := qux.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.ModTime().Second(), Equals, 0)
+}
+
+func (s *SkewSuite) TestCapabilities(c *C) {
+	fs := memfs.New()
+	c.Assert(billy.Capabilities(New(fs, 0, 0)), Equals, billy.Capabilities(fs))
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs, time.Hour, time.Second)
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
@@ -0,0 +1,254 @@
+// Package verify provides a read-only helper that checks the content of the
+// files read from a billy filesystem against their expected hashes.
+package verify // import "gopkg.in/src-d/go-billy.v4/helper/verify"
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+)
+
+// ErrCorrupted is returned when the content read from a file doesn't match its
+// expected hash.
+var ErrCorrupted = errors.New("file content doesn't match the expected hash")
+
+// Manifest holds the expected hashes of the content of the files, by path.
+type Manifest map[string][]byte
+
+// Verify is a read-only helper that verifies the content of the files listed
+// in a manifest while they are read. The hash is computed as the content is
+// read sequentially, and checked when the end of the file is reached, so the
+// content read before is not verified yet. Any other access, with ReadAt or
+// seeking, verifies the whole file first. Once a mismatch is found every read
+// of the file fails with ErrCorrupted.
+//
+// The files not listed in the manifest are read without any verification.
+type Verify struct {
+	underlying billy.Filesystem
+	manifest   Manifest
+	hash       func() hash.Hash
+}
+
+// New creates a new read-only filesystem wrapping up fs, verifying the files
+// listed in the manifest with the hashes returned by h. The paths of the
+// manifest are relative to the root of fs.
+func New(fs billy.Filesystem, manifest Manifest, h func() hash.Hash) billy.Filesystem {
+	m := make(Manifest, len(manifest))
+	for path, sum := range manifest {
+		m[normalize(path)] = sum
+	}
+
+	return &Verify{underlying: fs, manifest: m, hash: h}
+}
+
+func (h *Verify) Create(filename string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (h *Verify) Open(filename string) (billy.File, error) {
+	return h.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (h *Verify) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	return h.OpenFileOpt(filename, flag, perm)
+}
+
+// OpenFileOpt implements the OptionOpener interface. The hashes are checked
+// against the content as returned by the filters of the options, if any.
+func (h *Verify) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+		return nil, billy.ErrReadOnly
+	}
+
+	f, err := billy.OpenFileOpt(h.underlying, filename, flag, perm, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, ok := h.manifest[normalize(filename)]
+	if !ok {
+		return &file{File: f, verified: true}, nil
+	}
+
+	return &file{File: f, expected: expected, hash: h.hash(), newHash: h.hash}, nil
+}
+
+func (h *Verify) Stat(filename string) (os.FileInfo, error) {
+	return h.underlying.Stat(filename)
+}
+
+func (h *Verify) Lstat(filename string) (os.FileInfo, error) {
+	return h.underlying.Lstat(filename)
+}
+
+func (h *Verify) ReadDir(path string) ([]os.FileInfo, error) {
+	return h.underlying.ReadDir(path)
+}
+
+func (h *Verify) Readlink(link string) (string, error) {
+	return h.underlying.Readlink(link)
+}
+
+func (h *Verify) Rename(from, to string) error {
+	return billy.ErrReadOnly
+}
+
+func (h *Verify) Remove(filename string) error {
+	return billy.ErrReadOnly
+}
+
+func (h *Verify) MkdirAll(filename string, perm os.FileMode) error {
+	return billy.ErrReadOnly
+}
+
+func (h *Verify) Symlink(target, link string) error {
+	return billy.ErrReadOnly
+}
+
+func (h *Verify) TempFile(dir, prefix string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (h *Verify) TempDir(dir, prefix string) (string, error) {
+	return "", billy.ErrReadOnly
+}
+
+func (h *Verify) Join(elem ...string) string {
+	return h.underlying.Join(elem...)
+}
+
+// Chroot returns a chroot of the verifying filesystem, using the same
+// manifest, relative to the root of the underlying filesystem.
+func (h *Verify) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(h, h.Join(string(filepath.Separator), path)), nil
+}
+
+func (h *Verify) Root() string {
+	return h.underlying.Root()
+}
+
+// Capabilities implements the Capable interface.
+func (h *Verify) Capabilities() billy.Capability {
+	return billy.Capabilities(h.underlying) &^ (billy.WriteCapability |
+		billy.ReadAndWriteCapability | billy.TruncateCapability |
+		billy.CreateExclusiveCapability)
+}
+
+// Describe implements the Describer interface.
+func (h *Verify) Describe() billy.Description {
+	return billy.Describe(h.underlying)
+}
+
+func normalize(path string) string {
+	return filepath.ToSlash(filepath.Join(string(filepath.Separator), path))
+}
+
+// file computes the hash of the content while it is read sequentially.
+type file struct {
+	billy.File
+	expected []byte
+	hash     hash.Hash
+	newHash  func() hash.Hash
+	offset   int64
+	verified bool
+	err      error
+}
+
+func (f *file) Read(b []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	n, err := f.File.Read(b)
+	if f.verified {
+		return n, err
+	}
+
+	f.hash.Write(b[:n])
+	f.offset += int64(n)
+	if err == io.EOF {
+		if err := f.check(f.hash); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if err := f.verify(); err != nil {
+		return 0, err
+	}
+
+	return f.File.ReadAt(b, off)
+}
+
+// Seek sets the offset of the next Read, verifying the whole file first unless
+// the offset is the current one.
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	current := (whence == io.SeekCurrent && offset == 0) ||
+		(whence == io.SeekStart && offset == f.offset)
+
+	if !current {
+		if err := f.verify(); err != nil {
+			return 0, err
+		}
+	}
+
+	return f.File.Seek(offset, whence)
+}
+
+// verify reads the whole file, with ReadAt, checking its hash, unless it was
+// already verified.
+func (f *file) verify() error {
+	if f.err != nil || f.verified {
+		return f.err
+	}
+
+	h := f.newHash()
+	buf := make([]byte, 32*1024)
+	for off := int64(0); ; {
+		n, err := f.File.ReadAt(buf, off)
+		h.Write(buf[:n])
+		off += int64(n)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return f.check(h)
+}
+
+func (f *file) check(h hash.Hash) error {
+	if !bytes.Equal(h.Sum(nil), f.expected) {
+		f.err = ErrCorrupted
+		return f.err
+	}
+
+	f.verified = true
+	return nil
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	return 0, billy.ErrReadOnly
+}
+
+// Sync implements the Syncer interface.
+func (f *file) Sync() error {
+	return billy.Sync(f.File)
+}
+
+func (f *file) Truncate(size int64) error {
+	return billy.ErrReadOnly
+}
@@ -0,0 +1,158 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&VerifySuite{})
+
+type VerifySuite struct {
+	Underlying billy.Filesystem
+	Helper     billy.Filesystem
+}
+
+func sum(content string) []byte {
+	s := sha256.Sum256([]byte(content))
+	return s[:]
+}
+
+func (s *VerifySuite) SetUpTest(c *C) {
+	s.Underlying = memfs.New()
+	c.Assert(util.WriteFile(s.Underlying, "foo/bar", []byte("bar"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.Underlying, "foo/qux", []byte("qux"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.Underlying, "unlisted", []byte("foo"), 0644), IsNil)
+
+	s.Helper = New(s.Underlying, Manifest{
+		"foo/bar":  sum("bar"),
+		"/foo/qux": sum("corrupted"),
+	}, sha256.New)
+}
+
+func (s *VerifySuite) read(c *C, filename string) (string, error) {
+	f, err := s.Helper.Open(filename)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	return string(content), err
+}
+
+func (s *VerifySuite) TestRead(c *C) {
+	content, err := s.read(c, "foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(content, Equals, "bar")
+
+	content, err = s.read(c, "unlisted")
+	c.Assert(err, IsNil)
+	c.Assert(content, Equals, "foo")
+}
+
+func (s *VerifySuite) TestReadCorrupted(c *C) {
+	_, err := s.read(c, "foo/qux")
+	c.Assert(err, Equals, ErrCorrupted)
+
+	f, err := s.Helper.Open("foo/qux")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = ioutil.ReadAll(f)
+	c.Assert(err, Equals, ErrCorrupted)
+
+	_, err = f.Read(make([]byte, 1))
+	c.Assert(err, Equals, ErrCorrupted)
+}
+
+func (s *VerifySuite) TestReadAtCorrupted(c *C) {
+	f, err := s.Helper.Open("foo/qux")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.ReadAt(make([]byte, 1), 1)
+	c.Assert(err, Equals, ErrCorrupted)
+}
+
+func (s *VerifySuite) TestSeek(c *C) {
+	f, err := s.Helper.Open("foo/bar")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	b := make([]byte, 1)
+	_, err = f.Read(b)
+	c.Assert(err, IsNil)
+
+	pos, err := f.Seek(0, io.SeekCurrent)
+	c.Assert(err, IsNil)
+	c.Assert(pos, Equals, int64(1))
+
+	_, err = f.Seek(2, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "r")
+}
+
+func (s *VerifySuite) TestSeekCorrupted(c *C) {
+	f, err := s.Helper.Open("foo/qux")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.Seek(1, io.SeekStart)
+	c.Assert(err, Equals, ErrCorrupted)
+}
+
+func (s *VerifySuite) TestChroot(c *C) {
+	fs, err := s.Helper.Chroot("foo")
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("qux")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = ioutil.ReadAll(f)
+	c.Assert(err, Equals, ErrCorrupted)
+}
+
+func (s *VerifySuite) TestReadOnly(c *C) {
+	_, err := s.Helper.Create("foo")
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	_, err = s.Helper.OpenFile("foo/bar", os.O_RDWR, 0)
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	c.Assert(s.Helper.Remove("foo/bar"), Equals, billy.ErrReadOnly)
+	c.Assert(s.Helper.Rename("foo/bar", "bar"), Equals, billy.ErrReadOnly)
+
+	f, err := s.Helper.Open("foo/bar")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.Write([]byte("foo"))
+	c.Assert(err, Equals, billy.ErrReadOnly)
+}
+
+func (s *VerifySuite) TestCapabilities(c *C) {
+	c.Assert(billy.Capabilities(s.Helper), Equals, billy.ReadCapability|
+		billy.SeekCapability|billy.SymlinkCapability|billy.PermissionCapability)
+}
+
+var _ = Suite(&WrapperSuite{test.NewWrapperSuite(memfs.New, func(fs billy.Filesystem) billy.Filesystem {
+	return New(fs, Manifest{"foo": sum("foo"), "dir/bar": sum("bar")}, sha256.New)
+})})
+
+type WrapperSuite struct {
+	test.WrapperSuite
+}
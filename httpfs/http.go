@@ -0,0 +1,594 @@
+// Package httpfs provides a read-only billy filesystem over a static web
+// server, listing the directories using its directory index or a JSON
+// manifest.
+package httpfs // import "gopkg.in/src-d/go-billy.v4/httpfs"
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/transport"
+)
+
+const (
+	defaultDirectoryMode = os.ModeDir | 0555
+	defaultFileMode      = 0444
+)
+
+// HTTP is a read-only filesystem based on a static web server.
+type HTTP struct {
+	base   *url.URL
+	client *http.Client
+	retry  transport.RetryPolicy
+}
+
+// New returns a new HTTP filesystem rooted at the given base URL. If client is
+// nil, http.DefaultClient is used.
+//
+// Directories are listed requesting its URL with a trailing slash, the
+// response can be a JSON manifest, served with application/json content type,
+// or an HTML page, as the index generated by most web servers, from where the
+// links to the entries are extracted.
+//
+// The content of the files is read on demand with range requests, so seeking
+// doesn't transfer the skipped content. If a response is interrupted while it
+// is read, or the request fails to connect, the request is retried from the
+// current offset following transport.DefaultRetryPolicy, resuming the
+// download. The servers ignoring the range requests are supported, discarding
+// the content before the offset.
+func New(baseURL string, client *http.Client) (billy.Filesystem, error) {
+	return newHTTP(baseURL, client, transport.DefaultRetryPolicy)
+}
+
+// NewWithConfig returns a new HTTP filesystem rooted at the given base URL,
+// sending the requests with the TLS, credentials, signer and pool of the given
+// config, and resuming the interrupted reads with its retry policy.
+func NewWithConfig(baseURL string, c *transport.Config) (billy.Filesystem, error) {
+	return newHTTP(baseURL, c.Client(), c.RetryPolicy())
+}
+
+func newHTTP(baseURL string, client *http.Client, retry transport.RetryPolicy) (billy.Filesystem, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	fs := &HTTP{base: base, client: client, retry: retry}
+	return chroot.New(fs, string(filepath.Separator)), nil
+}
+
+func (fs *HTTP) Create(filename string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *HTTP) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *HTTP) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+		return nil, billy.ErrReadOnly
+	}
+
+	res, err := fs.do("HEAD", filename, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body.Close()
+	if isDirResponse(res) {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: billy.ErrIsDir}
+	}
+
+	return &file{fs: fs, name: filename, size: res.ContentLength}, nil
+}
+
+func (fs *HTTP) Stat(filename string) (os.FileInfo, error) {
+	res, err := fs.do("HEAD", filename, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body.Close()
+	if isDirResponse(res) {
+		return newDirInfo(filename), nil
+	}
+
+	return newFileInfo(filename, res), nil
+}
+
+func (fs *HTTP) Lstat(filename string) (os.FileInfo, error) {
+	return fs.Stat(filename)
+}
+
+func (fs *HTTP) ReadDir(path string) ([]os.FileInfo, error) {
+	res, err := fs.do("GET", path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	content, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mediatype, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediatype == "application/json" {
+		return parseManifest(content)
+	}
+
+	var entries []os.FileInfo
+	for _, name := range parseIndex(content) {
+		fullpath := fs.Join(path, strings.TrimSuffix(name, "/"))
+		if strings.HasSuffix(name, "/") {
+			entries = append(entries, newDirInfo(fullpath))
+			continue
+		}
+
+		fi, err := fs.Stat(fullpath)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, fi)
+	}
+
+	return entries, nil
+}
+
+func (fs *HTTP) Rename(from, to string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *HTTP) Remove(filename string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *HTTP) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (fs *HTTP) TempFile(dir, prefix string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *HTTP) TempDir(dir, prefix string) (string, error) {
+	return "", billy.ErrReadOnly
+}
+
+func (fs *HTTP) MkdirAll(filename string, perm os.FileMode) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *HTTP) Symlink(target, link string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *HTTP) Readlink(link string) (string, error) {
+	return "", billy.ErrNotSupported
+}
+
+// Capabilities implements the Capable interface.
+func (fs *HTTP) Capabilities() billy.Capability {
+	return billy.ReadCapability | billy.SeekCapability
+}
+
+// do performs a request of the given method to the URL of filename, returning
+// os.ErrNotExist on 404 and an error on any other non-successful status.
+func (fs *HTTP) do(method, filename string, dir bool) (*http.Response, error) {
+	req, err := http.NewRequest(method, fs.url(filename, dir), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.send(req, filename)
+}
+
+// get requests the content of filename from offset, until the end if length
+// is negative. The response is 206 if the server supports range requests, and
+// 200 with the whole content otherwise. It returns io.EOF if offset is past
+// the end of the content.
+func (fs *HTTP) get(filename string, offset, length int64) (*http.Response, error) {
+	req, err := http.NewRequest("GET", fs.url(filename, false), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if length < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+
+	return fs.send(req, filename)
+}
+
+func (fs *HTTP) send(req *http.Request, filename string) (*http.Response, error) {
+	res, err := fs.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return res, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		res.Body.Close()
+		return nil, io.EOF
+	}
+
+	res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, &os.PathError{Op: strings.ToLower(req.Method), Path: filename, Err: os.ErrNotExist}
+	}
+
+	return nil, &os.PathError{
+		Op:   strings.ToLower(req.Method),
+		Path: filename,
+		Err:  fmt.Errorf("unexpected status: %s", res.Status),
+	}
+}
+
+func (fs *HTTP) url(filename string, dir bool) string {
+	p := path.Join(fs.base.Path, filepath.ToSlash(filename))
+	if dir || p == "" {
+		p += "/"
+	}
+
+	u := *fs.base
+	u.Path = p
+	u.RawPath = ""
+	return u.String()
+}
+
+// isDirResponse returns true if the response URL has a trailing slash, since
+// web servers redirect the requests of directories to them.
+func isDirResponse(res *http.Response) bool {
+	if res.Request == nil || res.Request.URL == nil {
+		return false
+	}
+
+	return strings.HasSuffix(res.Request.URL.Path, "/")
+}
+
+var hrefRegexp = regexp.MustCompile(`(?i)<a\s[^>]*href\s*=\s*["']([^"']+)["']`)
+
+// parseIndex returns the names of the entries linked from an HTML directory
+// index, directories keep the trailing slash. Links to other hosts, parents,
+// queries or nested paths are ignored.
+func parseIndex(content []byte) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range hrefRegexp.FindAllSubmatch(content, -1) {
+		u, err := url.Parse(html.UnescapeString(string(m[1])))
+		if err != nil || u.IsAbs() || u.Host != "" || u.Path == "" {
+			continue
+		}
+
+		name := u.Path
+		trimmed := strings.TrimSuffix(name, "/")
+		if trimmed == "" || trimmed == "." || trimmed == ".." ||
+			strings.Contains(trimmed, "/") || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+type manifestEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Dir     bool      `json:"dir"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// parseManifest decodes a JSON manifest, being a list of objects with name,
+// size, dir and mtime keys.
+func parseManifest(content []byte) ([]os.FileInfo, error) {
+	var manifest []manifestEntry
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+
+	var entries []os.FileInfo
+	for _, e := range manifest {
+		fi := &fileInfo{
+			name:    e.Name,
+			size:    e.Size,
+			mode:    defaultFileMode,
+			modTime: e.ModTime,
+		}
+
+		if e.Dir {
+			fi.mode = defaultDirectoryMode
+		}
+
+		entries = append(entries, fi)
+	}
+
+	return entries, nil
+}
+
+// file reads the content on demand, keeping the response of the last request
+// open while it's read sequentially.
+type file struct {
+	fs       *HTTP
+	name     string
+	size     int64
+	position int64
+	body     io.ReadCloser
+	isClosed bool
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+// Read reads from the response of the current request, doing a new one if the
+// position changed. If the response is interrupted, or the request fails to
+// connect, it's retried from the current position following the retry policy.
+func (f *file) Read(b []byte) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	var err error
+	for retry := 0; ; retry++ {
+		if f.body == nil {
+			if f.body, err = f.open(f.position, -1); err != nil {
+				if !isTransient(err) || !f.fs.retry.Wait(retry) {
+					return 0, err
+				}
+
+				continue
+			}
+		}
+
+		var n int
+		n, err = f.body.Read(b)
+		f.position += int64(n)
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		// the response was interrupted, the next request resumes it.
+		f.body.Close()
+		f.body = nil
+		if n > 0 {
+			return n, nil
+		}
+
+		if !f.fs.retry.Wait(retry) {
+			return 0, err
+		}
+	}
+}
+
+// ReadAt reads with a range request, resuming it from the last offset read if
+// it's interrupted, following the retry policy.
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	var read, retry int
+	for read < len(b) {
+		body, err := f.open(off+int64(read), int64(len(b)-read))
+		if err == nil {
+			var n int
+			n, err = io.ReadFull(body, b[read:])
+			body.Close()
+			read += n
+			if err == nil {
+				return read, nil
+			}
+
+			if n > 0 {
+				retry = 0
+			}
+
+			// the end of the file was reached, unless the size is known and
+			// the response was cut short.
+			eof := err == io.EOF || err == io.ErrUnexpectedEOF
+			if eof && (f.size < 0 || off+int64(read) >= f.size) {
+				return read, io.EOF
+			}
+		} else if !isTransient(err) {
+			return read, err
+		}
+
+		if !f.fs.retry.Wait(retry) {
+			return read, err
+		}
+
+		retry++
+	}
+
+	return read, nil
+}
+
+// isTransient returns true if err is a failure sending a request, which may
+// succeed if retried.
+func isTransient(err error) bool {
+	_, ok := err.(*url.Error)
+	return ok
+}
+
+// open returns the content of the file from offset, discarding the content
+// before it if the server doesn't support range requests. The body is limited
+// to length bytes, unless it's negative.
+func (f *file) open(offset, length int64) (io.ReadCloser, error) {
+	res, err := f.fs.get(f.name, offset, length)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusOK {
+		if _, err := io.CopyN(ioutil.Discard, res.Body, offset); err != nil {
+			res.Body.Close()
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+
+			return nil, err
+		}
+	}
+
+	if length < 0 {
+		return res.Body, nil
+	}
+
+	return &limitedBody{Reader: io.LimitReader(res.Body, length), Closer: res.Body}, nil
+}
+
+type limitedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.position
+	case io.SeekEnd:
+		if f.size < 0 {
+			return 0, &os.PathError{Op: "seek", Path: f.name, Err: billy.ErrNotSupported}
+		}
+
+		offset += f.size
+	default:
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrInvalid}
+	}
+
+	if offset != f.position && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+
+	f.position = offset
+	return offset, nil
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	return 0, billy.ErrReadOnly
+}
+
+func (f *file) Close() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	f.isClosed = true
+	if f.body != nil {
+		return f.body.Close()
+	}
+
+	return nil
+}
+
+// Lock is a no-op in httpfs.
+func (f *file) Lock() error {
+	return nil
+}
+
+// Unlock is a no-op in httpfs.
+func (f *file) Unlock() error {
+	return nil
+}
+
+func (f *file) Truncate(size int64) error {
+	return billy.ErrReadOnly
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func newDirInfo(filename string) *fileInfo {
+	return &fileInfo{
+		name:    filepath.Base(filename),
+		mode:    defaultDirectoryMode,
+		modTime: time.Now(),
+	}
+}
+
+func newFileInfo(filename string, res *http.Response) *fileInfo {
+	fi := &fileInfo{
+		name:    filepath.Base(filename),
+		mode:    defaultFileMode,
+		modTime: time.Now(),
+	}
+
+	if res.ContentLength > 0 {
+		fi.size = res.ContentLength
+	}
+
+	if t, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		fi.modTime = t
+	}
+
+	return fi
+}
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *fileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi *fileInfo) Mode() os.FileMode {
+	return fi.mode
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+func (fi *fileInfo) IsDir() bool {
+	return fi.mode.IsDir()
+}
+
+func (*fileInfo) Sys() interface{} {
+	return nil
+}
@@ -0,0 +1,381 @@
+package httpfs
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/transport"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&HTTPSuite{})
+
+type HTTPSuite struct {
+	FS     billy.Filesystem
+	path   string
+	server *httptest.Server
+}
+
+func (s *HTTPSuite) SetUpTest(c *C) {
+	s.path = c.MkDir()
+
+	files := map[string]string{
+		"foo":         "foo",
+		"bar/qux":     "qux",
+		"bar/baz/foo": "foo",
+		"a b/c&d":     "cd",
+	}
+
+	for name, content := range files {
+		fullpath := filepath.Join(s.path, name)
+		c.Assert(os.MkdirAll(filepath.Dir(fullpath), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fullpath, []byte(content), 0644), IsNil)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/files/", http.StripPrefix("/files", http.FileServer(http.Dir(s.path))))
+	mux.HandleFunc("/manifest/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name":"foo","size":3},{"name":"bar","dir":true}]`))
+	})
+
+	mux.HandleFunc("/norange/foo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("foobar"))
+	})
+
+	var interrupted bool
+	mux.HandleFunc("/flaky/foo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && !interrupted {
+			interrupted = true
+			w.Header().Set("Content-Length", "6")
+			w.Write([]byte("foo"))
+			return
+		}
+
+		http.ServeContent(w, r, "foo", time.Time{}, strings.NewReader("foobar"))
+	})
+
+	var interruptions int
+	mux.HandleFunc("/unstable/foo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && interruptions < 2 {
+			interruptions++
+			w = &cutWriter{ResponseWriter: w, n: 2}
+		}
+
+		http.ServeContent(w, r, "foo", time.Time{}, strings.NewReader("foobar"))
+	})
+
+	mux.HandleFunc("/private/foo", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "foo" || pass != "bar" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		http.ServeContent(w, r, "foo", time.Time{}, strings.NewReader("private"))
+	})
+
+	s.server = httptest.NewServer(mux)
+
+	var err error
+	s.FS, err = New(s.server.URL+"/files", nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *HTTPSuite) TearDownTest(c *C) {
+	s.server.Close()
+}
+
+func (s *HTTPSuite) TestOpen(c *C) {
+	f, err := s.FS.Open("bar/qux")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, filepath.Join("bar", "qux"))
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "qux")
+	c.Assert(f.Close(), IsNil)
+
+	_, err = ioutil.ReadAll(f)
+	c.Assert(err, Equals, os.ErrClosed)
+}
+
+func (s *HTTPSuite) TestOpenEscaped(c *C) {
+	f, err := s.FS.Open("a b/c&d")
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "cd")
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *HTTPSuite) TestOpenNotExists(c *C) {
+	f, err := s.FS.Open("qux")
+	c.Assert(os.IsNotExist(err), Equals, true)
+	c.Assert(f, IsNil)
+}
+
+func (s *HTTPSuite) TestOpenDir(c *C) {
+	f, err := s.FS.Open("bar")
+	c.Assert(err, NotNil)
+	c.Assert(f, IsNil)
+}
+
+func (s *HTTPSuite) TestStat(c *C) {
+	fi, err := s.FS.Stat("bar/qux")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "qux")
+	c.Assert(fi.Size(), Equals, int64(3))
+	c.Assert(fi.IsDir(), Equals, false)
+	c.Assert(fi.ModTime().IsZero(), Equals, false)
+
+	fi, err = s.FS.Stat("bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "bar")
+	c.Assert(fi.IsDir(), Equals, true)
+
+	fi, err = s.FS.Stat("/")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+
+	_, err = s.FS.Stat("qux")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *HTTPSuite) TestReadDir(c *C) {
+	l, err := s.FS.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 3)
+
+	c.Assert(l[0].Name(), Equals, "a b")
+	c.Assert(l[0].IsDir(), Equals, true)
+	c.Assert(l[1].Name(), Equals, "bar")
+	c.Assert(l[1].IsDir(), Equals, true)
+	c.Assert(l[2].Name(), Equals, "foo")
+	c.Assert(l[2].IsDir(), Equals, false)
+	c.Assert(l[2].Size(), Equals, int64(3))
+
+	l, err = s.FS.ReadDir("a b")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 1)
+	c.Assert(l[0].Name(), Equals, "c&d")
+	c.Assert(l[0].Size(), Equals, int64(2))
+}
+
+func (s *HTTPSuite) TestReadDirManifest(c *C) {
+	fs, err := New(s.server.URL+"/manifest", nil)
+	c.Assert(err, IsNil)
+
+	l, err := fs.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 2)
+	c.Assert(l[0].Name(), Equals, "foo")
+	c.Assert(l[0].Size(), Equals, int64(3))
+	c.Assert(l[1].Name(), Equals, "bar")
+	c.Assert(l[1].IsDir(), Equals, true)
+}
+
+func (s *HTTPSuite) TestChroot(c *C) {
+	fs, err := s.FS.Chroot("bar")
+	c.Assert(err, IsNil)
+
+	l, err := fs.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 2)
+
+	f, err := fs.Open("qux")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, "qux")
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *HTTPSuite) TestReadOnly(c *C) {
+	_, err := s.FS.Create("foo")
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	_, err = s.FS.OpenFile("foo", os.O_RDWR, 0)
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	c.Assert(s.FS.Rename("foo", "bar"), Equals, billy.ErrReadOnly)
+	c.Assert(s.FS.Remove("foo"), Equals, billy.ErrReadOnly)
+	c.Assert(s.FS.MkdirAll("qux", 0755), Equals, billy.ErrReadOnly)
+	c.Assert(s.FS.Symlink("foo", "qux"), Equals, billy.ErrReadOnly)
+
+	_, err = s.FS.TempFile("", "foo")
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	f, err := s.FS.Open("foo")
+	c.Assert(err, IsNil)
+
+	_, err = f.Write([]byte("foo"))
+	c.Assert(err, Equals, billy.ErrReadOnly)
+	c.Assert(f.Truncate(0), Equals, billy.ErrReadOnly)
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *HTTPSuite) TestParseIndex(c *C) {
+	index := []byte(`<html><body>
+<a href="?C=N;O=D">Name</a>
+<a href="../">Parent Directory</a>
+<a href="http://example.com/">Other</a>
+<a href="foo">foo</a>
+<A HREF='bar/'>bar/</A>
+<a href="a%20b">a b</a>
+<a href="qux/baz">nested</a>
+<a href="foo">duplicated</a>
+</body></html>`)
+
+	c.Assert(parseIndex(index), DeepEquals, []string{"foo", "bar/", "a b"})
+}
+
+func (s *HTTPSuite) TestCapabilities(c *C) {
+	caps := billy.Capabilities(s.FS)
+	c.Assert(caps, Equals, billy.ReadCapability|billy.SeekCapability)
+}
+
+func (s *HTTPSuite) TestSeek(c *C) {
+	f, err := s.FS.Open("bar/qux")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	pos, err := f.Seek(-2, io.SeekEnd)
+	c.Assert(err, IsNil)
+	c.Assert(pos, Equals, int64(1))
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "ux")
+
+	b := make([]byte, 2)
+	n, err := f.ReadAt(b, 0)
+	c.Assert(err, IsNil)
+	c.Assert(string(b[:n]), Equals, "qu")
+
+	n, err = f.ReadAt(b, 2)
+	c.Assert(err, Equals, io.EOF)
+	c.Assert(string(b[:n]), Equals, "x")
+}
+
+func (s *HTTPSuite) TestReadResumed(c *C) {
+	fs, err := New(s.server.URL+"/flaky", nil)
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foobar")
+}
+
+// cutWriter writes only the first n bytes of a response, as a connection
+// reset mid-stream.
+type cutWriter struct {
+	http.ResponseWriter
+	n int
+}
+
+func (w *cutWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		w.ResponseWriter.Write(p[:w.n])
+		w.n = 0
+		return len(p), nil
+	}
+
+	w.n -= len(p)
+	return w.ResponseWriter.Write(p)
+}
+
+var fastRetry = &transport.RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond}
+
+func (s *HTTPSuite) TestReadResumedRetries(c *C) {
+	fs, err := NewWithConfig(s.server.URL+"/unstable", &transport.Config{Retry: fastRetry})
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foobar")
+}
+
+func (s *HTTPSuite) TestReadNotRetried(c *C) {
+	fs, err := NewWithConfig(s.server.URL+"/unstable", &transport.Config{
+		Retry: &transport.RetryPolicy{},
+	})
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, Equals, io.ErrUnexpectedEOF)
+	c.Assert(string(content), Equals, "fo")
+}
+
+func (s *HTTPSuite) TestReadAtResumed(c *C) {
+	fs, err := NewWithConfig(s.server.URL+"/unstable", &transport.Config{Retry: fastRetry})
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	b := make([]byte, 5)
+	n, err := f.ReadAt(b, 1)
+	c.Assert(err, IsNil)
+	c.Assert(string(b[:n]), Equals, "oobar")
+}
+
+func (s *HTTPSuite) TestReadWithoutRanges(c *C) {
+	fs, err := New(s.server.URL+"/norange", nil)
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.Seek(3, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+}
+
+func (s *HTTPSuite) TestNewWithConfig(c *C) {
+	fs, err := New(s.server.URL+"/private", nil)
+	c.Assert(err, IsNil)
+	_, err = fs.Open("foo")
+	c.Assert(err, NotNil)
+
+	fs, err = NewWithConfig(s.server.URL+"/private", &transport.Config{
+		Credentials: transport.StaticCredentials(transport.Credentials{
+			Username: "foo",
+			Password: "bar",
+		}),
+	})
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("foo")
+	c.Assert(err, IsNil)
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "private")
+	c.Assert(f.Close(), IsNil)
+}
@@ -0,0 +1,116 @@
+// Package buffer provides the content of a file buffered in memory, read and
+// written at a position, as used by the helpers writing the files once closed.
+package buffer // import "gopkg.in/src-d/go-billy.v4/internal/buffer"
+
+import (
+	"io"
+	"os"
+)
+
+// Buffer is the content of a file held in memory, with the position of the
+// next read or write. The errors returned are not wrapped, the callers wrap
+// them in a *os.PathError with the name of their file.
+type Buffer struct {
+	content  []byte
+	position int64
+}
+
+// New returns a new Buffer holding content, at the position zero.
+func New(content []byte) *Buffer {
+	return &Buffer{content: content}
+}
+
+// Bytes returns the content of the buffer, valid until the next write.
+func (b *Buffer) Bytes() []byte {
+	return b.content
+}
+
+// Len returns the size of the content.
+func (b *Buffer) Len() int64 {
+	return int64(len(b.content))
+}
+
+func (b *Buffer) Read(p []byte) (int, error) {
+	n, err := b.ReadAt(p, b.position)
+	b.position += int64(n)
+	return n, err
+}
+
+// ReadAt reads from the given offset, it fails with os.ErrInvalid if the
+// offset is negative.
+func (b *Buffer) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, os.ErrInvalid
+	}
+
+	if off >= b.Len() {
+		return 0, io.EOF
+	}
+
+	n := copy(p, b.content[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// Write writes at the current position, growing the content as needed.
+func (b *Buffer) Write(p []byte) (int, error) {
+	end := b.position + int64(len(p))
+	if end > b.Len() {
+		b.resize(end)
+	}
+
+	copy(b.content[b.position:], p)
+	b.position = end
+	return len(p), nil
+}
+
+// Seek sets the position of the next read or write, it fails with
+// os.ErrInvalid on an unknown whence or a negative position.
+func (b *Buffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.position
+	case io.SeekEnd:
+		offset += b.Len()
+	default:
+		return 0, os.ErrInvalid
+	}
+
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+
+	b.position = offset
+	return offset, nil
+}
+
+// Truncate changes the size of the content, filling it with zeros if it
+// grows, it fails with os.ErrInvalid if size is negative.
+func (b *Buffer) Truncate(size int64) error {
+	if size < 0 {
+		return os.ErrInvalid
+	}
+
+	b.resize(size)
+	return nil
+}
+
+func (b *Buffer) resize(size int64) {
+	if size <= int64(cap(b.content)) {
+		old := b.Len()
+		b.content = b.content[:size]
+		for i := old; i < size; i++ {
+			b.content[i] = 0
+		}
+
+		return
+	}
+
+	content := make([]byte, size, size*2)
+	copy(content, b.content)
+	b.content = content
+}
@@ -0,0 +1,69 @@
+package buffer
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&BufferSuite{})
+
+type BufferSuite struct{}
+
+func (s *BufferSuite) TestWriteAndRead(c *C) {
+	b := New(nil)
+	_, err := b.Write([]byte("foobar"))
+	c.Assert(err, IsNil)
+
+	_, err = b.Seek(3, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	_, err = b.Write([]byte("qux"))
+	c.Assert(err, IsNil)
+
+	_, err = b.Seek(0, io.SeekStart)
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(b)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "fooqux")
+}
+
+func (s *BufferSuite) TestSeekPastEndAndWrite(c *C) {
+	b := New([]byte("foo"))
+	_, err := b.Seek(2, io.SeekEnd)
+	c.Assert(err, IsNil)
+
+	_, err = b.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+	c.Assert(string(b.Bytes()), Equals, "foo\x00\x00bar")
+}
+
+func (s *BufferSuite) TestTruncate(c *C) {
+	b := New([]byte("foobar"))
+	c.Assert(b.Truncate(3), IsNil)
+	c.Assert(string(b.Bytes()), Equals, "foo")
+
+	c.Assert(b.Truncate(5), IsNil)
+	c.Assert(string(b.Bytes()), Equals, "foo\x00\x00")
+
+	c.Assert(b.Truncate(-1), Equals, os.ErrInvalid)
+	c.Assert(b.Len(), Equals, int64(5))
+}
+
+func (s *BufferSuite) TestInvalid(c *C) {
+	b := New([]byte("foo"))
+	_, err := b.Seek(0, 42)
+	c.Assert(err, Equals, os.ErrInvalid)
+
+	_, err = b.Seek(-4, io.SeekEnd)
+	c.Assert(err, Equals, os.ErrInvalid)
+
+	_, err = b.ReadAt(make([]byte, 1), -1)
+	c.Assert(err, Equals, os.ErrInvalid)
+}
@@ -0,0 +1,17 @@
+package memfs
+
+import (
+	"gopkg.in/src-d/go-billy.v4/test"
+
+	. "gopkg.in/check.v1"
+)
+
+type BenchmarkSuite struct {
+	test.BenchmarkSuite
+}
+
+var _ = Suite(&BenchmarkSuite{})
+
+func (s *BenchmarkSuite) SetUpTest(c *C) {
+	s.BenchmarkSuite = test.NewBenchmarkSuite(New())
+}
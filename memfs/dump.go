@@ -0,0 +1,186 @@
+package memfs
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+)
+
+// paxXattr is the prefix of the PAX records of the extended attributes, the
+// one used by GNU tar and Go's archive/tar.
+const paxXattr = "SCHILY.xattr."
+
+// Dump writes the files, directories and symbolic links of fs, a filesystem
+// returned by New or any chroot of it, to w as a tar archive, which Load
+// restores. The files linked with Link are written as hard links, and the
+// extended attributes as PAX records. The archive is written from a snapshot
+// of fs, so fs can be used meanwhile, and the same tree always results in the
+// same archive. If fs isn't backed by a Memory billy.ErrNotSupported is
+// returned.
+func Dump(fs billy.Basic, w io.Writer) error {
+	m, base, err := underlyingMemory(fs)
+	if err != nil {
+		return err
+	}
+
+	dir, ok := m.snapshot().m.s.Get(base)
+	if !ok {
+		return &os.PathError{Op: "dump", Path: base, Err: os.ErrNotExist}
+	}
+
+	tw := tar.NewWriter(w)
+	if err := dump(tw, dir, "", make(map[*content]string)); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// dump writes the tree of dir, named prefix in the archive. The contents
+// already written are in links, by the name of the file written.
+func dump(tw *tar.Writer, dir *file, prefix string, links map[*content]string) error {
+	for _, f := range dir.sortedChildren() {
+		name := path.Join(prefix, f.name)
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    int64(f.mode.Perm()),
+			ModTime: time.Unix(0, 0),
+		}
+
+		switch {
+		case f.mode.IsDir():
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+		case isSymlink(f.mode):
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = string(f.content.Bytes())
+		default:
+			if target, ok := links[f.content]; ok {
+				hdr.Typeflag = tar.TypeLink
+				hdr.Linkname = target
+				break
+			}
+
+			links[f.content] = name
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(f.content.Len())
+		}
+
+		if hdr.Typeflag != tar.TypeLink && hdr.Typeflag != tar.TypeSymlink {
+			for attr, value := range f.content.xattrs {
+				if hdr.PAXRecords == nil {
+					hdr.PAXRecords = make(map[string]string)
+				}
+
+				hdr.PAXRecords[paxXattr+attr] = string(value)
+			}
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(f.content.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		if f.mode.IsDir() {
+			if err := dump(tw, f, name, links); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Load returns a new Memory filesystem with the contents of the tar archive
+// read from r, as written by Dump. The regular files, directories, symbolic
+// links and hard links of the archive are restored, with their permissions
+// and extended attributes, any other entry is ignored. The options are the
+// ones of NewWithOptions, the permissions aren't enforced while loading.
+func Load(r io.Reader, opts ...Option) (billy.Filesystem, error) {
+	m := &Memory{s: newStorage(), ignorePerms: true}
+	fs := chroot.New(m, string(separator))
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			m.ignorePerms = false
+			for _, opt := range opts {
+				opt(m)
+			}
+
+			return fs, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := load(fs, tr, hdr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func load(fs billy.Filesystem, r io.Reader, hdr *tar.Header) error {
+	name := filepath.FromSlash(hdr.Name)
+	perm := os.FileMode(hdr.Mode).Perm()
+
+	var err error
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		err = fs.MkdirAll(name, perm)
+	case tar.TypeReg, tar.TypeRegA, tar.TypeGNUSparse:
+		err = loadFile(fs, name, r, perm)
+	case tar.TypeSymlink:
+		return fs.Symlink(hdr.Linkname, name)
+	case tar.TypeLink:
+		return billy.Link(fs, filepath.FromSlash(hdr.Linkname), name)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for key, value := range hdr.PAXRecords {
+		if !strings.HasPrefix(key, paxXattr) {
+			continue
+		}
+
+		attr := strings.TrimPrefix(key, paxXattr)
+		if err := billy.SetXattr(fs, name, attr, []byte(value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadFile(fs billy.Filesystem, name string, r io.Reader, perm os.FileMode) (err error) {
+	f, err := fs.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+	}()
+
+	_, err = io.Copy(f, r)
+	return err
+}
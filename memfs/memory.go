@@ -2,31 +2,73 @@
 package memfs // import "gopkg.in/src-d/go-billy.v4/memfs"
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/cancel"
 	"gopkg.in/src-d/go-billy.v4/helper/chroot"
 	"gopkg.in/src-d/go-billy.v4/util"
 )
 
 const separator = filepath.Separator
 
-// Memory a very convenient filesystem based on memory files
+// Memory a very convenient filesystem based on memory files. It's safe for
+// concurrent use by multiple goroutines.
+//
+// It implements the billy.Watcher interface, with the events published by the
+// operations of the filesystem itself.
 type Memory struct {
-	s *storage
+	s   *storage
+	mu  sync.Mutex
+	bus bus
 
 	tempCount int
+
+	// ignorePerms disables the enforcement of the permissions.
+	ignorePerms bool
 }
 
-//New returns a new Memory filesystem.
+// New returns a new Memory filesystem. The permissions of the files and
+// directories are enforced as the ones of their owner.
 func New() billy.Filesystem {
+	return NewWithOptions()
+}
+
+// NewTemp returns a new Memory filesystem based on a directory named with the
+// given prefix, and a function removing the directory and all its content,
+// mirroring osfs.NewTemp so the tests can use any of them.
+func NewTemp(prefix string) (billy.Filesystem, func() error, error) {
+	fs := &Memory{s: newStorage()}
+	base := filepath.Join(string(separator), prefix)
+	if err := fs.MkdirAll(base, 0700); err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() error {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+
+		fs.s = newStorage()
+		return nil
+	}
+
+	return chroot.New(fs, base), cleanup, nil
+}
+
+// NewWithOptions returns a new Memory filesystem configured by opts, as
+// WithoutPermissions.
+func NewWithOptions(opts ...Option) billy.Filesystem {
 	fs := &Memory{s: newStorage()}
+	for _, opt := range opts {
+		opt(fs)
+	}
+
 	return chroot.New(fs, string(separator))
 }
 
@@ -39,93 +81,233 @@ func (fs *Memory) Open(filename string) (billy.File, error) {
 }
 
 func (fs *Memory) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
-	f, has := fs.s.Get(filename)
-	if !has {
-		if !isCreate(flag) {
-			return nil, os.ErrNotExist
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := fs.openFile(filename, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// OpenFileOpt implements the OptionOpener interface. Only the Snapshot option
+// is supported, when opening a file in read-only mode, it reads a copy of the
+// content taken at open time.
+func (fs *Memory) OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	o := billy.NewOpenOptions(opts...)
+
+	fs.mu.Lock()
+	f, err := fs.openFile(filename, flag, perm)
+	fs.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Snapshot && flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_TRUNC) == 0 {
+		f.content = &content{name: f.content.name, bytes: f.content.Bytes()}
+	}
+
+	return o.ApplyFilters(f)
+}
+
+func (fs *Memory) openFile(filename string, flag int, perm os.FileMode) (*file, error) {
+	links := 0
+	for {
+		if err := fs.checkSearch(filename); err != nil {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: err}
 		}
 
-		var err error
-		f, err = fs.s.New(filename, perm, flag)
-		if err != nil {
-			return nil, err
+		f, has := fs.s.Get(filename)
+		if !has {
+			return fs.createFile(filename, flag, perm)
+		}
+
+		if isCreate(flag) && isExclusive(flag) {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrExist}
 		}
-	} else {
+
 		if target, isLink := fs.resolveLink(filename, f); isLink {
-			return fs.OpenFile(target, flag, perm)
+			if links++; links > billy.MaxSymlinks {
+				return nil, &os.PathError{Op: "open", Path: filename, Err: billy.ErrTooManyLinks}
+			}
+
+			filename = target
+			continue
+		}
+
+		if f.mode.IsDir() {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: billy.ErrIsDir}
+		}
+
+		if err := fs.checkOpen(f, flag); err != nil {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+		}
+
+		if isTruncate(flag) {
+			fs.notify(billy.Write, filename)
 		}
+
+		return fs.duplicate(f, filename, flag), nil
+	}
+}
+
+// createFile creates the missing file filename, if O_CREATE is given.
+func (fs *Memory) createFile(filename string, flag int, perm os.FileMode) (*file, error) {
+	if !isCreate(flag) {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+	}
+
+	if err := fs.checkWrite(filename); err != nil {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+	}
+
+	missing := fs.missing(filename)
+	f, err := fs.s.New(filename, perm, flag)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: err}
 	}
 
-	if f.mode.IsDir() {
-		return nil, fmt.Errorf("cannot open directory: %s", filename)
+	fs.notify(billy.Create, missing...)
+	return fs.duplicate(f, filename, flag), nil
+}
+
+func (fs *Memory) duplicate(f *file, filename string, flag int) *file {
+	d := f.Duplicate(filename, f.mode, flag)
+	d.bus = &fs.bus
+	return d
+}
+
+// missing returns path and its parents that don't exist, the outermost first.
+func (fs *Memory) missing(path string) []string {
+	var paths []string
+	for p := clean(path); !fs.s.Has(p); p = filepath.Dir(p) {
+		paths = append([]string{p}, paths...)
 	}
 
-	return f.Duplicate(filename, perm, flag), nil
+	return paths
 }
 
-var errNotLink = errors.New("not a link")
+// notify publishes an event with the given operation for each path.
+func (fs *Memory) notify(op billy.Op, paths ...string) {
+	for _, path := range paths {
+		fs.bus.publish(path, op)
+	}
+}
+
+// Watch implements the Watcher interface. The events are delivered for the
+// changes done after the call, by this filesystem.
+func (fs *Memory) Watch(path string, recursive bool) (<-chan billy.Event, billy.CancelFunc, error) {
+	events, cancel := fs.bus.watch(path, recursive)
+	return events, cancel, nil
+}
+
+// WithContext implements the Contexter interface, checking ctx before each
+// operation.
+func (fs *Memory) WithContext(ctx context.Context) billy.Filesystem {
+	return cancel.New(fs, ctx)
+}
 
 func (fs *Memory) resolveLink(fullpath string, f *file) (target string, isLink bool) {
 	if !isSymlink(f.mode) {
 		return fullpath, false
 	}
 
-	target = string(f.content.bytes)
-	if !isAbs(target) {
+	target = string(f.content.Bytes())
+	if !billy.IsAbs(target) {
 		target = fs.Join(filepath.Dir(fullpath), target)
 	}
 
 	return target, true
 }
 
-// On Windows OS, IsAbs validates if a path is valid based on if stars with a
-// unit (eg.: `C:\`)  to assert that is absolute, but in this mem implementation
-// any path starting by `separator` is also considered absolute.
-func isAbs(path string) bool {
-	return filepath.IsAbs(path) || strings.HasPrefix(path, string(separator))
-}
-
 func (fs *Memory) Stat(filename string) (os.FileInfo, error) {
-	f, has := fs.s.Get(filename)
-	if !has {
-		return nil, os.ErrNotExist
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fi, err := fs.stat(filename)
+	if err != nil {
+		return nil, &os.PathError{Op: "stat", Path: filename, Err: err}
 	}
 
-	fi, _ := f.Stat()
+	return fi, nil
+}
 
-	var err error
-	if target, isLink := fs.resolveLink(filename, f); isLink {
-		fi, err = fs.Stat(target)
-		if err != nil {
-			return nil, err
-		}
+// stat returns the FileInfo of filename, following the links, or
+// os.ErrNotExist.
+func (fs *Memory) stat(filename string) (os.FileInfo, error) {
+	f, err := fs.resolve(filename)
+	if err != nil {
+		return nil, err
 	}
 
 	// the name of the file should always the name of the stated file, so we
 	// overwrite the Stat returned from the storage with it, since the
 	// filename may belong to a link.
+	fi, _ := f.Stat()
 	fi.(*fileInfo).name = filepath.Base(filename)
 	return fi, nil
 }
 
+// resolve returns the file of filename, following the links, or
+// os.ErrNotExist.
+func (fs *Memory) resolve(filename string) (*file, error) {
+	links := 0
+	for {
+		if err := fs.checkSearch(filename); err != nil {
+			return nil, err
+		}
+
+		f, has := fs.s.Get(filename)
+		if !has {
+			return nil, os.ErrNotExist
+		}
+
+		target, isLink := fs.resolveLink(filename, f)
+		if !isLink {
+			return f, nil
+		}
+
+		if links++; links > billy.MaxSymlinks {
+			return nil, billy.ErrTooManyLinks
+		}
+
+		filename = target
+	}
+}
+
 func (fs *Memory) Lstat(filename string) (os.FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.checkSearch(filename); err != nil {
+		return nil, &os.PathError{Op: "lstat", Path: filename, Err: err}
+	}
+
 	f, has := fs.s.Get(filename)
 	if !has {
-		return nil, os.ErrNotExist
+		return nil, &os.PathError{Op: "lstat", Path: filename, Err: os.ErrNotExist}
 	}
 
 	return f.Stat()
 }
 
 func (fs *Memory) ReadDir(path string) ([]os.FileInfo, error) {
-	if f, has := fs.s.Get(path); has {
-		if target, isLink := fs.resolveLink(path, f); isLink {
-			return fs.ReadDir(target)
-		}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.readDir(path)
+}
+
+func (fs *Memory) readDir(path string) ([]os.FileInfo, error) {
+	children, err := fs.children(path)
+	if err != nil {
+		return nil, err
 	}
 
 	var entries []os.FileInfo
-	for _, f := range fs.s.Children(path) {
+	for _, f := range children {
 		fi, _ := f.Stat()
 		entries = append(entries, fi)
 	}
@@ -133,15 +315,145 @@ func (fs *Memory) ReadDir(path string) ([]os.FileInfo, error) {
 	return entries, nil
 }
 
+// ReadDirIter implements the DirIterator interface. The children of the
+// directory are taken when it's called, but their attributes are read as
+// they're requested.
+func (fs *Memory) ReadDirIter(path string) (billy.DirIter, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	children, err := fs.children(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dirIter{fs: fs, children: children}, nil
+}
+
+// children returns the children of the directory path, following the links,
+// if it can be listed.
+func (fs *Memory) children(path string) ([]*file, error) {
+	links := 0
+	for {
+		if err := fs.checkSearch(path); err != nil {
+			return nil, &os.PathError{Op: "readdir", Path: path, Err: err}
+		}
+
+		f, has := fs.s.Get(path)
+		if !has {
+			return nil, &os.PathError{Op: "readdir", Path: path, Err: os.ErrNotExist}
+		}
+
+		if target, isLink := fs.resolveLink(path, f); isLink {
+			if links++; links > billy.MaxSymlinks {
+				return nil, &os.PathError{Op: "readdir", Path: path, Err: billy.ErrTooManyLinks}
+			}
+
+			path = target
+			continue
+		}
+
+		if !f.mode.IsDir() {
+			return nil, &os.PathError{Op: "readdir", Path: path, Err: billy.ErrNotDir}
+		}
+
+		if !fs.allowed(f, permRead) {
+			return nil, &os.PathError{Op: "readdir", Path: path, Err: os.ErrPermission}
+		}
+
+		return f.sortedChildren(), nil
+	}
+}
+
+type dirIter struct {
+	fs       *Memory
+	children []*file
+}
+
+func (i *dirIter) Next(n int) ([]os.FileInfo, error) {
+	if n > 0 && len(i.children) == 0 {
+		return nil, io.EOF
+	}
+
+	if n <= 0 || n > len(i.children) {
+		n = len(i.children)
+	}
+
+	i.fs.mu.Lock()
+	defer i.fs.mu.Unlock()
+
+	entries := make([]os.FileInfo, n)
+	for j, f := range i.children[:n] {
+		entries[j], _ = f.Stat()
+	}
+
+	i.children = i.children[n:]
+	return entries, nil
+}
+
+func (i *dirIter) Close() error {
+	i.children = nil
+	return nil
+}
+
 func (fs *Memory) MkdirAll(path string, perm os.FileMode) error {
-	_, err := fs.s.New(path, perm|os.ModeDir, 0)
-	return err
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	missing := fs.missing(path)
+	if len(missing) != 0 {
+		if err := fs.checkWrite(path); err != nil {
+			return &os.PathError{Op: "mkdir", Path: path, Err: err}
+		}
+	}
+
+	if _, err := fs.s.New(path, perm|os.ModeDir, 0); err != nil {
+		if err == billy.ErrExist {
+			err = billy.ErrNotDir
+		}
+
+		return &os.PathError{Op: "mkdir", Path: path, Err: err}
+	}
+
+	fs.notify(billy.Create, missing...)
+	return nil
 }
 
 func (fs *Memory) TempFile(dir, prefix string) (billy.File, error) {
 	return util.TempFile(fs, dir, prefix)
 }
 
+// TempDir creates a new temporary directory, its name is unique among the
+// names of the directories created by this filesystem.
+func (fs *Memory) TempDir(dir, prefix string) (string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	for {
+		name := fs.getTempFilename(dir, prefix)
+		if _, ok := fs.s.Get(clean(name)); ok {
+			continue
+		}
+
+		if err := fs.checkWrite(name); err != nil {
+			return "", &os.PathError{Op: "mkdir", Path: name, Err: err}
+		}
+
+		missing := fs.missing(name)
+		if _, err := fs.s.New(name, 0700|os.ModeDir, 0); err != nil {
+			return "", &os.PathError{Op: "mkdir", Path: name, Err: err}
+		}
+
+		fs.notify(billy.Create, missing...)
+
+		return name, nil
+	}
+}
+
 func (fs *Memory) getTempFilename(dir, prefix string) string {
 	fs.tempCount++
 	filename := fmt.Sprintf("%s_%d_%d", prefix, fs.tempCount, time.Now().UnixNano())
@@ -149,11 +461,124 @@ func (fs *Memory) getTempFilename(dir, prefix string) string {
 }
 
 func (fs *Memory) Rename(from, to string) error {
-	return fs.s.Rename(from, to)
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for _, path := range []string{from, to} {
+		if err := fs.checkWrite(path); err != nil {
+			return &os.LinkError{Op: "rename", Old: from, New: to, Err: err}
+		}
+	}
+
+	missing := fs.missing(to)
+	if err := fs.s.Rename(from, to); err != nil {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: err}
+	}
+
+	if clean(from) != clean(to) {
+		fs.notify(billy.Rename, from)
+		fs.notify(billy.Create, missing...)
+	}
+
+	return nil
+}
+
+// Link implements the Linker interface, both files share the same content.
+func (fs *Memory) Link(oldname, newname string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.checkSearch(oldname); err != nil {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+
+	if err := fs.checkWrite(newname); err != nil {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+
+	missing := fs.missing(newname)
+	if err := fs.s.Link(oldname, newname); err != nil {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+
+	fs.notify(billy.Create, missing...)
+	return nil
 }
 
 func (fs *Memory) Remove(filename string) error {
-	return fs.s.Remove(filename)
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.checkWrite(filename); err != nil {
+		return &os.PathError{Op: "remove", Path: filename, Err: err}
+	}
+
+	if err := fs.s.Remove(filename); err != nil {
+		return &os.PathError{Op: "remove", Path: filename, Err: err}
+	}
+
+	fs.notify(billy.Remove, filename)
+	return nil
+}
+
+// RemoveAll implements the RemoverAll interface. As os.RemoveAll does, it
+// removes every entry of the tree it can, the children of each directory
+// before it, and returns the first error found.
+func (fs *Memory) RemoveAll(path string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.checkSearch(path); err != nil {
+		return &os.PathError{Op: "removeall", Path: path, Err: err}
+	}
+
+	f, has := fs.s.Get(path)
+	if !has {
+		return nil
+	}
+
+	if f == fs.s.root {
+		return &os.PathError{Op: "removeall", Path: path, Err: os.ErrInvalid}
+	}
+
+	if err := fs.checkWrite(path); err != nil {
+		return &os.PathError{Op: "removeall", Path: path, Err: err}
+	}
+
+	var removed []string
+	err := fs.removeTree(path, f, &removed)
+	fs.notify(billy.Remove, removed...)
+	return err
+}
+
+// removeTree removes the tree of f, at path, appending the paths removed to
+// removed. The entries of a directory are only removed if it can be listed
+// and changed, and the directory itself only once it's empty.
+func (fs *Memory) removeTree(path string, f *file, removed *[]string) error {
+	if len(f.children) != 0 {
+		if !fs.allowed(f, permRead|permWrite|permExec) {
+			return &os.PathError{Op: "removeall", Path: path, Err: os.ErrPermission}
+		}
+
+		var first error
+		for _, child := range f.sortedChildren() {
+			err := fs.removeTree(filepath.Join(path, child.name), child, removed)
+			if first == nil {
+				first = err
+			}
+		}
+
+		if first != nil {
+			return first
+		}
+	}
+
+	if err := fs.s.Remove(path); err != nil {
+		return &os.PathError{Op: "removeall", Path: path, Err: err}
+	}
+
+	*removed = append(*removed, path)
+	return nil
 }
 
 func (fs *Memory) Join(elem ...string) string {
@@ -161,42 +586,62 @@ func (fs *Memory) Join(elem ...string) string {
 }
 
 func (fs *Memory) Symlink(target, link string) error {
-	_, err := fs.Stat(link)
-	if err == nil {
-		return os.ErrExist
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, has := fs.s.Get(link); has {
+		return &os.LinkError{Op: "symlink", Old: target, New: link, Err: os.ErrExist}
 	}
 
-	if !os.IsNotExist(err) {
-		return err
+	f, err := fs.openFile(link, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0777|os.ModeSymlink)
+	if err != nil {
+		return &os.LinkError{Op: "symlink", Old: target, New: link, Err: underlyingError(err)}
 	}
 
-	return util.WriteFile(fs, link, []byte(target), 0777|os.ModeSymlink)
+	_, err = f.Write([]byte(target))
+	return err
 }
 
 func (fs *Memory) Readlink(link string) (string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.checkSearch(link); err != nil {
+		return "", &os.PathError{Op: "readlink", Path: link, Err: err}
+	}
+
 	f, has := fs.s.Get(link)
 	if !has {
-		return "", os.ErrNotExist
+		return "", &os.PathError{Op: "readlink", Path: link, Err: os.ErrNotExist}
 	}
 
 	if !isSymlink(f.mode) {
-		return "", &os.PathError{
-			Op:   "readlink",
-			Path: link,
-			Err:  fmt.Errorf("not a symlink"),
-		}
+		return "", &os.PathError{Op: "readlink", Path: link, Err: os.ErrInvalid}
 	}
 
-	return string(f.content.bytes), nil
+	return string(f.content.Bytes()), nil
 }
 
 // Capabilities implements the Capable interface.
 func (fs *Memory) Capabilities() billy.Capability {
-	return billy.WriteCapability |
+	c := billy.WriteCapability |
 		billy.ReadCapability |
 		billy.ReadAndWriteCapability |
 		billy.SeekCapability |
-		billy.TruncateCapability
+		billy.TruncateCapability |
+		billy.SymlinkCapability |
+		billy.CreateExclusiveCapability
+
+	if !fs.ignorePerms {
+		c |= billy.PermissionCapability
+	}
+
+	return c
+}
+
+// Describe implements the Describer interface.
+func (fs *Memory) Describe() billy.Description {
+	return billy.DefaultDescription
 }
 
 type file struct {
@@ -205,6 +650,8 @@ type file struct {
 	position int64
 	flag     int
 	mode     os.FileMode
+	children map[string]*file
+	bus      *bus
 
 	isClosed bool
 }
@@ -230,7 +677,7 @@ func (f *file) ReadAt(b []byte, off int64) (int, error) {
 	}
 
 	if !isReadAndWrite(f.flag) && !isReadOnly(f.flag) {
-		return 0, errors.New("read not supported")
+		return 0, &os.PathError{Op: "read", Path: f.name, Err: os.ErrPermission}
 	}
 
 	n, err := f.content.ReadAt(b, off)
@@ -245,13 +692,19 @@ func (f *file) Seek(offset int64, whence int) (int64, error) {
 
 	switch whence {
 	case io.SeekCurrent:
-		f.position += offset
+		offset += f.position
 	case io.SeekStart:
-		f.position = offset
 	case io.SeekEnd:
-		f.position = int64(f.content.Len()) + offset
+		offset += int64(f.content.Len())
+	default:
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrInvalid}
 	}
 
+	f.position = offset
 	return f.position, nil
 }
 
@@ -261,12 +714,20 @@ func (f *file) Write(p []byte) (int, error) {
 	}
 
 	if !isReadAndWrite(f.flag) && !isWriteOnly(f.flag) {
-		return 0, errors.New("write not supported")
+		return 0, &os.PathError{Op: "write", Path: f.name, Err: os.ErrPermission}
+	}
+
+	if isAppend(f.flag) {
+		f.position = int64(f.content.Len())
 	}
 
 	n, err := f.content.WriteAt(p, f.position)
 	f.position += int64(n)
 
+	if n > 0 {
+		f.notify(billy.Write)
+	}
+
 	return n, err
 }
 
@@ -280,16 +741,36 @@ func (f *file) Close() error {
 }
 
 func (f *file) Truncate(size int64) error {
-	if size < int64(len(f.content.bytes)) {
-		f.content.bytes = f.content.bytes[:size]
-	} else if more := int(size) - len(f.content.bytes); more > 0 {
-		f.content.bytes = append(f.content.bytes, make([]byte, more)...)
+	if isReadOnly(f.flag) {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: os.ErrPermission}
+	}
+
+	if size < 0 {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: os.ErrInvalid}
 	}
 
+	f.content.Resize(size)
+	f.notify(billy.Write)
 	return nil
 }
 
-func (f *file) Duplicate(filename string, mode os.FileMode, flag int) billy.File {
+// notify publishes an event of the file, if it was opened by a Memory.
+func (f *file) notify(op billy.Op) {
+	if f.bus != nil {
+		f.bus.publish(f.name, op)
+	}
+}
+
+// Sync is a no-op in memfs, the content is never persisted.
+func (f *file) Sync() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	return nil
+}
+
+func (f *file) Duplicate(filename string, mode os.FileMode, flag int) *file {
 	new := &file{
 		name:    filename,
 		content: f.content,
@@ -357,13 +838,37 @@ func (*fileInfo) Sys() interface{} {
 }
 
 func (c *content) Truncate() {
-	c.bytes = make([]byte, 0)
+	c.Resize(0)
+}
+
+func (c *content) Resize(size int64) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.own()
+
+	if size < int64(len(c.bytes)) {
+		c.bytes = c.bytes[:size]
+	} else if more := int(size) - len(c.bytes); more > 0 {
+		c.bytes = append(c.bytes, make([]byte, more)...)
+	}
 }
 
 func (c *content) Len() int {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
 	return len(c.bytes)
 }
 
+// Bytes returns a copy of the content.
+func (c *content) Bytes() []byte {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	return append([]byte(nil), c.bytes...)
+}
+
 func isCreate(flag int) bool {
 	return flag&os.O_CREATE != 0
 }
@@ -372,20 +877,36 @@ func isAppend(flag int) bool {
 	return flag&os.O_APPEND != 0
 }
 
+func isExclusive(flag int) bool {
+	return flag&os.O_EXCL != 0
+}
+
 func isTruncate(flag int) bool {
 	return flag&os.O_TRUNC != 0
 }
 
+// accessMode is the mask of the access mode bits of the flags of OpenFile.
+const accessMode = os.O_RDONLY | os.O_WRONLY | os.O_RDWR
+
 func isReadAndWrite(flag int) bool {
-	return flag&os.O_RDWR != 0
+	return flag&accessMode == os.O_RDWR
 }
 
 func isReadOnly(flag int) bool {
-	return flag == os.O_RDONLY
+	return flag&accessMode == os.O_RDONLY
 }
 
 func isWriteOnly(flag int) bool {
-	return flag&os.O_WRONLY != 0
+	return flag&accessMode == os.O_WRONLY
+}
+
+// underlyingError returns the error wrapped by a *os.PathError.
+func underlyingError(err error) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return pe.Err
+	}
+
+	return err
 }
 
 func isSymlink(m os.FileMode) bool {
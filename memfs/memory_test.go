@@ -1,11 +1,18 @@
 package memfs
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
 
 	. "gopkg.in/check.v1"
 )
@@ -28,7 +35,16 @@ func (s *MemorySuite) TestCapabilities(c *C) {
 	c.Assert(ok, Equals, true)
 
 	caps := billy.Capabilities(s.FS)
-	c.Assert(caps, Equals, billy.DefaultCapabilities&^billy.LockCapability)
+	expected := billy.DefaultCapabilities | billy.SymlinkCapability |
+		billy.CreateExclusiveCapability
+	c.Assert(caps, Equals, expected&^billy.LockCapability|billy.PermissionCapability)
+}
+
+func (s *MemorySuite) TestDescribe(c *C) {
+	_, ok := s.FS.(billy.Describer)
+	c.Assert(ok, Equals, true)
+
+	c.Assert(billy.Describe(s.FS), Equals, billy.DefaultDescription)
 }
 
 func (s *MemorySuite) TestNegativeOffsets(c *C) {
@@ -40,7 +56,579 @@ func (s *MemorySuite) TestNegativeOffsets(c *C) {
 	c.Assert(err, ErrorMatches, "readat negative: negative offset")
 
 	_, err = f.Seek(-100, io.SeekCurrent)
+	c.Assert(err, ErrorMatches, "seek .*negative: invalid argument")
+}
+
+func (s *MemorySuite) TestRenameToSubdirectory(c *C) {
+	err := s.FS.MkdirAll("foo/bar", 0755)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Rename("foo", "foo/bar/qux")
+	c.Assert(err, NotNil)
+
+	fi, err := s.FS.Stat("foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+}
+
+func (s *MemorySuite) TestCreateInFile(c *C) {
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Create("foo/bar")
+	c.Assert(err, NotNil)
+}
+
+func (s *MemorySuite) TestOpenKeepsMode(c *C) {
+	fs := &Memory{s: newStorage()}
+	err := util.WriteFile(fs, "/foo", nil, 0640)
+	c.Assert(err, IsNil)
+
+	f, err := fs.OpenFile("/foo", os.O_RDWR, 0600)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	fi, err := f.(*file).Stat()
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode(), Equals, os.FileMode(0640))
+}
+
+func (s *MemorySuite) TestOpenFileOptSnapshot(c *C) {
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	snapshot, err := billy.OpenFileOpt(s.FS, "foo", os.O_RDONLY, 0, billy.WithSnapshot())
+	c.Assert(err, IsNil)
+
+	live, err := s.FS.Open("foo")
+	c.Assert(err, IsNil)
+
+	err = util.WriteFile(s.FS, "foo", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(snapshot)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+
+	content, err = ioutil.ReadAll(live)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+}
+
+func (s *MemorySuite) TestLink(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(billy.Link(s.FS, "foo", "dir/bar"), IsNil)
+
+	f, err := s.FS.OpenFile("dir/bar", os.O_WRONLY|os.O_APPEND, 0)
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	c.Assert(s.FS.Remove("dir/bar"), IsNil)
+
+	f, err = s.FS.Open("foo")
+	c.Assert(err, IsNil)
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foobar")
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *MemorySuite) TestLinkErrors(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", nil, 0644), IsNil)
+	c.Assert(s.FS.MkdirAll("dir", 0755), IsNil)
+
+	err := billy.Link(s.FS, "foo", "foo")
+	c.Assert(os.IsExist(err), Equals, true)
+
+	err = billy.Link(s.FS, "dir", "bar")
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	err = billy.Link(s.FS, "qux", "bar")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *MemorySuite) TestWatch(c *C) {
+	events, cancel, err := s.FS.(billy.Watcher).Watch("foo", false)
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(s.FS, "foo/bar", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "foo/qux/baz", nil, 0644), IsNil)
+	c.Assert(s.FS.Rename("foo/bar", "foo/baz"), IsNil)
+	c.Assert(s.FS.Remove("foo/baz"), IsNil)
+	c.Assert(util.WriteFile(s.FS, "qux", nil, 0644), IsNil)
+
+	expected := []billy.Event{
+		{Path: "foo", Op: billy.Create},
+		{Path: "foo/bar", Op: billy.Create},
+		{Path: "foo/bar", Op: billy.Write},
+		{Path: "foo/qux", Op: billy.Create},
+		{Path: "foo/bar", Op: billy.Rename},
+		{Path: "foo/baz", Op: billy.Create},
+		{Path: "foo/baz", Op: billy.Remove},
+	}
+
+	for _, e := range expected {
+		c.Assert(<-events, DeepEquals, billy.Event{
+			Path: filepath.FromSlash(e.Path), Op: e.Op,
+		})
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func (s *MemorySuite) TestWatchRecursive(c *C) {
+	events, cancel, err := s.FS.(billy.Watcher).Watch("/", true)
+	c.Assert(err, IsNil)
+	defer cancel()
+
+	c.Assert(s.FS.MkdirAll("foo/bar", 0755), IsNil)
+	c.Assert(<-events, DeepEquals, billy.Event{Path: "foo", Op: billy.Create})
+	c.Assert(<-events, DeepEquals, billy.Event{
+		Path: filepath.Join("foo", "bar"), Op: billy.Create,
+	})
+}
+
+func (s *MemorySuite) TestWithContext(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fs := billy.WithContext(s.FS, ctx)
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+	cancel()
+
+	_, err := fs.Open("foo")
+	c.Assert(err, Equals, context.Canceled)
+
+	_, err = s.FS.Open("foo")
+	c.Assert(err, IsNil)
+}
+
+func (s *MemorySuite) TestSnapshot(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "bar/qux", []byte("qux"), 0644), IsNil)
+
+	snap, err := Snapshot(s.FS)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", os.O_WRONLY, 0)
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Truncate(1), IsNil)
+	c.Assert(f.Close(), IsNil)
+	c.Assert(s.FS.Remove("bar/qux"), IsNil)
+	c.Assert(util.WriteFile(s.FS, "baz", nil, 0644), IsNil)
+
+	content, err := readFile(snap, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+
+	content, err = readFile(snap, "bar/qux")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "qux")
+
+	_, err = snap.Stat("baz")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	content, err = readFile(s.FS, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "b")
+}
+
+func (s *MemorySuite) TestSnapshotReadOnly(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+
+	snap, err := Snapshot(s.FS)
+	c.Assert(err, IsNil)
+	c.Assert(billy.CapabilityCheck(snap, billy.WriteCapability), Equals, false)
+
+	_, err = snap.Create("bar")
+	c.Assert(err, Equals, billy.ErrReadOnly)
+	_, err = snap.OpenFile("foo", os.O_RDWR, 0)
+	c.Assert(err, Equals, billy.ErrReadOnly)
+	c.Assert(snap.Remove("foo"), Equals, billy.ErrReadOnly)
+	c.Assert(snap.Rename("foo", "bar"), Equals, billy.ErrReadOnly)
+	c.Assert(snap.MkdirAll("bar", 0755), Equals, billy.ErrReadOnly)
+	c.Assert(snap.Symlink("foo", "bar"), Equals, billy.ErrReadOnly)
+
+	f, err := snap.Open("foo")
+	c.Assert(err, IsNil)
+	c.Assert(f.Truncate(0), NotNil)
+	c.Assert(f.Close(), IsNil)
+
+	content, err := readFile(snap, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *MemorySuite) TestSnapshotLinked(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(s.FS.(billy.Linker).Link("foo", "bar"), IsNil)
+
+	snap, err := Snapshot(s.FS)
+	c.Assert(err, IsNil)
+	c.Assert(util.WriteFile(s.FS, "bar", []byte("bar"), 0644), IsNil)
+
+	content, err := readFile(snap, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+
+	content, err = readFile(s.FS, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+}
+
+func (s *MemorySuite) TestSnapshotChroot(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar", []byte("bar"), 0644), IsNil)
+
+	fs, err := s.FS.Chroot("foo")
+	c.Assert(err, IsNil)
+
+	snap, err := Snapshot(fs)
+	c.Assert(err, IsNil)
+
+	content, err := readFile(snap, "bar")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+}
+
+func (s *MemorySuite) TestSnapshotNotSupported(c *C) {
+	_, err := Snapshot(&test.BasicMock{})
+	c.Assert(err, Equals, billy.ErrNotSupported)
+}
+
+func (s *MemorySuite) TestDiff(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "bar/qux", []byte("qux"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "bar/quux", []byte("quux"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "mode", nil, 0644), IsNil)
+	c.Assert(s.FS.Symlink("foo", "link"), IsNil)
+
+	snap, err := Snapshot(s.FS)
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("bar"), 0644), IsNil)
+	c.Assert(s.FS.Remove("bar/qux"), IsNil)
+	c.Assert(util.WriteFile(s.FS, "baz/qux", nil, 0644), IsNil)
+	c.Assert(s.FS.Remove("mode"), IsNil)
+	c.Assert(util.WriteFile(s.FS, "mode", nil, 0600), IsNil)
+	c.Assert(s.FS.Remove("link"), IsNil)
+	c.Assert(s.FS.Symlink("bar", "link"), IsNil)
+
+	changes, err := Diff(snap, s.FS)
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, []Change{
+		{Removed, filepath.Join("bar", "qux")},
+		{Added, "baz"},
+		{Added, filepath.Join("baz", "qux")},
+		{Modified, "foo"},
+		{Modified, "link"},
+		{Modified, "mode"},
+	})
+
+	changes, err = Diff(s.FS, s.FS)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+}
+
+func (s *MemorySuite) TestDumpAndLoad(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0600), IsNil)
+	c.Assert(util.WriteFile(s.FS, "bar/qux", []byte("qux"), 0644), IsNil)
+	c.Assert(s.FS.MkdirAll("baz", 0700), IsNil)
+	c.Assert(s.FS.Symlink("bar/qux", "link"), IsNil)
+	c.Assert(s.FS.(billy.Linker).Link("foo", "bar/foo"), IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	c.Assert(Dump(s.FS, buf), IsNil)
+
+	fs, err := Load(buf)
+	c.Assert(err, IsNil)
+
+	changes, err := Diff(s.FS, fs)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+
+	fi, err := fs.Stat("baz")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode(), Equals, os.ModeDir|0700)
+
+	target, err := fs.Readlink("link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "bar/qux")
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("bar"), 0600), IsNil)
+	content, err := readFile(fs, "bar/foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+}
+
+func (s *MemorySuite) TestDumpDeterministic(c *C) {
+	for _, name := range []string{"foo", "bar", "baz/qux", "baz/quux"} {
+		c.Assert(util.WriteFile(s.FS, name, []byte(name), 0644), IsNil)
+	}
+
+	a := bytes.NewBuffer(nil)
+	c.Assert(Dump(s.FS, a), IsNil)
+
+	b := bytes.NewBuffer(nil)
+	c.Assert(Dump(s.FS, b), IsNil)
+	c.Assert(a.Bytes(), DeepEquals, b.Bytes())
+}
+
+func (s *MemorySuite) TestDumpChroot(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar", []byte("bar"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "qux", []byte("qux"), 0644), IsNil)
+
+	chrooted, err := s.FS.Chroot("foo")
+	c.Assert(err, IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	c.Assert(Dump(chrooted, buf), IsNil)
+
+	fs, err := Load(buf)
+	c.Assert(err, IsNil)
+
+	changes, err := Diff(chrooted, fs)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+}
+
+func (s *MemorySuite) TestDumpNotSupported(c *C) {
+	err := Dump(&test.BasicMock{}, ioutil.Discard)
+	c.Assert(err, Equals, billy.ErrNotSupported)
+}
+
+func (s *MemorySuite) TestLoadCrossedBoundary(c *C) {
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	c.Assert(tw.WriteHeader(&tar.Header{
+		Name: "../foo", Typeflag: tar.TypeReg, Mode: 0644,
+	}), IsNil)
+	c.Assert(tw.Close(), IsNil)
+
+	_, err := Load(buf)
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+}
+
+func (s *MemorySuite) TestRemoveInReadOnlyDir(c *C) {
+	c.Assert(util.WriteFile(s.FS, "dir/foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "dir/bar", []byte("bar"), 0644), IsNil)
+
+	m, _, err := underlyingMemory(s.FS)
+	c.Assert(err, IsNil)
+	m.s.MustGet("dir").mode = os.ModeDir | 0555
+
+	err = s.FS.Remove("dir/foo")
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	err = s.FS.Rename("dir/foo", "foo")
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	err = s.FS.Rename("foo", "dir/bar")
+	c.Assert(os.IsNotExist(err), Equals, false)
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	_, err = s.FS.Stat("dir/foo")
+	c.Assert(err, IsNil)
+}
+
+func (s *MemorySuite) TestWithoutPermissions(c *C) {
+	fs := NewWithOptions(WithoutPermissions())
+	c.Assert(billy.CapabilityCheck(fs, billy.PermissionCapability), Equals, false)
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0), IsNil)
+	c.Assert(util.WriteFile(fs, "foo", []byte("bar"), 0), IsNil)
+
+	content, err := readFile(fs, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+
+	c.Assert(fs.MkdirAll("dir", 0), IsNil)
+	c.Assert(util.WriteFile(fs, "dir/foo", []byte("foo"), 0644), IsNil)
+
+	fi, err := fs.Stat("dir")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode(), Equals, os.ModeDir)
+}
+
+func (s *MemorySuite) TestLoadPermissions(c *C) {
+	c.Assert(util.WriteFile(s.FS, "dir/foo", []byte("foo"), 0400), IsNil)
+	c.Assert(s.FS.MkdirAll("ro", 0555), IsNil)
+
+	m, _, err := underlyingMemory(s.FS)
+	c.Assert(err, IsNil)
+	m.s.MustGet("dir").mode = os.ModeDir | 0500
+
+	buf := bytes.NewBuffer(nil)
+	c.Assert(Dump(s.FS, buf), IsNil)
+
+	fs, err := Load(bytes.NewReader(buf.Bytes()))
+	c.Assert(err, IsNil)
+
+	content, err := readFile(fs, "dir/foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+
+	_, err = fs.Create("ro/foo")
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	fs, err = Load(bytes.NewReader(buf.Bytes()), WithoutPermissions())
+	c.Assert(err, IsNil)
+	c.Assert(util.WriteFile(fs, "ro/foo", []byte("foo"), 0644), IsNil)
+}
+
+func (s *MemorySuite) TestSymlinkLoopReadDir(c *C) {
+	c.Assert(s.FS.Symlink("b", "a"), IsNil)
+	c.Assert(s.FS.Symlink("a", "b"), IsNil)
+
+	_, err := s.FS.ReadDir("a")
+	c.Assert(billy.IsTooManyLinks(err), Equals, true)
+
+	_, err = s.FS.(billy.DirIterator).ReadDirIter("a")
+	c.Assert(billy.IsTooManyLinks(err), Equals, true)
+
+	_, err = s.FS.Create("a")
+	c.Assert(billy.IsTooManyLinks(err), Equals, true)
+}
+
+func (s *MemorySuite) TestNewTemp(c *C) {
+	fs, cleanup, err := NewTemp("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fs.Root(), Equals, string(separator)+"foo")
+
+	l, err := fs.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 0)
+
+	c.Assert(util.WriteFile(fs, "foo/bar", []byte("bar"), 0644), IsNil)
+	c.Assert(cleanup(), IsNil)
+
+	_, err = fs.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *MemorySuite) TestXattrLinkAndSnapshot(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(billy.Link(s.FS, "foo", "bar"), IsNil)
+	c.Assert(billy.SetXattr(s.FS, "foo", "hash", []byte("1")), IsNil)
+
+	value, err := billy.GetXattr(s.FS, "bar", "hash")
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "1")
+
+	snap, err := Snapshot(s.FS)
+	c.Assert(err, IsNil)
+	c.Assert(billy.SetXattr(s.FS, "foo", "hash", []byte("2")), IsNil)
+
+	value, err = billy.GetXattr(snap, "foo", "hash")
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "1")
+
+	err = billy.SetXattr(snap, "foo", "hash", []byte("3"))
+	c.Assert(err, Equals, billy.ErrReadOnly)
+}
+
+func (s *MemorySuite) TestXattrDumpAndLoad(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(billy.SetXattr(s.FS, "foo", "hash", []byte("1")), IsNil)
+	c.Assert(billy.SetXattr(s.FS, "foo", "user.name", []byte("foo")), IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	c.Assert(Dump(s.FS, buf), IsNil)
+
+	fs, err := Load(buf)
+	c.Assert(err, IsNil)
+
+	attrs, err := billy.ListXattr(fs, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(attrs, DeepEquals, []string{"hash", "user.name"})
+
+	value, err := billy.GetXattr(fs, "foo", "user.name")
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "foo")
+}
+
+func (s *MemorySuite) TestXattrPermissions(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0444), IsNil)
+
+	err := billy.SetXattr(s.FS, "foo", "hash", []byte("1"))
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	_, err = billy.ListXattr(s.FS, "foo")
+	c.Assert(err, IsNil)
+}
+
+func (s *MemorySuite) TestRemoveAll(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar/qux", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "foo/baz", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "qux", nil, 0644), IsNil)
+
+	events, cancel, err := s.FS.(billy.Watcher).Watch("", true)
+	c.Assert(err, IsNil)
+	defer cancel()
+
+	c.Assert(s.FS.(billy.RemoverAll).RemoveAll("foo"), IsNil)
+
+	_, err = s.FS.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = s.FS.Stat("qux")
+	c.Assert(err, IsNil)
+
+	var removed []string
+	for i := 0; i < 4; i++ {
+		e := <-events
+		c.Assert(e.Op, Equals, billy.Remove)
+		removed = append(removed, filepath.ToSlash(e.Path))
+	}
+
+	c.Assert(removed, DeepEquals, []string{"foo/bar/qux", "foo/bar", "foo/baz", "foo"})
+	c.Assert(s.FS.(billy.RemoverAll).RemoveAll("foo"), IsNil)
+}
+
+func (s *MemorySuite) TestRemoveAllPermission(c *C) {
+	c.Assert(util.WriteFile(s.FS, "foo/bar/qux", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "foo/baz", nil, 0644), IsNil)
+
+	m, _, err := underlyingMemory(s.FS)
+	c.Assert(err, IsNil)
+	m.s.MustGet("foo/bar").mode = os.ModeDir | 0555
+
+	err = util.RemoveAll(s.FS, "foo")
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	_, err = s.FS.Stat("foo/bar/qux")
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("foo/baz")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *MemorySuite) TestRenameReplace(c *C) {
+	c.Assert(util.WriteFile(s.FS, "a", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "b", nil, 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "d/x", nil, 0644), IsNil)
+	c.Assert(s.FS.MkdirAll("e", 0755), IsNil)
+	c.Assert(s.FS.MkdirAll("f", 0755), IsNil)
+
+	err := s.FS.Rename("a", "d")
+	c.Assert(billy.IsDir(err), Equals, true, Commentf("%s", err))
+
+	err = s.FS.Rename("e", "d")
+	c.Assert(billy.IsNotEmpty(err), Equals, true, Commentf("%s", err))
+
+	err = s.FS.Rename("e", "a")
+	c.Assert(billy.IsNotDir(err), Equals, true, Commentf("%s", err))
+
+	err = s.FS.Rename("d/x", "d")
+	c.Assert(billy.IsDir(err), Equals, true, Commentf("%s", err))
+
+	c.Assert(s.FS.Rename("a", "b"), IsNil)
+	c.Assert(s.FS.Rename("e", "f"), IsNil)
+
+	_, err = s.FS.Stat("d/x")
 	c.Assert(err, IsNil)
-	_, err = f.Write(buf)
-	c.Assert(err, ErrorMatches, "writeat negative: negative offset")
 }
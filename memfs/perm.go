@@ -0,0 +1,98 @@
+package memfs
+
+import (
+	"os"
+)
+
+// The permissions of the owner, the ones enforced.
+const (
+	permRead  os.FileMode = 0400
+	permWrite os.FileMode = 0200
+	permExec  os.FileMode = 0100
+)
+
+// Option is an option of the filesystems returned by NewWithOptions.
+type Option func(*Memory)
+
+// WithoutPermissions disables the enforcement of the permissions, the modes
+// of the files are kept but any operation is allowed, as memfs did before
+// enforcing them.
+func WithoutPermissions() Option {
+	return func(fs *Memory) { fs.ignorePerms = true }
+}
+
+// allowed returns true if the permissions of f grant perm to the owner, or
+// they aren't enforced.
+func (fs *Memory) allowed(f *file, perm os.FileMode) bool {
+	return fs.ignorePerms || f.mode&perm == perm
+}
+
+// checkOpen returns os.ErrPermission if f can't be opened with flag.
+func (fs *Memory) checkOpen(f *file, flag int) error {
+	perm := permRead
+	switch {
+	case isWriteOnly(flag):
+		perm = permWrite
+	case isReadAndWrite(flag):
+		perm = permRead | permWrite
+	}
+
+	if isTruncate(flag) || isAppend(flag) {
+		perm |= permWrite
+	}
+
+	if !fs.allowed(f, perm) {
+		return os.ErrPermission
+	}
+
+	return nil
+}
+
+// checkSearch returns os.ErrPermission if any of the existing parents of path
+// lacks the search permission.
+func (fs *Memory) checkSearch(path string) error {
+	_, err := fs.searchParent(path)
+	return err
+}
+
+// checkWrite returns os.ErrPermission if the entry of path can't be added to
+// or removed from its parent, lacking it the write or search permissions. If
+// the parent is missing its nearest existing parent is checked, since it'd be
+// created.
+func (fs *Memory) checkWrite(path string) error {
+	dir, err := fs.searchParent(path)
+	if err != nil {
+		return err
+	}
+
+	if !fs.allowed(dir, permWrite|permExec) {
+		return os.ErrPermission
+	}
+
+	return nil
+}
+
+// searchParent walks the parents of path checking their search permission,
+// and returns the nearest existing one.
+func (fs *Memory) searchParent(path string) (*file, error) {
+	dir := fs.s.root
+	elems := split(path)
+	for i, name := range elems {
+		if !fs.allowed(dir, permExec) {
+			return nil, os.ErrPermission
+		}
+
+		if i == len(elems)-1 {
+			break
+		}
+
+		child, ok := dir.children[name]
+		if !ok || !child.mode.IsDir() {
+			break
+		}
+
+		dir = child
+	}
+
+	return dir, nil
+}
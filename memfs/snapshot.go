@@ -0,0 +1,307 @@
+package memfs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/helper/polyfill"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// Snapshot returns an immutable point-in-time view of the filesystem. The
+// tree of the files is copied, but their contents are shared with the
+// filesystem until they're written, so it's cheap regardless of their size.
+// The write operations of the snapshot fail with billy.ErrReadOnly.
+func (fs *Memory) Snapshot() billy.Filesystem {
+	return chroot.New(fs.snapshot(), string(separator))
+}
+
+func (fs *Memory) snapshot() *snapshot {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return &snapshot{m: &Memory{s: fs.s.snapshot(), ignorePerms: fs.ignorePerms}}
+}
+
+// Snapshot returns an immutable point-in-time view of fs, a filesystem
+// returned by New or any chroot of it, as Memory.Snapshot does. The snapshot
+// has the same root as fs. If fs isn't backed by a Memory
+// billy.ErrNotSupported is returned.
+func Snapshot(fs billy.Basic) (billy.Filesystem, error) {
+	m, base, err := underlyingMemory(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	return chroot.New(m.snapshot(), base), nil
+}
+
+// underlyingMemory returns the Memory backing fs, and the base of fs on it.
+func underlyingMemory(fs billy.Basic) (*Memory, string, error) {
+	base := string(separator)
+	for {
+		switch f := fs.(type) {
+		case *Memory:
+			return f, base, nil
+		case *chroot.ChrootHelper:
+			base = f.Join(f.Root(), base)
+			fs = f.Underlying()
+		case *polyfill.Polyfill:
+			fs = f.Underlying()
+		default:
+			return nil, "", billy.ErrNotSupported
+		}
+	}
+}
+
+// snapshot returns a copy of the tree of the files, sharing their contents.
+// The files linked keep sharing the same content in the copy.
+func (s *storage) snapshot() *storage {
+	return &storage{root: s.root.snapshot(make(map[*content]*content))}
+}
+
+func (f *file) snapshot(contents map[*content]*content) *file {
+	c, ok := contents[f.content]
+	if !ok {
+		c = f.content.snapshot()
+		contents[f.content] = c
+	}
+
+	cp := &file{name: f.name, content: c, mode: f.mode, flag: f.flag}
+	if f.children != nil {
+		cp.children = make(map[string]*file, len(f.children))
+		for name, child := range f.children {
+			cp.children[name] = child.snapshot(contents)
+		}
+	}
+
+	return cp
+}
+
+// snapshot is a read-only Memory.
+type snapshot struct {
+	m *Memory
+}
+
+func (fs *snapshot) Create(filename string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *snapshot) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *snapshot) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+		return nil, billy.ErrReadOnly
+	}
+
+	return fs.m.OpenFile(filename, flag, perm)
+}
+
+func (fs *snapshot) Stat(filename string) (os.FileInfo, error) {
+	return fs.m.Stat(filename)
+}
+
+func (fs *snapshot) Lstat(filename string) (os.FileInfo, error) {
+	return fs.m.Lstat(filename)
+}
+
+func (fs *snapshot) ReadDir(path string) ([]os.FileInfo, error) {
+	return fs.m.ReadDir(path)
+}
+
+// ReadDirIter implements the DirIterator interface.
+func (fs *snapshot) ReadDirIter(path string) (billy.DirIter, error) {
+	return fs.m.ReadDirIter(path)
+}
+
+func (fs *snapshot) Readlink(link string) (string, error) {
+	return fs.m.Readlink(link)
+}
+
+func (fs *snapshot) Join(elem ...string) string {
+	return fs.m.Join(elem...)
+}
+
+func (fs *snapshot) MkdirAll(filename string, perm os.FileMode) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *snapshot) TempFile(dir, prefix string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *snapshot) TempDir(dir, prefix string) (string, error) {
+	return "", billy.ErrReadOnly
+}
+
+func (fs *snapshot) Rename(from, to string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *snapshot) Remove(filename string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *snapshot) Symlink(target, link string) error {
+	return billy.ErrReadOnly
+}
+
+// Capabilities implements the Capable interface.
+func (fs *snapshot) Capabilities() billy.Capability {
+	return billy.ReadCapability | billy.SeekCapability
+}
+
+// Describe implements the Describer interface.
+func (fs *snapshot) Describe() billy.Description {
+	return fs.m.Describe()
+}
+
+// Action is the kind of change of a path between two filesystems.
+type Action int
+
+const (
+	// Added is a path missing in the first filesystem.
+	Added Action = iota + 1
+	// Modified is a path whose type, permissions or content differ.
+	Modified
+	// Removed is a path missing in the second filesystem.
+	Removed
+)
+
+func (a Action) String() string {
+	switch a {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a path changed between two filesystems.
+type Change struct {
+	Action Action
+	Path   string
+}
+
+// Diff returns the paths added, modified and removed from a to b, sorted by
+// path. The content of the directories isn't compared, every changed path in
+// them is returned, and the symbolic links are compared by their targets. It
+// works with any filesystem, typically a snapshot and its filesystem.
+func Diff(a, b billy.Filesystem) ([]Change, error) {
+	before, err := entries(a)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := entries(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for path, fi := range after {
+		prev, ok := before[path]
+		if !ok {
+			changes = append(changes, Change{Added, path})
+			continue
+		}
+
+		equal, err := equalEntries(a, b, path, prev, fi)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equal {
+			changes = append(changes, Change{Modified, path})
+		}
+	}
+
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, Change{Removed, path})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+// entries returns the FileInfo of every path of fs, without its root.
+func entries(fs billy.Filesystem) (map[string]os.FileInfo, error) {
+	m := make(map[string]os.FileInfo)
+	err := util.Walk(fs, "", func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != "" {
+			m[path] = fi
+		}
+
+		return nil
+	})
+
+	return m, err
+}
+
+func equalEntries(a, b billy.Filesystem, path string, fa, fb os.FileInfo) (bool, error) {
+	if fa.Mode() != fb.Mode() {
+		return false, nil
+	}
+
+	switch {
+	case fa.IsDir():
+		return true, nil
+	case isSymlink(fa.Mode()):
+		ta, err := a.Readlink(path)
+		if err != nil {
+			return false, err
+		}
+
+		tb, err := b.Readlink(path)
+		if err != nil {
+			return false, err
+		}
+
+		return ta == tb, nil
+	}
+
+	if fa.Size() != fb.Size() {
+		return false, nil
+	}
+
+	ca, err := readFile(a, path)
+	if err != nil {
+		return false, err
+	}
+
+	cb, err := readFile(b, path)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(ca, cb), nil
+}
+
+func readFile(fs billy.Basic, filename string) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
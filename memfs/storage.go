@@ -6,37 +6,58 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-billy.v4"
 )
 
+// storage is a tree of files, where every directory holds its children, so
+// the cost of the operations is proportional to the depth of the path and not
+// to the total number of files.
 type storage struct {
-	files    map[string]*file
-	children map[string]map[string]*file
+	root *file
 }
 
 func newStorage() *storage {
 	return &storage{
-		files:    make(map[string]*file, 0),
-		children: make(map[string]map[string]*file, 0),
+		root: &file{
+			name:     string(separator),
+			content:  &content{name: string(separator)},
+			mode:     os.ModeDir | 0755,
+			children: make(map[string]*file),
+		},
 	}
 }
 
 func (s *storage) Has(path string) bool {
-	path = clean(path)
-
-	_, ok := s.files[path]
+	_, ok := s.Get(path)
 	return ok
 }
 
 func (s *storage) New(path string, mode os.FileMode, flag int) (*file, error) {
 	path = clean(path)
-	if s.Has(path) {
-		if !s.MustGet(path).mode.IsDir() {
-			return nil, fmt.Errorf("file already exists %q", path)
+	if f, ok := s.Get(path); ok {
+		if !f.mode.IsDir() {
+			return nil, billy.ErrExist
 		}
 
 		return nil, nil
 	}
 
+	// the missing parents of a file are created as os.MkdirAll does, the
+	// ones of a directory with its permissions.
+	parentMode := mode
+	if !mode.IsDir() {
+		parentMode = 0755
+	}
+
+	parent, err := s.createParent(path, parentMode)
+	if err != nil {
+		return nil, err
+	}
+
 	name := filepath.Base(path)
 
 	f := &file{
@@ -46,38 +67,59 @@ func (s *storage) New(path string, mode os.FileMode, flag int) (*file, error) {
 		flag:    flag,
 	}
 
-	s.files[path] = f
-	s.createParent(path, mode, f)
+	if mode.IsDir() {
+		f.children = make(map[string]*file)
+	}
+
+	parent.children[name] = f
 	return f, nil
 }
 
-func (s *storage) createParent(path string, mode os.FileMode, f *file) error {
-	base := filepath.Dir(path)
-	base = clean(base)
-	if f.Name() == string(separator) {
-		return nil
-	}
+// createParent returns the parent directory of path, creating it and any of
+// its missing parents with the given mode.
+func (s *storage) createParent(path string, mode os.FileMode) (*file, error) {
+	dir := s.root
+	elems := split(filepath.Dir(path))
+	for _, name := range elems {
+		child, ok := dir.children[name]
+		if !ok {
+			child = &file{
+				name:     name,
+				content:  &content{name: name},
+				mode:     mode.Perm() | os.ModeDir,
+				children: make(map[string]*file),
+			}
+
+			dir.children[name] = child
+		}
 
-	if _, err := s.New(base, mode.Perm()|os.ModeDir, 0); err != nil {
-		return err
-	}
+		if !child.mode.IsDir() {
+			return nil, billy.ErrNotDir
+		}
 
-	if _, ok := s.children[base]; !ok {
-		s.children[base] = make(map[string]*file, 0)
+		dir = child
 	}
 
-	s.children[base][f.Name()] = f
-	return nil
+	return dir, nil
 }
 
 func (s *storage) Children(path string) []*file {
-	path = clean(path)
+	f, ok := s.Get(path)
+	if !ok {
+		return nil
+	}
+
+	return f.sortedChildren()
+}
 
-	l := make([]*file, 0)
-	for _, f := range s.children[path] {
-		l = append(l, f)
+// sortedChildren returns the children of the directory sorted by name.
+func (f *file) sortedChildren() []*file {
+	l := make([]*file, 0, len(f.children))
+	for _, child := range f.children {
+		l = append(l, child)
 	}
 
+	sort.Slice(l, func(i, j int) bool { return l[i].name < l[j].name })
 	return l
 }
 
@@ -91,60 +133,107 @@ func (s *storage) MustGet(path string) *file {
 }
 
 func (s *storage) Get(path string) (*file, bool) {
-	path = clean(path)
-	if !s.Has(path) {
-		return nil, false
+	f := s.root
+	for _, name := range split(path) {
+		child, ok := f.children[name]
+		if !ok {
+			return nil, false
+		}
+
+		f = child
 	}
 
-	file, ok := s.files[path]
-	return file, ok
+	return f, true
 }
 
+// Rename moves the file, or the whole directory tree, at from to the path to.
+// As os.Rename does, a file replaces a file, and a directory replaces an empty
+// directory, any other existing destination is an error.
 func (s *storage) Rename(from, to string) error {
 	from = clean(from)
 	to = clean(to)
 
-	if !s.Has(from) {
+	f, ok := s.Get(from)
+	if !ok {
 		return os.ErrNotExist
 	}
 
-	move := [][2]string{{from, to}}
+	if f == s.root {
+		return os.ErrInvalid
+	}
 
-	for pathFrom := range s.files {
-		if pathFrom == from || !filepath.HasPrefix(pathFrom, from) {
-			continue
-		}
+	if from == to {
+		return nil
+	}
 
-		rel, _ := filepath.Rel(from, pathFrom)
-		pathTo := filepath.Join(to, rel)
+	if strings.HasPrefix(to, from+string(separator)) {
+		return os.ErrInvalid
+	}
 
-		move = append(move, [2]string{pathFrom, pathTo})
+	if err := f.canReplace(s.Get(to)); err != nil {
+		return err
 	}
 
-	for _, ops := range move {
-		from := ops[0]
-		to := ops[1]
+	parent, err := s.createParent(to, 0755)
+	if err != nil {
+		return err
+	}
 
-		if err := s.move(from, to); err != nil {
-			return err
-		}
+	delete(s.MustGet(filepath.Dir(from)).children, f.name)
+
+	f.name = filepath.Base(to)
+	parent.children[f.name] = f
+	return nil
+}
+
+// canReplace returns an error if f can't replace the file dst, if it exists.
+func (f *file) canReplace(dst *file, exists bool) error {
+	switch {
+	case !exists:
+		return nil
+	case !dst.mode.IsDir() && f.mode.IsDir():
+		return billy.ErrNotDir
+	case dst.mode.IsDir() && !f.mode.IsDir():
+		return billy.ErrIsDir
+	case dst.mode.IsDir() && len(dst.children) != 0:
+		return billy.ErrNotEmpty
 	}
 
 	return nil
 }
 
-func (s *storage) move(from, to string) error {
-	s.files[to] = s.files[from]
-	s.files[to].name = filepath.Base(to)
-	s.children[to] = s.children[from]
+// Link adds a new entry at to for the file at from, sharing its content.
+func (s *storage) Link(from, to string) error {
+	from = clean(from)
+	to = clean(to)
+
+	f, ok := s.Get(from)
+	if !ok {
+		return os.ErrNotExist
+	}
+
+	if f.mode.IsDir() {
+		return os.ErrPermission
+	}
+
+	if s.Has(to) {
+		return os.ErrExist
+	}
+
+	parent, err := s.createParent(to, 0755)
+	if err != nil {
+		return err
+	}
 
-	defer func() {
-		delete(s.children, from)
-		delete(s.files, from)
-		delete(s.children[filepath.Dir(from)], filepath.Base(from))
-	}()
+	name := filepath.Base(to)
+	parent.children[name] = &file{
+		name:    name,
+		content: f.content,
+		mode:    f.mode,
+		flag:    f.flag,
+	}
 
-	return s.createParent(to, 0644, s.files[to])
+	return nil
 }
 
 func (s *storage) Remove(path string) error {
@@ -155,15 +244,15 @@ func (s *storage) Remove(path string) error {
 		return os.ErrNotExist
 	}
 
-	if f.mode.IsDir() && len(s.children[path]) != 0 {
-		return fmt.Errorf("dir: %s contains files", path)
+	if f == s.root {
+		return os.ErrInvalid
 	}
 
-	base, file := filepath.Split(path)
-	base = filepath.Clean(base)
+	if f.mode.IsDir() && len(f.children) != 0 {
+		return billy.ErrNotEmpty
+	}
 
-	delete(s.children[base], file)
-	delete(s.files, path)
+	delete(s.MustGet(filepath.Dir(path)).children, f.name)
 	return nil
 }
 
@@ -171,12 +260,63 @@ func clean(path string) string {
 	return filepath.Clean(filepath.FromSlash(path))
 }
 
+// split returns the elements of path, being empty for the root.
+func split(path string) []string {
+	path = strings.Trim(clean(path), string(separator))
+	if path == "" || path == "." {
+		return nil
+	}
+
+	return strings.Split(path, string(separator))
+}
+
 type content struct {
 	name  string
 	bytes []byte
+	m     sync.RWMutex
+
+	// xattrs are the extended attributes, their values are never modified.
+	xattrs map[string][]byte
+
+	// shared is set when bytes and xattrs are shared with a snapshot, so
+	// they're copied before being written.
+	shared bool
+}
+
+// snapshot returns a copy of the content sharing its bytes, until any of
+// both is written.
+func (c *content) snapshot() *content {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.shared = true
+	return &content{name: c.name, bytes: c.bytes, xattrs: c.xattrs, shared: true}
+}
+
+// own copies the bytes and the xattrs if they're shared, it must be called
+// with the lock held, before writing them.
+func (c *content) own() {
+	if !c.shared {
+		return
+	}
+
+	c.bytes = append([]byte(nil), c.bytes...)
+	if c.xattrs != nil {
+		xattrs := make(map[string][]byte, len(c.xattrs))
+		for attr, value := range c.xattrs {
+			xattrs[attr] = value
+		}
+
+		c.xattrs = xattrs
+	}
+
+	c.shared = false
 }
 
 func (c *content) WriteAt(p []byte, off int64) (int, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
 	if off < 0 {
 		return 0, &os.PathError{
 			Op:   "writeat",
@@ -185,6 +325,7 @@ func (c *content) WriteAt(p []byte, off int64) (int, error) {
 		}
 	}
 
+	c.own()
 	prev := len(c.bytes)
 
 	diff := int(off) - prev
@@ -201,6 +342,9 @@ func (c *content) WriteAt(p []byte, off int64) (int, error) {
 }
 
 func (c *content) ReadAt(b []byte, off int64) (n int, err error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
 	if off < 0 {
 		return 0, &os.PathError{
 			Op:   "readat",
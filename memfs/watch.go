@@ -0,0 +1,125 @@
+package memfs
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// bus delivers the events of the changes done to a Memory filesystem to the
+// watches matching them. Publishing never blocks, the events are queued by
+// each watch until they are received.
+type bus struct {
+	m       sync.Mutex
+	watches map[*watch]bool
+}
+
+// publish delivers an event to the watches of path.
+func (b *bus) publish(path string, op billy.Op) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	path = clean(path)
+	for w := range b.watches {
+		if w.match(path) {
+			w.push(billy.Event{Path: path, Op: op})
+		}
+	}
+}
+
+// watch starts a new watch of path.
+func (b *bus) watch(path string, recursive bool) (<-chan billy.Event, billy.CancelFunc) {
+	w := &watch{
+		path:      clean(path),
+		recursive: recursive,
+		events:    make(chan billy.Event),
+		ready:     make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+
+	b.m.Lock()
+	if b.watches == nil {
+		b.watches = make(map[*watch]bool)
+	}
+
+	b.watches[w] = true
+	b.m.Unlock()
+
+	go w.deliver()
+
+	var once sync.Once
+	return w.events, func() {
+		once.Do(func() {
+			b.m.Lock()
+			delete(b.watches, w)
+			b.m.Unlock()
+
+			close(w.done)
+		})
+	}
+}
+
+type watch struct {
+	path      string
+	recursive bool
+	events    chan billy.Event
+	ready     chan struct{}
+	done      chan struct{}
+
+	m     sync.Mutex
+	queue []billy.Event
+}
+
+// match returns true if the events of path are delivered to the watch, being
+// the path watched, one of its children or, if recursive, a descendant.
+func (w *watch) match(path string) bool {
+	if path == w.path || filepath.Dir(path) == w.path {
+		return true
+	}
+
+	prefix := w.path
+	if !strings.HasSuffix(prefix, string(separator)) {
+		prefix += string(separator)
+	}
+
+	return w.recursive && strings.HasPrefix(path, prefix)
+}
+
+func (w *watch) push(e billy.Event) {
+	w.m.Lock()
+	w.queue = append(w.queue, e)
+	w.m.Unlock()
+
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the queued events, in order, until the watch is canceled.
+func (w *watch) deliver() {
+	defer close(w.events)
+
+	for {
+		select {
+		case <-w.ready:
+		case <-w.done:
+			return
+		}
+
+		w.m.Lock()
+		queue := w.queue
+		w.queue = nil
+		w.m.Unlock()
+
+		for _, e := range queue {
+			select {
+			case w.events <- e:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package memfs
+
+import (
+	"os"
+	"sort"
+
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// GetXattr implements the Xattrer interface. The attributes are kept in
+// memory, with any name, and shared by the files linked with Link.
+func (fs *Memory) GetXattr(name, attr string) ([]byte, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := fs.resolveXattr(name, permRead)
+	if err != nil {
+		return nil, &os.PathError{Op: "getxattr", Path: name, Err: err}
+	}
+
+	return f.content.getXattr(name, attr)
+}
+
+// SetXattr implements the Xattrer interface.
+func (fs *Memory) SetXattr(name, attr string, value []byte) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := fs.resolveXattr(name, permWrite)
+	if err != nil {
+		return &os.PathError{Op: "setxattr", Path: name, Err: err}
+	}
+
+	f.content.setXattr(attr, value)
+	fs.notify(billy.Chmod, name)
+	return nil
+}
+
+// ListXattr implements the Xattrer interface, the names are sorted.
+func (fs *Memory) ListXattr(name string) ([]string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := fs.resolveXattr(name, permRead)
+	if err != nil {
+		return nil, &os.PathError{Op: "listxattr", Path: name, Err: err}
+	}
+
+	return f.content.listXattr(), nil
+}
+
+// RemoveXattr implements the Xattrer interface.
+func (fs *Memory) RemoveXattr(name, attr string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	f, err := fs.resolveXattr(name, permWrite)
+	if err != nil {
+		return &os.PathError{Op: "removexattr", Path: name, Err: err}
+	}
+
+	if err := f.content.removeXattr(name, attr); err != nil {
+		return err
+	}
+
+	fs.notify(billy.Chmod, name)
+	return nil
+}
+
+// resolveXattr returns the file of name, following the links, if its
+// attributes can be accessed with perm.
+func (fs *Memory) resolveXattr(name string, perm os.FileMode) (*file, error) {
+	f, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fs.allowed(f, perm) {
+		return nil, os.ErrPermission
+	}
+
+	return f, nil
+}
+
+func (c *content) getXattr(name, attr string) ([]byte, error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	value, ok := c.xattrs[attr]
+	if !ok {
+		return nil, &os.PathError{Op: "getxattr", Path: name, Err: billy.ErrNoXattr}
+	}
+
+	return append([]byte(nil), value...), nil
+}
+
+func (c *content) setXattr(attr string, value []byte) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.own()
+	if c.xattrs == nil {
+		c.xattrs = make(map[string][]byte)
+	}
+
+	c.xattrs[attr] = append([]byte(nil), value...)
+}
+
+func (c *content) listXattr() []string {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	attrs := make([]string, 0, len(c.xattrs))
+	for attr := range c.xattrs {
+		attrs = append(attrs, attr)
+	}
+
+	sort.Strings(attrs)
+	return attrs
+}
+
+func (c *content) removeXattr(name, attr string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if _, ok := c.xattrs[attr]; !ok {
+		return &os.PathError{Op: "removexattr", Path: name, Err: billy.ErrNoXattr}
+	}
+
+	c.own()
+	delete(c.xattrs, attr)
+	return nil
+}
+
+// GetXattr implements the Xattrer interface.
+func (fs *snapshot) GetXattr(name, attr string) ([]byte, error) {
+	return fs.m.GetXattr(name, attr)
+}
+
+// SetXattr implements the Xattrer interface, failing with
+// billy.ErrReadOnly.
+func (fs *snapshot) SetXattr(name, attr string, value []byte) error {
+	return billy.ErrReadOnly
+}
+
+// ListXattr implements the Xattrer interface.
+func (fs *snapshot) ListXattr(name string) ([]string, error) {
+	return fs.m.ListXattr(name)
+}
+
+// RemoveXattr implements the Xattrer interface, failing with
+// billy.ErrReadOnly.
+func (fs *snapshot) RemoveXattr(name, attr string) error {
+	return billy.ErrReadOnly
+}
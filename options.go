@@ -0,0 +1,123 @@
+package billy
+
+import "os"
+
+// OpenOptions holds the per-file settings requested through OpenFileOpt. The
+// settings are hints, filesystems apply the ones they support and ignore the
+// rest.
+type OpenOptions struct {
+	// BufferSize is the size of the buffer used for I/O, 0 means the
+	// filesystem default.
+	BufferSize int
+	// Mmap requests the file to be memory mapped.
+	Mmap bool
+	// DirectIO requests the file to bypass the operating system caches.
+	DirectIO bool
+	// Snapshot requests the file to be isolated from the writes done after
+	// opening it, reading always the content it had at open time.
+	Snapshot bool
+	// Filters are applied, in order, to the opened file.
+	Filters []ContentFilter
+
+	values map[interface{}]interface{}
+}
+
+// Value returns the value associated with key by WithValue, or nil.
+func (o *OpenOptions) Value(key interface{}) interface{} {
+	return o.values[key]
+}
+
+// ContentFilter wraps an opened file, allowing to transform its content, e.g.
+// compressing or decompressing it.
+type ContentFilter func(File) (File, error)
+
+// OpenOption configures an OpenOptions.
+type OpenOption func(*OpenOptions)
+
+// WithBufferSize sets the size of the buffer used for I/O.
+func WithBufferSize(size int) OpenOption {
+	return func(o *OpenOptions) { o.BufferSize = size }
+}
+
+// WithMmap requests the file to be memory mapped.
+func WithMmap() OpenOption {
+	return func(o *OpenOptions) { o.Mmap = true }
+}
+
+// WithDirectIO requests the file to bypass the operating system caches.
+func WithDirectIO() OpenOption {
+	return func(o *OpenOptions) { o.DirectIO = true }
+}
+
+// WithSnapshot requests the file to be isolated from later writes.
+func WithSnapshot() OpenOption {
+	return func(o *OpenOptions) { o.Snapshot = true }
+}
+
+// WithFilter appends a ContentFilter to be applied to the opened file.
+func WithFilter(f ContentFilter) OpenOption {
+	return func(o *OpenOptions) { o.Filters = append(o.Filters, f) }
+}
+
+// WithValue associates a value with key, it allows to pass settings specific
+// to a filesystem without a dedicated option. Key should be of a type defined
+// by the filesystem reading it, to avoid collisions.
+func WithValue(key, value interface{}) OpenOption {
+	return func(o *OpenOptions) {
+		if o.values == nil {
+			o.values = make(map[interface{}]interface{})
+		}
+
+		o.values[key] = value
+	}
+}
+
+// NewOpenOptions returns the OpenOptions resulting of applying opts.
+func NewOpenOptions(opts ...OpenOption) *OpenOptions {
+	o := &OpenOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// ApplyFilters applies the Filters to f, closing it if any of them fails.
+func (o *OpenOptions) ApplyFilters(f File) (File, error) {
+	for _, filter := range o.Filters {
+		filtered, err := filter(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		f = filtered
+	}
+
+	return f, nil
+}
+
+// OptionOpener interface can open files with per-file options. Wrappers
+// implementing it must forward all the options, including the unknown ones,
+// to the underlying filesystem, and must not apply the Filters themselves.
+type OptionOpener interface {
+	// OpenFileOpt is like OpenFile, accepting per-file options. It's
+	// responsible for applying the Filters to the opened file.
+	OpenFileOpt(filename string, flag int, perm os.FileMode, opts ...OpenOption) (File, error)
+}
+
+// OpenFileOpt opens the named file with the given per-file options. If the FS
+// does not implement OptionOpener interface, the file is opened with
+// OpenFile, ignoring all the options but the Filters.
+func OpenFileOpt(fs Basic, filename string, flag int, perm os.FileMode, opts ...OpenOption) (File, error) {
+	if o, ok := fs.(OptionOpener); ok {
+		return o.OpenFileOpt(filename, flag, perm, opts...)
+	}
+
+	f, err := fs.OpenFile(filename, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOpenOptions(opts...).ApplyFilters(f)
+}
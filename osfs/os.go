@@ -2,12 +2,14 @@
 package osfs // import "gopkg.in/src-d/go-billy.v4/osfs"
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sync"
 
 	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/cancel"
 	"gopkg.in/src-d/go-billy.v4/helper/chroot"
 )
 
@@ -19,11 +21,38 @@ const (
 // OS is a filesystem based on the os filesystem.
 type OS struct{}
 
-// New returns a new OS filesystem.
+// New returns a new OS filesystem based on baseDir. The paths are only
+// checked lexically, the symbolic links found are followed even if they point
+// outside of baseDir, use NewRooted to confine the filesystem to it.
 func New(baseDir string) billy.Filesystem {
 	return chroot.New(&OS{}, baseDir)
 }
 
+// NewTemp returns a new Rooted filesystem confined to a new temporary
+// directory, named with the given prefix, and a function removing the
+// directory and all its content, to be called once the filesystem isn't used
+// anymore.
+func NewTemp(prefix string) (billy.Filesystem, func() error, error) {
+	dir, err := ioutil.TempDir("", prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fs, err := newRooted(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	cleanup := func() error {
+		once.Do(func() { fs.root.close() })
+		return os.RemoveAll(dir)
+	}
+
+	return chroot.New(fs, string(filepath.Separator)), cleanup, nil
+}
+
 func (fs *OS) Create(filename string) (billy.File, error) {
 	return fs.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, defaultCreateMode)
 }
@@ -67,6 +96,29 @@ func (fs *OS) ReadDir(path string) ([]os.FileInfo, error) {
 	return s, nil
 }
 
+// ReadDirIter implements the DirIterator interface, reading the entries from
+// the OS as they're requested.
+func (fs *OS) ReadDirIter(path string) (billy.DirIter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dirIter{f}, nil
+}
+
+type dirIter struct {
+	f *os.File
+}
+
+func (i *dirIter) Next(n int) ([]os.FileInfo, error) {
+	return i.f.Readdir(n)
+}
+
+func (i *dirIter) Close() error {
+	return i.f.Close()
+}
+
 func (fs *OS) Rename(from, to string) error {
 	if err := fs.createDir(to); err != nil {
 		return err
@@ -103,6 +155,14 @@ func (fs *OS) TempFile(dir, prefix string) (billy.File, error) {
 	return &file{File: f}, nil
 }
 
+func (fs *OS) TempDir(dir, prefix string) (string, error) {
+	if err := fs.createDir(dir + string(os.PathSeparator)); err != nil {
+		return "", err
+	}
+
+	return ioutil.TempDir(dir, prefix)
+}
+
 func (fs *OS) Join(elem ...string) string {
 	return filepath.Join(elem...)
 }
@@ -123,13 +183,37 @@ func (fs *OS) Symlink(target, link string) error {
 	return os.Symlink(target, link)
 }
 
+// Link implements the Linker interface.
+func (fs *OS) Link(oldname, newname string) error {
+	if err := fs.createDir(newname); err != nil {
+		return err
+	}
+
+	return os.Link(oldname, newname)
+}
+
 func (fs *OS) Readlink(link string) (string, error) {
 	return os.Readlink(link)
 }
 
-// Capabilities implements the Capable interface.
+// WithContext implements the Contexter interface. The operations of the OS
+// can't be interrupted, ctx is checked before each of them.
+func (fs *OS) WithContext(ctx context.Context) billy.Filesystem {
+	return cancel.New(fs, ctx)
+}
+
+// Capabilities implements the Capable interface. The permissions are enforced
+// by the OS, but not for the privileged users, so PermissionCapability isn't
+// declared.
 func (fs *OS) Capabilities() billy.Capability {
-	return billy.DefaultCapabilities
+	return billy.DefaultCapabilities | billy.SymlinkCapability |
+		billy.CreateExclusiveCapability
+}
+
+// Describe implements the Describer interface. It returns the defaults of the
+// running platform, a given volume may be configured differently.
+func (fs *OS) Describe() billy.Description {
+	return description
 }
 
 // file is a wrapper for an os.File which adds support for file locking.
@@ -3,7 +3,10 @@
 package osfs
 
 import (
+	"runtime"
+
 	"golang.org/x/sys/unix"
+	"gopkg.in/src-d/go-billy.v4"
 )
 
 func (f *file) Lock() error {
@@ -19,3 +22,20 @@ func (f *file) Unlock() error {
 
 	return unix.Flock(int(f.File.Fd()), unix.LOCK_UN)
 }
+
+var description = func() billy.Description {
+	d := billy.Description{
+		CaseSensitive:  true,
+		CasePreserving: true,
+		MaxPathLength:  4096,
+		MaxNameLength:  255,
+	}
+
+	if runtime.GOOS == "darwin" {
+		d.CaseSensitive = false
+		d.Normalization = billy.NFD
+		d.MaxPathLength = 1024
+	}
+
+	return d
+}()
@@ -1,13 +1,17 @@
 package osfs
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
 
 	. "gopkg.in/check.v1"
 )
@@ -44,5 +48,114 @@ func (s *OSSuite) TestCapabilities(c *C) {
 	c.Assert(ok, Equals, true)
 
 	caps := billy.Capabilities(s.FS)
-	c.Assert(caps, Equals, billy.AllCapabilities)
+	c.Assert(caps, Equals, billy.AllCapabilities&^billy.PermissionCapability)
+}
+
+func (s *OSSuite) TestDescribe(c *C) {
+	_, ok := s.FS.(billy.Describer)
+	c.Assert(ok, Equals, true)
+
+	d := billy.Describe(s.FS)
+	c.Assert(d.CasePreserving, Equals, true)
+	c.Assert(d.MaxNameLength, Equals, 255)
+	c.Assert(d.MaxPathLength, Equals, description.MaxPathLength-len(s.path)-1)
+}
+
+func (s *OSSuite) TestLink(c *C) {
+	err := ioutil.WriteFile(filepath.Join(s.path, "foo"), []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	c.Assert(billy.Link(s.FS, "foo", "dir/bar"), IsNil)
+
+	fi, err := os.Stat(filepath.Join(s.path, "foo"))
+	c.Assert(err, IsNil)
+	link, err := os.Stat(filepath.Join(s.path, "dir", "bar"))
+	c.Assert(err, IsNil)
+	c.Assert(os.SameFile(fi, link), Equals, true)
+}
+
+func (s *OSSuite) TestWatch(c *C) {
+	events, cancel, err := s.FS.(billy.Watcher).Watch("/", true)
+	c.Assert(err, IsNil)
+	defer cancel()
+
+	c.Assert(s.FS.MkdirAll("foo", 0755), IsNil)
+	c.Assert(nextEvent(c, events), DeepEquals, billy.Event{Path: "foo", Op: billy.Create})
+
+	f, err := s.FS.Create("foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+	c.Assert(nextEvent(c, events), DeepEquals, billy.Event{
+		Path: filepath.Join("foo", "bar"), Op: billy.Create,
+	})
+
+	cancel()
+	for range events {
+	}
+}
+
+func nextEvent(c *C, events <-chan billy.Event) billy.Event {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for an event")
+		return billy.Event{}
+	}
+}
+
+func (s *OSSuite) TestWithContext(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fs := billy.WithContext(s.FS, ctx)
+
+	c.Assert(util.WriteFile(fs, "foo", []byte("foo"), 0644), IsNil)
+	cancel()
+
+	_, err := fs.Open("foo")
+	c.Assert(err, Equals, context.Canceled)
+
+	_, err = s.FS.Open("foo")
+	c.Assert(err, IsNil)
+}
+
+func (s *OSSuite) TestNewTemp(c *C) {
+	prefix := "go-billy-osfs-temp" + strconv.Itoa(os.Getpid()) + "-"
+	fs, cleanup, err := NewTemp(prefix)
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(fs, "foo/bar", []byte("bar"), 0644), IsNil)
+
+	dirs, err := filepath.Glob(filepath.Join(os.TempDir(), prefix+"*"))
+	c.Assert(err, IsNil)
+	c.Assert(dirs, HasLen, 1)
+
+	_, err = os.Stat(filepath.Join(dirs[0], "foo", "bar"))
+	c.Assert(err, IsNil)
+
+	c.Assert(cleanup(), IsNil)
+
+	_, err = os.Stat(dirs[0])
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	c.Assert(cleanup(), IsNil)
+}
+
+func (s *OSSuite) TestNewTempSymlinkOutside(c *C) {
+	prefix := "go-billy-osfs-temp" + strconv.Itoa(os.Getpid()) + "-"
+	fs, cleanup, err := NewTemp(prefix)
+	c.Assert(err, IsNil)
+	defer cleanup()
+
+	dirs, err := filepath.Glob(filepath.Join(os.TempDir(), prefix+"*"))
+	c.Assert(err, IsNil)
+	c.Assert(dirs, HasLen, 1)
+
+	err = ioutil.WriteFile(filepath.Join(s.path, "secret"), []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = os.Symlink(s.path, filepath.Join(dirs[0], "link"))
+	c.Assert(err, IsNil)
+
+	_, err = fs.Open("link/secret")
+	c.Assert(os.IsNotExist(err), Equals, true)
 }
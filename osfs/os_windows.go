@@ -8,6 +8,7 @@ import (
 	"unsafe"
 
 	"golang.org/x/sys/windows"
+	"gopkg.in/src-d/go-billy.v4"
 )
 
 type fileInfo struct {
@@ -19,6 +20,13 @@ func (fi *fileInfo) Name() string {
 	return fi.name
 }
 
+var description = billy.Description{
+	CaseSensitive:  false,
+	CasePreserving: true,
+	MaxPathLength:  260,
+	MaxNameLength:  255,
+}
+
 var (
 	kernel32DLL    = windows.NewLazySystemDLL("kernel32.dll")
 	lockFileExProc = kernel32DLL.NewProc("LockFileEx")
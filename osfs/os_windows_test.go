@@ -0,0 +1,52 @@
+// +build windows
+
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *OSSuite) TestVolumeName(c *C) {
+	c.Assert(filepath.VolumeName(s.path), Not(Equals), "")
+
+	_, err := s.FS.Create(filepath.Join(s.path, "foo"))
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+
+	_, err = s.FS.Stat(filepath.VolumeName(s.path) + "foo")
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+
+	err = util.WriteFile(s.FS, `\foo\bar`, nil, 0644)
+	c.Assert(err, IsNil)
+
+	_, err = os.Stat(filepath.Join(s.path, "foo", "bar"))
+	c.Assert(err, IsNil)
+}
+
+func (s *OSSuite) TestUNCBase(c *C) {
+	volume := filepath.VolumeName(s.path)
+	if len(volume) != 2 {
+		c.Skip("the temporary directory isn't on a drive")
+	}
+
+	// the administrative share of the drive, as \\localhost\C$.
+	unc := `\\localhost\` + volume[:1] + "$" + s.path[len(volume):]
+	if _, err := os.Stat(unc); err != nil {
+		c.Skip("the administrative shares aren't available")
+	}
+
+	fs := New(unc)
+	err := util.WriteFile(fs, "foo/bar", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	_, err = os.Stat(filepath.Join(s.path, "foo", "bar"))
+	c.Assert(err, IsNil)
+
+	_, err = fs.Stat(unc)
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+}
@@ -0,0 +1,93 @@
+// +build linux darwin freebsd
+
+package osfs
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// GetXattr implements the Xattrer interface, using the extended attributes
+// of the OS. Their names require a namespace, "user." for the ones of the
+// users, except on darwin.
+func (fs *OS) GetXattr(name, attr string) ([]byte, error) {
+	for {
+		size, err := unix.Getxattr(name, attr, nil)
+		if err != nil {
+			return nil, xattrError("getxattr", name, err)
+		}
+
+		value := make([]byte, size)
+		n, err := unix.Getxattr(name, attr, value)
+		if err == unix.ERANGE {
+			// the attribute grew since its size was read
+			continue
+		}
+
+		if err != nil {
+			return nil, xattrError("getxattr", name, err)
+		}
+
+		return value[:n], nil
+	}
+}
+
+// SetXattr implements the Xattrer interface.
+func (fs *OS) SetXattr(name, attr string, value []byte) error {
+	if err := unix.Setxattr(name, attr, value, 0); err != nil {
+		return xattrError("setxattr", name, err)
+	}
+
+	return nil
+}
+
+// ListXattr implements the Xattrer interface.
+func (fs *OS) ListXattr(name string) ([]string, error) {
+	for {
+		size, err := unix.Listxattr(name, nil)
+		if err != nil {
+			return nil, xattrError("listxattr", name, err)
+		}
+
+		list := make([]byte, size)
+		n, err := unix.Listxattr(name, list)
+		if err == unix.ERANGE {
+			continue
+		}
+
+		if err != nil {
+			return nil, xattrError("listxattr", name, err)
+		}
+
+		var attrs []string
+		for _, attr := range strings.Split(string(list[:n]), "\x00") {
+			if attr != "" {
+				attrs = append(attrs, attr)
+			}
+		}
+
+		return attrs, nil
+	}
+}
+
+// RemoveXattr implements the Xattrer interface.
+func (fs *OS) RemoveXattr(name, attr string) error {
+	if err := unix.Removexattr(name, attr); err != nil {
+		return xattrError("removexattr", name, err)
+	}
+
+	return nil
+}
+
+// xattrError wraps err in a *os.PathError, replacing the error of a missing
+// attribute with billy.ErrNoXattr.
+func xattrError(op, name string, err error) error {
+	if err == errNoXattr {
+		err = billy.ErrNoXattr
+	}
+
+	return &os.PathError{Op: op, Path: name, Err: err}
+}
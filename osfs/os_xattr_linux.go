@@ -0,0 +1,6 @@
+package osfs
+
+import "golang.org/x/sys/unix"
+
+// errNoXattr is the error of a missing extended attribute.
+const errNoXattr = unix.ENODATA
@@ -0,0 +1,424 @@
+package osfs
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/cancel"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// Rooted is a filesystem confined to a directory of the os filesystem.
+//
+// Unlike New, which only prevents the names from crossing the base directory,
+// Rooted resolves every path element by itself, following the symbolic links
+// as if the root was the root of the system, so any link pointing outside of
+// it, even if created after NewRooted is called, can't be used to escape.
+// Where available (linux, darwin and freebsd) the path elements are resolved
+// with the *at family of syscalls and opened with O_NOFOLLOW, relative to the
+// directory containing them, which makes the resolution safe from concurrent
+// changes of the tree. On the rest of the platforms the resolution is done by
+// name, and is subject to races with concurrent changes.
+type Rooted struct {
+	root *dir
+}
+
+// NewRooted returns a new OS filesystem confined to the given directory. The
+// directory is resolved, following any symbolic link, and pinned when
+// NewRooted is called, so moving or replacing it afterwards doesn't change
+// the directory accessed.
+func NewRooted(path string) (billy.Filesystem, error) {
+	fs, err := newRooted(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return chroot.New(fs, string(filepath.Separator)), nil
+}
+
+func newRooted(path string) (*Rooted, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := openRoot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rooted{root: root}, nil
+}
+
+func (fs *Rooted) Create(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, defaultCreateMode)
+}
+
+func (fs *Rooted) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *Rooted) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	// with O_EXCL a symbolic link isn't followed, failing as if it was a file.
+	exclusive := flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL
+	d, name, err := fs.resolve(filename, !exclusive, flag&os.O_CREATE != 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fs.release(d)
+	f, err := d.openFile(name, flag, perm)
+	if err != nil {
+		return nil, pathError("open", filename, err)
+	}
+
+	return &file{File: f}, nil
+}
+
+func (fs *Rooted) Stat(filename string) (os.FileInfo, error) {
+	return fs.stat("stat", filename, true)
+}
+
+func (fs *Rooted) Lstat(filename string) (os.FileInfo, error) {
+	return fs.stat("lstat", filename, false)
+}
+
+func (fs *Rooted) stat(op, filename string, follow bool) (os.FileInfo, error) {
+	d, name, err := fs.resolve(filename, follow, false)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fs.release(d)
+	fi, err := d.lstat(name)
+	if err != nil {
+		return nil, pathError(op, filename, err)
+	}
+
+	if base := filepath.Base(filename); fi.Name() != base {
+		fi = &namedFileInfo{FileInfo: fi, name: base}
+	}
+
+	return fi, nil
+}
+
+func (fs *Rooted) ReadDir(path string) ([]os.FileInfo, error) {
+	d, name, err := fs.resolve(path, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fs.release(d)
+	sub, err := d.open(name)
+	if err != nil {
+		return nil, pathError("open", path, err)
+	}
+
+	defer sub.close()
+	l, err := sub.readDir()
+	if err != nil {
+		return nil, pathError("readdirent", path, err)
+	}
+
+	sort.Slice(l, func(i, j int) bool { return l[i].Name() < l[j].Name() })
+	return l, nil
+}
+
+func (fs *Rooted) MkdirAll(path string, perm os.FileMode) error {
+	d, name, err := fs.resolve(path, true, true)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(d)
+	fi, err := d.lstat(name)
+	if err == nil {
+		if fi.IsDir() {
+			return nil
+		}
+
+		return pathError("mkdir", path, syscall.ENOTDIR)
+	}
+
+	if err := d.mkdir(name, defaultDirectoryMode); err != nil {
+		return pathError("mkdir", path, err)
+	}
+
+	return nil
+}
+
+func (fs *Rooted) Rename(from, to string) error {
+	fd, fname, err := fs.resolve(from, false, false)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(fd)
+	td, tname, err := fs.resolve(to, false, true)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(td)
+	if err := fd.rename(fname, td, tname); err != nil {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: err}
+	}
+
+	return nil
+}
+
+func (fs *Rooted) Remove(filename string) error {
+	d, name, err := fs.resolve(filename, false, false)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(d)
+	if err := d.remove(name); err != nil {
+		return pathError("remove", filename, err)
+	}
+
+	return nil
+}
+
+func (fs *Rooted) TempFile(dir, prefix string) (billy.File, error) {
+	return util.TempFile(fs, dir, prefix)
+}
+
+// TempDir creates a new temporary directory, retrying with another name if
+// the chosen one already exists.
+func (fs *Rooted) TempDir(dir, prefix string) (string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	for i := 0; i < 10000; i++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		err := fs.mkdir(name, 0700)
+		if os.IsExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		return name, nil
+	}
+
+	return "", pathError("mkdir", dir, syscall.EEXIST)
+}
+
+// mkdir creates the directory name, failing if it already exists.
+func (fs *Rooted) mkdir(name string, perm os.FileMode) error {
+	d, base, err := fs.resolve(name, false, true)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(d)
+	if err := d.mkdir(base, perm); err != nil {
+		return pathError("mkdir", name, err)
+	}
+
+	return nil
+}
+
+func (fs *Rooted) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (fs *Rooted) Symlink(target, link string) error {
+	d, name, err := fs.resolve(link, false, true)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(d)
+	if err := d.symlink(target, name); err != nil {
+		return &os.LinkError{Op: "symlink", Old: target, New: link, Err: err}
+	}
+
+	return nil
+}
+
+// Link implements the Linker interface.
+func (fs *Rooted) Link(oldname, newname string) error {
+	od, oname, err := fs.resolve(oldname, false, false)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(od)
+	nd, nname, err := fs.resolve(newname, false, true)
+	if err != nil {
+		return err
+	}
+
+	defer fs.release(nd)
+	if err := od.link(oname, nd, nname); err != nil {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+
+	return nil
+}
+
+func (fs *Rooted) Readlink(link string) (string, error) {
+	d, name, err := fs.resolve(link, false, false)
+	if err != nil {
+		return "", err
+	}
+
+	defer fs.release(d)
+	target, err := d.readlink(name)
+	if err != nil {
+		return "", pathError("readlink", link, err)
+	}
+
+	return target, nil
+}
+
+// WithContext implements the Contexter interface. The operations of the OS
+// can't be interrupted, ctx is checked before each of them.
+func (fs *Rooted) WithContext(ctx context.Context) billy.Filesystem {
+	return cancel.New(fs, ctx)
+}
+
+// Capabilities implements the Capable interface.
+func (fs *Rooted) Capabilities() billy.Capability {
+	return billy.DefaultCapabilities | billy.SymlinkCapability |
+		billy.CreateExclusiveCapability
+}
+
+// Describe implements the Describer interface.
+func (fs *Rooted) Describe() billy.Description {
+	return description
+}
+
+// resolve walks the given path from the root, following the symbolic links
+// found on the way as if the root was the root of the system, and returns
+// the directory containing the last element and its name. The last element is
+// only followed when follow is true, and it may not exist. If create is true,
+// the missing directories are created. The returned directory must be
+// released.
+func (fs *Rooted) resolve(path string, follow, create bool) (*dir, string, error) {
+	stack := []*dir{fs.root}
+	fail := func(err error) (*dir, string, error) {
+		for _, d := range stack {
+			fs.release(d)
+		}
+
+		return nil, "", err
+	}
+
+	base := "."
+	parts := billy.SplitPath(path)
+	for links := 0; len(parts) > 0; {
+		part := parts[0]
+		parts = parts[1:]
+		cur := stack[len(stack)-1]
+
+		switch part {
+		case ".":
+			continue
+		case "..":
+			if len(stack) == 1 {
+				return fail(billy.ErrCrossedBoundary)
+			}
+
+			fs.release(cur)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		last := len(parts) == 0
+		fi, err := cur.lstat(part)
+		switch {
+		case os.IsNotExist(err) && last:
+		case os.IsNotExist(err) && create:
+			err := cur.mkdir(part, defaultDirectoryMode)
+			if err != nil && !os.IsExist(err) {
+				return fail(pathError("mkdir", path, err))
+			}
+		case err != nil:
+			return fail(pathError("lstat", path, err))
+		case fi.Mode()&os.ModeSymlink != 0 && (!last || follow):
+			if links++; links > billy.MaxSymlinks {
+				return fail(pathError("lstat", path, syscall.ELOOP))
+			}
+
+			target, err := cur.readlink(part)
+			if err != nil {
+				return fail(pathError("readlink", path, err))
+			}
+
+			if billy.IsAbs(target) {
+				for _, d := range stack[1:] {
+					fs.release(d)
+				}
+
+				stack = stack[:1]
+			}
+
+			// the volume name of the targets on Windows is dropped, they're
+			// resolved from the root as any other absolute target.
+			target = target[len(filepath.VolumeName(target)):]
+			parts = append(billy.SplitPath(target), parts...)
+			continue
+		}
+
+		if last {
+			base = part
+			break
+		}
+
+		d, err := cur.open(part)
+		if err != nil {
+			return fail(pathError("open", path, err))
+		}
+
+		stack = append(stack, d)
+	}
+
+	for _, d := range stack[:len(stack)-1] {
+		fs.release(d)
+	}
+
+	return stack[len(stack)-1], base, nil
+}
+
+func (fs *Rooted) release(d *dir) {
+	if d != fs.root {
+		d.close()
+	}
+}
+
+func pathError(op, path string, err error) error {
+	if _, ok := err.(syscall.Errno); !ok {
+		return err
+	}
+
+	return &os.PathError{Op: op, Path: path, Err: err}
+}
+
+// namedFileInfo overrides the name of an os.FileInfo.
+type namedFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (fi *namedFileInfo) Name() string {
+	return fi.name
+}
@@ -0,0 +1,210 @@
+// +build linux darwin freebsd
+
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// dir is an open directory, used to access its entries without following
+// symbolic links with the *at family of syscalls.
+type dir struct {
+	fd   int
+	file *os.File
+}
+
+func openRoot(path string) (*dir, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		f.Close()
+		return nil, &os.PathError{Op: "open", Path: path, Err: unix.ENOTDIR}
+	}
+
+	return &dir{fd: int(f.Fd()), file: f}, nil
+}
+
+func (d *dir) open(name string) (*dir, error) {
+	fd, err := unix.Openat(d.fd, name, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dir{fd: fd, file: os.NewFile(uintptr(fd), name)}, nil
+}
+
+func (d *dir) close() error {
+	return d.file.Close()
+}
+
+func (d *dir) openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	fd, err := unix.Openat(d.fd, name, flag|unix.O_NOFOLLOW|unix.O_CLOEXEC, uint32(perm.Perm()))
+	if err != nil {
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), name), nil
+}
+
+func (d *dir) lstat(name string) (os.FileInfo, error) {
+	var st unix.Stat_t
+	if err := unix.Fstatat(d.fd, name, &st, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		return nil, err
+	}
+
+	return newStatInfo(name, &st), nil
+}
+
+func (d *dir) readDir() ([]os.FileInfo, error) {
+	names, err := d.file.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]os.FileInfo, 0, len(names))
+	for _, name := range names {
+		fi, err := d.lstat(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		l = append(l, fi)
+	}
+
+	return l, nil
+}
+
+func (d *dir) mkdir(name string, perm os.FileMode) error {
+	return unix.Mkdirat(d.fd, name, uint32(perm.Perm()))
+}
+
+func (d *dir) rename(name string, to *dir, toName string) error {
+	return unix.Renameat(d.fd, name, to.fd, toName)
+}
+
+func (d *dir) link(name string, to *dir, toName string) error {
+	return unix.Linkat(d.fd, name, to.fd, toName, 0)
+}
+
+func (d *dir) remove(name string) error {
+	err := unix.Unlinkat(d.fd, name, 0)
+	if err == nil {
+		return nil
+	}
+
+	if err1 := unix.Unlinkat(d.fd, name, unix.AT_REMOVEDIR); err1 == nil {
+		return nil
+	} else if err1 != unix.ENOTDIR {
+		// Both failed, as in os.Remove prefer the error of rmdir, unless
+		// it's ENOTDIR, which means that name isn't a directory.
+		err = err1
+	}
+
+	return err
+}
+
+func (d *dir) symlink(target, name string) error {
+	return unix.Symlinkat(target, d.fd, name)
+}
+
+func (d *dir) readlink(name string) (string, error) {
+	for size := 128; ; size *= 2 {
+		b := make([]byte, size)
+		n, err := unix.Readlinkat(d.fd, name, b)
+		if err != nil {
+			return "", err
+		}
+
+		if n < size {
+			return string(b[:n]), nil
+		}
+	}
+}
+
+// statInfo is an os.FileInfo built from the result of fstatat.
+type statInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	sys     *unix.Stat_t
+}
+
+func newStatInfo(name string, st *unix.Stat_t) *statInfo {
+	mode := os.FileMode(st.Mode & 0777)
+	switch uint32(st.Mode) & unix.S_IFMT {
+	case unix.S_IFBLK:
+		mode |= os.ModeDevice
+	case unix.S_IFCHR:
+		mode |= os.ModeDevice | os.ModeCharDevice
+	case unix.S_IFDIR:
+		mode |= os.ModeDir
+	case unix.S_IFIFO:
+		mode |= os.ModeNamedPipe
+	case unix.S_IFLNK:
+		mode |= os.ModeSymlink
+	case unix.S_IFSOCK:
+		mode |= os.ModeSocket
+	}
+
+	if uint32(st.Mode)&unix.S_ISGID != 0 {
+		mode |= os.ModeSetgid
+	}
+
+	if uint32(st.Mode)&unix.S_ISUID != 0 {
+		mode |= os.ModeSetuid
+	}
+
+	if uint32(st.Mode)&unix.S_ISVTX != 0 {
+		mode |= os.ModeSticky
+	}
+
+	return &statInfo{
+		name:    filepath.Base(name),
+		size:    st.Size,
+		mode:    mode,
+		modTime: time.Unix(st.Mtim.Unix()),
+		sys:     st,
+	}
+}
+
+func (fi *statInfo) Name() string {
+	return fi.name
+}
+
+func (fi *statInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi *statInfo) Mode() os.FileMode {
+	return fi.mode
+}
+
+func (fi *statInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+func (fi *statInfo) IsDir() bool {
+	return fi.mode.IsDir()
+}
+
+func (fi *statInfo) Sys() interface{} {
+	return fi.sys
+}
@@ -0,0 +1,91 @@
+// +build !linux,!darwin,!freebsd
+
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// dir is a directory, its entries are accessed by name, since the *at family
+// of syscalls isn't available.
+type dir struct {
+	path string
+}
+
+func openRoot(path string) (*dir, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		return nil, &os.PathError{Op: "open", Path: path, Err: syscall.ENOTDIR}
+	}
+
+	return &dir{path: path}, nil
+}
+
+func (d *dir) join(name string) string {
+	return filepath.Join(d.path, name)
+}
+
+func (d *dir) open(name string) (*dir, error) {
+	fi, err := os.Lstat(d.join(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		return nil, syscall.ENOTDIR
+	}
+
+	return &dir{path: d.join(name)}, nil
+}
+
+func (d *dir) close() error {
+	return nil
+}
+
+func (d *dir) openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(d.join(name), flag, perm)
+}
+
+func (d *dir) lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(d.join(name))
+}
+
+func (d *dir) readDir() ([]os.FileInfo, error) {
+	f, err := os.Open(d.path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return f.Readdir(-1)
+}
+
+func (d *dir) mkdir(name string, perm os.FileMode) error {
+	return os.Mkdir(d.join(name), perm)
+}
+
+func (d *dir) rename(name string, to *dir, toName string) error {
+	return os.Rename(d.join(name), to.join(toName))
+}
+
+func (d *dir) link(name string, to *dir, toName string) error {
+	return os.Link(d.join(name), to.join(toName))
+}
+
+func (d *dir) remove(name string) error {
+	return os.Remove(d.join(name))
+}
+
+func (d *dir) symlink(target, name string) error {
+	return os.Symlink(target, d.join(name))
+}
+
+func (d *dir) readlink(name string) (string, error) {
+	return os.Readlink(d.join(name))
+}
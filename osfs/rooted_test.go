@@ -0,0 +1,140 @@
+package osfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+type RootedSuite struct {
+	test.FilesystemSuite
+	path string
+}
+
+var _ = Suite(&RootedSuite{})
+
+func (s *RootedSuite) SetUpTest(c *C) {
+	s.path, _ = ioutil.TempDir(os.TempDir(), "go-billy-osfs-test")
+
+	fs, err := NewRooted(s.path)
+	c.Assert(err, IsNil)
+	s.FilesystemSuite = test.NewFilesystemSuite(fs)
+}
+
+func (s *RootedSuite) TearDownTest(c *C) {
+	err := os.RemoveAll(s.path)
+	c.Assert(err, IsNil)
+}
+
+func (s *RootedSuite) TestNewRootedNotExists(c *C) {
+	_, err := NewRooted(filepath.Join(s.path, "foo"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *RootedSuite) TestSymlinkToAbsoluteOutside(c *C) {
+	outside, err := ioutil.TempDir(os.TempDir(), "go-billy-osfs-outside")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(outside)
+
+	err = ioutil.WriteFile(filepath.Join(outside, "secret"), []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = os.Symlink(outside, filepath.Join(s.path, "link"))
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Open("link/secret")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	err = util.WriteFile(s.FS, "link/secret", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadFile(filepath.Join(outside, "secret"))
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+
+	content, err = ioutil.ReadFile(filepath.Join(s.path, outside, "secret"))
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+}
+
+func (s *RootedSuite) TestSymlinkToRelativeOutside(c *C) {
+	err := os.MkdirAll(filepath.Join(s.path, "foo"), 0755)
+	c.Assert(err, IsNil)
+
+	err = os.Symlink("../../..", filepath.Join(s.path, "foo", "link"))
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("foo/link")
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+
+	_, err = s.FS.Create("foo/link/bar")
+	c.Assert(err, Equals, billy.ErrCrossedBoundary)
+
+	fi, err := s.FS.Lstat("foo/link")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode()&os.ModeSymlink, Equals, os.ModeSymlink)
+}
+
+func (s *RootedSuite) TestSymlinkLoop(c *C) {
+	err := s.FS.Symlink("bar", "foo")
+	c.Assert(err, IsNil)
+
+	err = s.FS.Symlink("foo", "bar")
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("foo")
+	c.Assert(err, NotNil)
+}
+
+func (s *RootedSuite) TestRootPinned(c *C) {
+	switch runtime.GOOS {
+	case "linux", "darwin", "freebsd":
+	default:
+		c.Skip("the root is only pinned when the *at syscalls are available")
+	}
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	moved := s.path + "-moved"
+	c.Assert(os.Rename(s.path, moved), IsNil)
+	defer os.RemoveAll(moved)
+	c.Assert(os.Mkdir(s.path, 0755), IsNil)
+
+	_, err = s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+
+	_, err = os.Stat(filepath.Join(s.path, "foo"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *RootedSuite) TestLinkThroughSymlinkOutside(c *C) {
+	outside, err := ioutil.TempDir(os.TempDir(), "go-billy-osfs-outside")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(outside)
+
+	err = ioutil.WriteFile(filepath.Join(outside, "secret"), []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = os.Symlink(outside, filepath.Join(s.path, "link"))
+	c.Assert(err, IsNil)
+
+	err = billy.Link(s.FS, "link/secret", "secret")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	c.Assert(util.WriteFile(s.FS, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(billy.Link(s.FS, "foo", "link/foo"), IsNil)
+
+	_, err = os.Stat(filepath.Join(outside, "foo"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = os.Stat(filepath.Join(s.path, outside, "foo"))
+	c.Assert(err, IsNil)
+}
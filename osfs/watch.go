@@ -0,0 +1,99 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// Watch implements the Watcher interface, with fsnotify. The recursive watches
+// add a watch for every directory of the tree, including the ones created
+// after the call.
+func (fs *OS) Watch(path string, recursive bool) (<-chan billy.Event, billy.CancelFunc, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := addWatches(w, path, recursive); err != nil {
+		w.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan billy.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(events)
+		for {
+			var e fsnotify.Event
+			select {
+			case e = <-w.Events:
+			case <-w.Errors:
+				continue
+			case <-done:
+				return
+			}
+
+			if recursive && e.Op&fsnotify.Create != 0 {
+				addWatches(w, e.Name, true)
+			}
+
+			select {
+			case events <- billy.Event{Path: e.Name, Op: toOp(e.Op)}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			close(done)
+			w.Close()
+		})
+	}, nil
+}
+
+// addWatches adds a watch for path and, if recursive, every directory inside
+// it.
+func addWatches(w *fsnotify.Watcher, path string, recursive bool) error {
+	if !recursive {
+		return w.Add(path)
+	}
+
+	return filepath.Walk(path, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !fi.IsDir() {
+			return nil
+		}
+
+		return w.Add(path)
+	})
+}
+
+func toOp(op fsnotify.Op) billy.Op {
+	var o billy.Op
+	for _, m := range []struct {
+		from fsnotify.Op
+		to   billy.Op
+	}{
+		{fsnotify.Create, billy.Create},
+		{fsnotify.Write, billy.Write},
+		{fsnotify.Remove, billy.Remove},
+		{fsnotify.Rename, billy.Rename},
+		{fsnotify.Chmod, billy.Chmod},
+	} {
+		if op&m.from != 0 {
+			o |= m.to
+		}
+	}
+
+	return o
+}
@@ -0,0 +1,57 @@
+package billy
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// The paths given to a filesystem are relative to its root, even when they
+// start with a separator, and their elements are separated by a slash or by
+// filepath.Separator, on every OS. The paths returned, as the names of the
+// files or the ones returned by TempDir, use filepath.Separator, since they
+// are built with Join.
+//
+// The filesystems not backed by the OS keep their paths in slash form, as
+// returned by SlashPath. osfs converts them to the form of the OS, the only
+// one where the volume names of Windows, as drive letters or UNC shares, are
+// meaningful: they're accepted in the base directory given to osfs, but a
+// path with a volume name given to a filesystem names a location outside of
+// its root, and it's rejected with ErrCrossedBoundary.
+
+// IsAbs reports whether path is absolute, starting with a separator, or with
+// a volume name on Windows.
+func IsAbs(path string) bool {
+	return filepath.IsAbs(path) || strings.HasPrefix(filepath.ToSlash(path), "/")
+}
+
+// HasVolumeName reports whether path starts with a volume name, as "C:" or
+// `\\host\share` on Windows, which is never the case on the other OSes.
+func HasVolumeName(path string) bool {
+	return filepath.VolumeName(filepath.FromSlash(path)) != ""
+}
+
+// SplitPath returns the elements of path, split by both separators, without
+// the empty and "." ones. The ".." elements are kept, since they can't be
+// resolved without following the symbolic links.
+func SplitPath(path string) []string {
+	var elems []string
+	for _, elem := range strings.FieldsFunc(path, isSeparator) {
+		if elem != "." {
+			elems = append(elems, elem)
+		}
+	}
+
+	return elems
+}
+
+// SlashPath returns the cleaned slash form of path, relative to the root and
+// without leading slash, or "." for the root.
+func SlashPath(path string) string {
+	path = filepath.ToSlash(filepath.Clean(filepath.FromSlash(path)))
+	path = strings.TrimLeft(path, "/")
+	if path == "" {
+		return "."
+	}
+
+	return path
+}
@@ -0,0 +1,14 @@
+// +build !windows
+
+package billy_test
+
+import (
+	. "gopkg.in/src-d/go-billy.v4"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *FSSuite) TestHasVolumeName(c *C) {
+	c.Assert(HasVolumeName(`C:\foo`), Equals, false)
+	c.Assert(HasVolumeName(`//host/share/foo`), Equals, false)
+}
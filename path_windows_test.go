@@ -0,0 +1,35 @@
+// +build windows
+
+package billy_test
+
+import (
+	. "gopkg.in/src-d/go-billy.v4"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *FSSuite) TestHasVolumeName(c *C) {
+	for path, expected := range map[string]bool{
+		`C:\foo`:           true,
+		`C:foo`:            true,
+		`c:/foo`:           true,
+		`\\host\share\foo`: true,
+		`//host/share/foo`: true,
+		`\foo`:             false,
+		`foo`:              false,
+	} {
+		c.Assert(HasVolumeName(path), Equals, expected, Commentf("%s", path))
+	}
+}
+
+func (s *FSSuite) TestSplitPathWindows(c *C) {
+	c.Assert(SplitPath(`\foo\bar/qux`), DeepEquals, []string{"foo", "bar", "qux"})
+	c.Assert(SlashPath(`\foo\..\bar\qux\`), Equals, "bar/qux")
+}
+
+func (s *FSSuite) TestIsAbsWindows(c *C) {
+	c.Assert(IsAbs(`C:\foo`), Equals, true)
+	c.Assert(IsAbs(`\\host\share\foo`), Equals, true)
+	c.Assert(IsAbs(`\foo`), Equals, true)
+	c.Assert(IsAbs(`C:foo`), Equals, false)
+}
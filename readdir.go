@@ -0,0 +1,72 @@
+package billy
+
+import (
+	"io"
+	"os"
+)
+
+// DirIter iterates the entries of a directory, without reading all of them
+// upfront. It must be closed once done.
+type DirIter interface {
+	// Next returns the next entries of the directory, as os.File.Readdir
+	// does. If n > 0, it returns at most n entries, and io.EOF once all of
+	// them were returned. If n <= 0, it returns all the remaining entries,
+	// and a nil error at the end of the directory.
+	Next(n int) ([]os.FileInfo, error)
+	// Close releases the resources of the iterator.
+	Close() error
+}
+
+// DirIterator interface can stream the entries of a directory, as an extension
+// to the Dir interface. It allows to read the directories with a huge number
+// of entries without holding all of them in memory.
+type DirIterator interface {
+	// ReadDirIter returns an iterator of the entries of the given directory.
+	// The order of the entries is the one of the filesystem, not sorted as
+	// the ones returned by ReadDir.
+	ReadDirIter(path string) (DirIter, error)
+}
+
+// ReadDirIter returns an iterator of the entries of the given directory, using
+// the ReadDirIter of fs if it implements the DirIterator interface, or
+// iterating the entries returned by ReadDir otherwise.
+func ReadDirIter(fs Dir, path string) (DirIter, error) {
+	if i, ok := fs.(DirIterator); ok {
+		return i.ReadDirIter(path)
+	}
+
+	entries, err := fs.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDirIter(entries), nil
+}
+
+// NewDirIter returns an iterator of the given entries.
+func NewDirIter(entries []os.FileInfo) DirIter {
+	return &sliceDirIter{entries: entries}
+}
+
+type sliceDirIter struct {
+	entries []os.FileInfo
+}
+
+func (i *sliceDirIter) Next(n int) ([]os.FileInfo, error) {
+	if n <= 0 || n > len(i.entries) {
+		if n > 0 && len(i.entries) == 0 {
+			return nil, io.EOF
+		}
+
+		n = len(i.entries)
+	}
+
+	l := i.entries[:n:n]
+	i.entries = i.entries[n:]
+	return l, nil
+}
+
+func (i *sliceDirIter) Close() error {
+	i.entries = nil
+	return nil
+}
@@ -0,0 +1,237 @@
+// Package remote provides the protocol shared by the clients and the servers
+// of the remote billy filesystems, served over HTTP. Every session starts with
+// a handshake negotiating the version of the protocol and the features served,
+// so the clients and the servers can be upgraded independently.
+package remote // import "gopkg.in/src-d/go-billy.v4/remote"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+const (
+	// MinVersion is the oldest version of the protocol supported.
+	MinVersion = 1
+	// MaxVersion is the newest version of the protocol supported.
+	MaxVersion = 1
+
+	// HandshakePath is the path of the handshake endpoint, relative to the
+	// base URL of the server.
+	HandshakePath = "/.billy/handshake"
+)
+
+// Feature is an optional feature of a server, served only if both sides know
+// it. New features are added without bumping the version of the protocol.
+type Feature string
+
+const (
+	// FeatureLock is the ability to lock the files.
+	FeatureLock Feature = "lock"
+	// FeatureWatch is the ability to watch the changes of the paths.
+	FeatureWatch Feature = "watch"
+	// FeatureBatch is the ability to remove many paths at once.
+	FeatureBatch Feature = "batch"
+	// FeatureSymlink is the ability to create and read symbolic links.
+	FeatureSymlink Feature = "symlink"
+	// FeatureLink is the ability to create hard links.
+	FeatureLink Feature = "link"
+)
+
+// Features are the features known by this version of the package.
+var Features = []Feature{
+	FeatureLock, FeatureWatch, FeatureBatch, FeatureSymlink, FeatureLink,
+}
+
+// Hello is the request of the handshake, sent by the client.
+type Hello struct {
+	// MinVersion and MaxVersion are the range of versions of the protocol
+	// supported by the client.
+	MinVersion int `json:"min_version"`
+	MaxVersion int `json:"max_version"`
+	// Features are the features known by the client.
+	Features []Feature `json:"features"`
+}
+
+// Welcome is the response of the handshake, sent by the server.
+type Welcome struct {
+	// Version is the version of the protocol to use during the session, the
+	// newest one supported by both sides, or 0 if there isn't any.
+	Version int `json:"version"`
+	// MinVersion and MaxVersion are the range of versions of the protocol
+	// supported by the server.
+	MinVersion int `json:"min_version"`
+	MaxVersion int `json:"max_version"`
+	// Capabilities are the capabilities of the filesystem served.
+	Capabilities billy.Capability `json:"capabilities"`
+	// Features are the features served known by the client.
+	Features []Feature `json:"features"`
+}
+
+// Has returns true if the feature f is served.
+func (w *Welcome) Has(f Feature) bool {
+	for _, feature := range w.Features {
+		if feature == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// VersionError is returned by the handshake when the client and the server
+// don't support any common version of the protocol.
+type VersionError struct {
+	Client, Server [2]int
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf(
+		"no common protocol version: client supports %d to %d, server %d to %d",
+		e.Client[0], e.Client[1], e.Server[0], e.Server[1],
+	)
+}
+
+// Server describes the filesystem served, to answer the handshakes.
+type Server struct {
+	// MinVersion and MaxVersion are the range of versions of the protocol
+	// supported by the server.
+	MinVersion, MaxVersion int
+	// Capabilities are the capabilities of the filesystem served.
+	Capabilities billy.Capability
+	// Features are the features served.
+	Features []Feature
+}
+
+// NewServer returns the description of a server of fs, supporting every version
+// of the protocol known, with the capabilities and the features of fs.
+func NewServer(fs billy.Basic) *Server {
+	caps := billy.Capabilities(fs)
+	var features []Feature
+	if caps&billy.LockCapability != 0 {
+		features = append(features, FeatureLock)
+	}
+
+	if _, ok := fs.(billy.Watcher); ok {
+		features = append(features, FeatureWatch)
+	}
+
+	if _, ok := fs.(billy.BulkRemover); ok {
+		features = append(features, FeatureBatch)
+	}
+
+	if caps&billy.SymlinkCapability != 0 {
+		features = append(features, FeatureSymlink)
+	}
+
+	if _, ok := fs.(billy.Linker); ok {
+		features = append(features, FeatureLink)
+	}
+
+	return &Server{
+		MinVersion:   MinVersion,
+		MaxVersion:   MaxVersion,
+		Capabilities: caps,
+		Features:     features,
+	}
+}
+
+// Negotiate answers the given hello, choosing the newest version supported by
+// both sides and the features served known by the client. If there isn't any
+// common version, the version of the welcome is 0.
+func (s *Server) Negotiate(h *Hello) *Welcome {
+	w := &Welcome{
+		MinVersion:   s.MinVersion,
+		MaxVersion:   s.MaxVersion,
+		Capabilities: s.Capabilities,
+	}
+
+	version := s.MaxVersion
+	if h.MaxVersion < version {
+		version = h.MaxVersion
+	}
+
+	if version < s.MinVersion || version < h.MinVersion {
+		return w
+	}
+
+	w.Version = version
+	for _, f := range s.Features {
+		for _, known := range h.Features {
+			if f == known {
+				w.Features = append(w.Features, f)
+				break
+			}
+		}
+	}
+
+	return w
+}
+
+// ServeHTTP implements the http.Handler interface, answering the handshakes.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h := &Hello{}
+	if err := json.NewDecoder(r.Body).Decode(h); err != nil {
+		http.Error(w, "malformed hello", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Negotiate(h))
+}
+
+// Handshake negotiates a session with the server at the given base URL,
+// sending the versions and the features known by this package. If client is
+// nil, http.DefaultClient is used, the clients with TLS and credentials can be
+// built with transport.Config. It returns a *VersionError if there isn't
+// any common version of the protocol.
+//
+// The capabilities of the welcome are limited to the ones known by this
+// package, so a newer server degrades to the features of the client.
+func Handshake(client *http.Client, baseURL string) (*Welcome, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	h := &Hello{MinVersion: MinVersion, MaxVersion: MaxVersion, Features: Features}
+	body, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(baseURL, "/") + HandshakePath
+	res, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("handshake failed: %s", res.Status)
+	}
+
+	w := &Welcome{}
+	if err := json.NewDecoder(res.Body).Decode(w); err != nil {
+		return nil, err
+	}
+
+	if w.Version == 0 {
+		return nil, &VersionError{
+			Client: [2]int{h.MinVersion, h.MaxVersion},
+			Server: [2]int{w.MinVersion, w.MaxVersion},
+		}
+	}
+
+	w.Capabilities &= billy.AllCapabilities
+	return w, nil
+}
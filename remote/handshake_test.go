@@ -0,0 +1,105 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&HandshakeSuite{})
+
+type HandshakeSuite struct{}
+
+func (s *HandshakeSuite) serve(srv *Server) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle(HandshakePath, srv)
+	return httptest.NewServer(mux)
+}
+
+func (s *HandshakeSuite) TestHandshake(c *C) {
+	server := s.serve(NewServer(memfs.New()))
+	defer server.Close()
+
+	w, err := Handshake(nil, server.URL+"/")
+	c.Assert(err, IsNil)
+	c.Assert(w.Version, Equals, MaxVersion)
+	c.Assert(w.Capabilities, Equals, billy.Capabilities(memfs.New()))
+	c.Assert(w.Has(FeatureWatch), Equals, true)
+	c.Assert(w.Has(FeatureSymlink), Equals, true)
+	c.Assert(w.Has(FeatureLink), Equals, true)
+	c.Assert(w.Has(FeatureLock), Equals, false)
+}
+
+func (s *HandshakeSuite) TestHandshakeNewerServer(c *C) {
+	server := s.serve(&Server{
+		MinVersion:   MinVersion,
+		MaxVersion:   MaxVersion + 1,
+		Capabilities: billy.AllCapabilities | billy.AllCapabilities<<1,
+		Features:     []Feature{FeatureLock, "future"},
+	})
+	defer server.Close()
+
+	w, err := Handshake(nil, server.URL)
+	c.Assert(err, IsNil)
+	c.Assert(w.Version, Equals, MaxVersion)
+	c.Assert(w.Capabilities, Equals, billy.AllCapabilities)
+	c.Assert(w.Features, DeepEquals, []Feature{FeatureLock})
+}
+
+func (s *HandshakeSuite) TestHandshakeVersionError(c *C) {
+	server := s.serve(&Server{MinVersion: MaxVersion + 1, MaxVersion: MaxVersion + 2})
+	defer server.Close()
+
+	_, err := Handshake(nil, server.URL)
+	c.Assert(err, DeepEquals, &VersionError{
+		Client: [2]int{MinVersion, MaxVersion},
+		Server: [2]int{MaxVersion + 1, MaxVersion + 2},
+	})
+}
+
+func (s *HandshakeSuite) TestHandshakeNotFound(c *C) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := Handshake(nil, server.URL)
+	c.Assert(err, ErrorMatches, "handshake failed: 404 Not Found")
+}
+
+func (s *HandshakeSuite) TestNegotiateOlderClient(c *C) {
+	srv := &Server{
+		MinVersion: 1,
+		MaxVersion: 3,
+		Features:   []Feature{FeatureLock, FeatureWatch},
+	}
+
+	w := srv.Negotiate(&Hello{MinVersion: 1, MaxVersion: 2, Features: []Feature{FeatureWatch}})
+	c.Assert(w.Version, Equals, 2)
+	c.Assert(w.Features, DeepEquals, []Feature{FeatureWatch})
+	c.Assert(w.Has(FeatureLock), Equals, false)
+}
+
+func (s *HandshakeSuite) TestNegotiateNoCommonVersion(c *C) {
+	srv := &Server{MinVersion: 2, MaxVersion: 3}
+
+	w := srv.Negotiate(&Hello{MinVersion: 1, MaxVersion: 1})
+	c.Assert(w.Version, Equals, 0)
+	c.Assert(w.MinVersion, Equals, 2)
+	c.Assert(w.MaxVersion, Equals, 3)
+}
+
+func (s *HandshakeSuite) TestServeHTTPMethodNotAllowed(c *C) {
+	server := s.serve(NewServer(memfs.New()))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + HandshakePath)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusMethodNotAllowed)
+}
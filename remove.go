@@ -0,0 +1,77 @@
+package billy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// RemoverAll interface can remove a whole tree, as an extension to the Basic
+// interface. util.RemoveAll uses it if available, removing the files one by
+// one otherwise.
+type RemoverAll interface {
+	// RemoveAll removes path and any children it contains, as os.RemoveAll
+	// does: it removes everything it can, even if some entries can't be
+	// removed, and returns the first error it encounters. If the path
+	// doesn't exist it returns nil. The symbolic links are removed, never
+	// followed.
+	RemoveAll(path string) error
+}
+
+// BulkRemover interface can remove many files at once, issuing batched
+// requests, as an extension to the Basic interface.
+type BulkRemover interface {
+	// RemoveMany removes the named files or empty directories, in the given
+	// order. The paths that don't exist are ignored. If any of them can't be
+	// removed the rest are removed anyway, and a *RemoveManyError is returned.
+	RemoveMany(paths []string) error
+}
+
+// RemoveManyError reports the paths that couldn't be removed by RemoveMany.
+type RemoveManyError struct {
+	// Errors holds the error of each path that couldn't be removed.
+	Errors map[string]error
+}
+
+// Paths returns the paths that couldn't be removed, sorted.
+func (e *RemoveManyError) Paths() []string {
+	paths := make([]string, 0, len(e.Errors))
+	for path := range e.Errors {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+func (e *RemoveManyError) Error() string {
+	paths := e.Paths()
+	if len(paths) == 1 {
+		return e.Errors[paths[0]].Error()
+	}
+
+	return fmt.Sprintf("%d files couldn't be removed, first: %s",
+		len(paths), e.Errors[paths[0]])
+}
+
+// RemoveMany removes the named files or empty directories, using the
+// RemoveMany of fs if it implements the BulkRemover interface, or removing
+// them one by one, in the given order, otherwise.
+func RemoveMany(fs Basic, paths []string) error {
+	if r, ok := fs.(BulkRemover); ok {
+		return r.RemoveMany(paths)
+	}
+
+	errs := make(map[string]error)
+	for _, path := range paths {
+		if err := fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs[path] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return &RemoveManyError{Errors: errs}
+	}
+
+	return nil
+}
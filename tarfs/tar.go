@@ -0,0 +1,468 @@
+// Package tarfs provides a read-only billy filesystem over a tar archive.
+package tarfs // import "gopkg.in/src-d/go-billy.v4/tarfs"
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+)
+
+const defaultDirectoryMode = os.ModeDir | 0755
+
+// Tar is a read-only filesystem based on a tar archive.
+type Tar struct {
+	r    io.ReaderAt
+	root *node
+}
+
+// node is an entry of the archive, directories not present in the archive
+// but containing some entry are created with a nil header.
+type node struct {
+	name     string
+	header   *tar.Header
+	children map[string]*node
+
+	// offset of the content in the archive, or the content itself for the
+	// entries that are not stored contiguously, as sparse files.
+	offset  int64
+	content []byte
+}
+
+// New returns a new read-only filesystem with the contents of the tar archive
+// read from r. The headers of the archive are read, and indexed, when New is
+// called, the content of the files is read from r on demand, so r must remain
+// available while the filesystem is in use.
+//
+// The symbolic links of the archive are followed as if the root of the
+// archive was the root of the system, and the hard links share the content of
+// their target.
+func New(r io.ReaderAt) (billy.Filesystem, error) {
+	fs := &Tar{r: r, root: newDir("")}
+	if err := fs.index(); err != nil {
+		return nil, err
+	}
+
+	return chroot.New(fs, string(filepath.Separator)), nil
+}
+
+func (fs *Tar) index() error {
+	cr := &countingReader{r: io.NewSectionReader(fs.r, 0, math.MaxInt64)}
+	tr := tar.NewReader(cr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		n := &node{header: hdr, offset: cr.n}
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeRegA, tar.TypeGNUSparse:
+			// sparse files are expanded in memory, since their content
+			// is not stored contiguously in the archive.
+			if isSparse(hdr) {
+				content, err := ioutil.ReadAll(tr)
+				if err != nil {
+					return err
+				}
+
+				n.content = content
+			}
+		case tar.TypeLink:
+			target, err := fs.get(hdr.Linkname)
+			if err != nil {
+				return err
+			}
+
+			n.offset, n.content = target.offset, target.content
+			n.header = copyHeader(target.header, hdr)
+		case tar.TypeDir, tar.TypeSymlink:
+		default:
+			continue
+		}
+
+		fs.add(hdr.Name, n)
+	}
+}
+
+func (fs *Tar) add(name string, n *node) {
+	parts := billy.SplitPath(name)
+	if len(parts) == 0 {
+		return
+	}
+
+	parent := fs.root
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := parent.children[part]
+		if !ok || !child.isDir() {
+			child = newDir(part)
+			parent.children[part] = child
+		}
+
+		parent = child
+	}
+
+	n.name = parts[len(parts)-1]
+	if n.isDir() {
+		n.children = make(map[string]*node)
+		if prev, ok := parent.children[n.name]; ok && prev.isDir() {
+			n.children = prev.children
+		}
+	}
+
+	parent.children[n.name] = n
+}
+
+// get returns the node of the given archive name, without following links.
+func (fs *Tar) get(name string) (*node, error) {
+	n := fs.root
+	for _, part := range billy.SplitPath(name) {
+		child, ok := n.children[part]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+
+		n = child
+	}
+
+	return n, nil
+}
+
+func (fs *Tar) Create(filename string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *Tar) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *Tar) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+		return nil, billy.ErrReadOnly
+	}
+
+	n, err := fs.resolve(filename, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.isDir() {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: billy.ErrIsDir}
+	}
+
+	var r *io.SectionReader
+	if n.content != nil {
+		r = io.NewSectionReader(bytes.NewReader(n.content), 0, int64(len(n.content)))
+	} else {
+		r = io.NewSectionReader(fs.r, n.offset, n.header.Size)
+	}
+
+	return &file{name: filename, SectionReader: r}, nil
+}
+
+func (fs *Tar) Stat(filename string) (os.FileInfo, error) {
+	n, err := fs.resolve(filename, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.info(filename), nil
+}
+
+func (fs *Tar) Lstat(filename string) (os.FileInfo, error) {
+	n, err := fs.resolve(filename, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.info(filename), nil
+}
+
+func (fs *Tar) ReadDir(path string) ([]os.FileInfo, error) {
+	n, err := fs.resolve(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !n.isDir() {
+		return nil, &os.PathError{Op: "readdir", Path: path, Err: syscall.ENOTDIR}
+	}
+
+	entries := make([]os.FileInfo, 0, len(n.children))
+	for name, child := range n.children {
+		entries = append(entries, child.info(name))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+func (fs *Tar) Readlink(link string) (string, error) {
+	n, err := fs.resolve(link, false)
+	if err != nil {
+		return "", err
+	}
+
+	if !n.isSymlink() {
+		return "", &os.PathError{Op: "readlink", Path: link, Err: syscall.EINVAL}
+	}
+
+	return n.header.Linkname, nil
+}
+
+func (fs *Tar) Rename(from, to string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *Tar) Remove(filename string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *Tar) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (fs *Tar) TempFile(dir, prefix string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *Tar) TempDir(dir, prefix string) (string, error) {
+	return "", billy.ErrReadOnly
+}
+
+func (fs *Tar) MkdirAll(filename string, perm os.FileMode) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *Tar) Symlink(target, link string) error {
+	return billy.ErrReadOnly
+}
+
+// Capabilities implements the Capable interface.
+func (fs *Tar) Capabilities() billy.Capability {
+	return billy.ReadCapability | billy.SeekCapability
+}
+
+// resolve returns the node of the given path, following the symbolic links,
+// including the last element if follow is true.
+func (fs *Tar) resolve(filename string, follow bool) (*node, error) {
+	stack := []*node{fs.root}
+	parts := billy.SplitPath(filename)
+	for links := 0; len(parts) > 0; {
+		part := parts[0]
+		parts = parts[1:]
+		cur := stack[len(stack)-1]
+
+		if part == ".." {
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+
+			continue
+		}
+
+		if !cur.isDir() {
+			return nil, &os.PathError{Op: "stat", Path: filename, Err: syscall.ENOTDIR}
+		}
+
+		n, ok := cur.children[part]
+		if !ok {
+			return nil, &os.PathError{Op: "stat", Path: filename, Err: os.ErrNotExist}
+		}
+
+		if n.isSymlink() && (follow || len(parts) > 0) {
+			if links++; links > billy.MaxSymlinks {
+				return nil, &os.PathError{Op: "stat", Path: filename, Err: billy.ErrTooManyLinks}
+			}
+
+			target := n.header.Linkname
+			if path.IsAbs(filepath.ToSlash(target)) {
+				stack = stack[:1]
+			}
+
+			parts = append(billy.SplitPath(target), parts...)
+			continue
+		}
+
+		stack = append(stack, n)
+	}
+
+	return stack[len(stack)-1], nil
+}
+
+func isSparse(hdr *tar.Header) bool {
+	if hdr.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+
+	for key := range hdr.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newDir(name string) *node {
+	return &node{name: name, children: make(map[string]*node)}
+}
+
+func (n *node) isDir() bool {
+	return n.header == nil || n.header.Typeflag == tar.TypeDir
+}
+
+func (n *node) isSymlink() bool {
+	return n.header != nil && n.header.Typeflag == tar.TypeSymlink
+}
+
+func (n *node) info(filename string) os.FileInfo {
+	fi := &fileInfo{name: filepath.Base(filename), mode: defaultDirectoryMode}
+	if n.header == nil {
+		return fi
+	}
+
+	fi.mode = n.header.FileInfo().Mode()
+	fi.modTime = n.header.ModTime
+	fi.sys = n.header
+	if !n.isDir() && !n.isSymlink() {
+		fi.size = n.header.Size
+		if n.content != nil {
+			fi.size = int64(len(n.content))
+		}
+	}
+
+	return fi
+}
+
+// copyHeader returns the header of a hard link, with the content related
+// fields of its target.
+func copyHeader(target, link *tar.Header) *tar.Header {
+	h := *link
+	h.Typeflag = target.Typeflag
+	h.Size = target.Size
+	h.Linkname = target.Linkname
+	return &h
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+type file struct {
+	*io.SectionReader
+	name     string
+	isClosed bool
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+func (f *file) Read(b []byte) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	return f.SectionReader.Read(b)
+}
+
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	return f.SectionReader.ReadAt(b, off)
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	return f.SectionReader.Seek(offset, whence)
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	return 0, billy.ErrReadOnly
+}
+
+func (f *file) Close() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	f.isClosed = true
+	return nil
+}
+
+// Lock is a no-op in tarfs.
+func (f *file) Lock() error {
+	return nil
+}
+
+// Unlock is a no-op in tarfs.
+func (f *file) Unlock() error {
+	return nil
+}
+
+func (f *file) Truncate(size int64) error {
+	return billy.ErrReadOnly
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	sys     interface{}
+}
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *fileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi *fileInfo) Mode() os.FileMode {
+	return fi.mode
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+func (fi *fileInfo) IsDir() bool {
+	return fi.mode.IsDir()
+}
+
+func (fi *fileInfo) Sys() interface{} {
+	return fi.sys
+}
@@ -0,0 +1,176 @@
+package tarfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&TarSuite{})
+
+type TarSuite struct {
+	FS billy.Filesystem
+}
+
+func (s *TarSuite) SetUpTest(c *C) {
+	buf := bytes.NewBuffer(nil)
+	w := tar.NewWriter(buf)
+
+	writeEntry(c, w, &tar.Header{Name: "foo/", Typeflag: tar.TypeDir, Mode: 0700})
+	writeEntry(c, w, &tar.Header{Name: "foo/bar", Mode: 0644}, "bar")
+	writeEntry(c, w, &tar.Header{Name: "./qux/baz", Mode: 0600}, "baz")
+	writeEntry(c, w, &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "foo/bar"})
+	writeEntry(c, w, &tar.Header{Name: "abs", Typeflag: tar.TypeSymlink, Linkname: "/qux"})
+	writeEntry(c, w, &tar.Header{Name: "hard", Typeflag: tar.TypeLink, Linkname: "foo/bar"})
+	c.Assert(w.Close(), IsNil)
+
+	var err error
+	s.FS, err = New(bytes.NewReader(buf.Bytes()))
+	c.Assert(err, IsNil)
+}
+
+func writeEntry(c *C, w *tar.Writer, hdr *tar.Header, content ...string) {
+	if len(content) > 0 {
+		hdr.Size = int64(len(content[0]))
+	}
+
+	c.Assert(w.WriteHeader(hdr), IsNil)
+	for _, s := range content {
+		_, err := w.Write([]byte(s))
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *TarSuite) readFile(c *C, filename string) string {
+	f, err := s.FS.Open(filename)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	return string(content)
+}
+
+func (s *TarSuite) TestOpen(c *C) {
+	c.Assert(s.readFile(c, "foo/bar"), Equals, "bar")
+	c.Assert(s.readFile(c, "qux/baz"), Equals, "baz")
+	c.Assert(s.readFile(c, "link"), Equals, "bar")
+	c.Assert(s.readFile(c, "abs/baz"), Equals, "baz")
+	c.Assert(s.readFile(c, "hard"), Equals, "bar")
+
+	_, err := s.FS.Open("foo/qux")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = s.FS.Open("foo")
+	c.Assert(err, NotNil)
+}
+
+func (s *TarSuite) TestReadAtAndSeek(c *C) {
+	f, err := s.FS.Open("qux/baz")
+	c.Assert(err, IsNil)
+
+	b := make([]byte, 2)
+	n, err := f.ReadAt(b, 1)
+	c.Assert(err, IsNil)
+	c.Assert(string(b[:n]), Equals, "az")
+
+	pos, err := f.Seek(2, 0)
+	c.Assert(err, IsNil)
+	c.Assert(pos, Equals, int64(2))
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "z")
+
+	c.Assert(f.Close(), IsNil)
+	c.Assert(f.Close(), Equals, os.ErrClosed)
+}
+
+func (s *TarSuite) TestStat(c *C) {
+	fi, err := s.FS.Stat("foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "bar")
+	c.Assert(fi.Size(), Equals, int64(3))
+	c.Assert(fi.Mode(), Equals, os.FileMode(0644))
+
+	fi, err = s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+	c.Assert(fi.Mode().Perm(), Equals, os.FileMode(0700))
+
+	fi, err = s.FS.Stat("qux")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+
+	fi, err = s.FS.Stat("link")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "link")
+	c.Assert(fi.Size(), Equals, int64(3))
+
+	fi, err = s.FS.Lstat("link")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode()&os.ModeSymlink, Equals, os.ModeSymlink)
+}
+
+func (s *TarSuite) TestReadDir(c *C) {
+	entries, err := s.FS.ReadDir("/")
+	c.Assert(err, IsNil)
+
+	var names []string
+	for _, fi := range entries {
+		names = append(names, fi.Name())
+	}
+
+	c.Assert(names, DeepEquals, []string{"abs", "foo", "hard", "link", "qux"})
+
+	entries, err = s.FS.ReadDir("abs")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Name(), Equals, "baz")
+}
+
+func (s *TarSuite) TestReadlink(c *C) {
+	target, err := s.FS.Readlink("link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "foo/bar")
+
+	_, err = s.FS.Readlink("foo/bar")
+	c.Assert(err, NotNil)
+}
+
+func (s *TarSuite) TestChroot(c *C) {
+	fs, err := s.FS.Chroot("foo")
+	c.Assert(err, IsNil)
+
+	f, err := fs.Open("bar")
+	c.Assert(err, IsNil)
+	c.Assert(f.Name(), Equals, "bar")
+}
+
+func (s *TarSuite) TestReadOnly(c *C) {
+	_, err := s.FS.Create("foo")
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	_, err = s.FS.OpenFile("foo/bar", os.O_RDWR, 0)
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	c.Assert(s.FS.Remove("foo/bar"), Equals, billy.ErrReadOnly)
+	c.Assert(s.FS.Rename("foo/bar", "bar"), Equals, billy.ErrReadOnly)
+	c.Assert(s.FS.MkdirAll("bar", 0755), Equals, billy.ErrReadOnly)
+	c.Assert(s.FS.Symlink("foo", "bar"), Equals, billy.ErrReadOnly)
+
+	f, err := s.FS.Open("foo/bar")
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("foo"))
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	c.Assert(billy.CapabilityCheck(s.FS, billy.WriteCapability), Equals, false)
+}
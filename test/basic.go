@@ -170,6 +170,8 @@ func (s *BasicSuite) TestOpenFileAppend(c *C) {
 }
 
 func (s *BasicSuite) TestOpenFileReadWrite(c *C) {
+	skipIfNotCapable(c, s.FS, ReadAndWriteCapability)
+
 	defaultMode := os.FileMode(0666)
 
 	f, err := s.FS.OpenFile("foo1", os.O_CREATE|os.O_TRUNC|os.O_RDWR, defaultMode)
@@ -293,6 +295,8 @@ func (s *BasicSuite) TestFileSeekEnd(c *C) {
 }
 
 func (s *BasicSuite) testFileSeek(c *C, offset int64, whence int) {
+	skipIfNotCapable(c, s.FS, SeekCapability)
+
 	err := util.WriteFile(s.FS, "foo", []byte("0123456789abcdefghijklmnopqrstuvwxyz"), 0644)
 	c.Assert(err, IsNil)
 
@@ -316,6 +320,8 @@ func (s *BasicSuite) testFileSeek(c *C, offset int64, whence int) {
 }
 
 func (s *BasicSuite) TestSeekToEndAndWrite(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability|ReadAndWriteCapability)
+
 	defaultMode := os.FileMode(0666)
 
 	f, err := s.FS.OpenFile("foo1", os.O_CREATE|os.O_TRUNC|os.O_RDWR, defaultMode)
@@ -564,6 +570,8 @@ func (s *BasicSuite) TestWriteFile(c *C) {
 }
 
 func (s *BasicSuite) TestTruncate(c *C) {
+	skipIfNotCapable(c, s.FS, TruncateCapability)
+
 	f, err := s.FS.Create("foo")
 	c.Assert(err, IsNil)
 
@@ -579,5 +587,6 @@ func (s *BasicSuite) TestTruncate(c *C) {
 		c.Assert(err, IsNil)
 	}
 
+	c.Assert(f.Truncate(-1), NotNil)
 	c.Assert(f.Close(), IsNil)
 }
@@ -0,0 +1,220 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+const (
+	// benchmarkFileSize is the size of the files read and written by the
+	// sequential and random benchmarks.
+	benchmarkFileSize = 1 << 20
+	// benchmarkBlockSize is the size of each read or write.
+	benchmarkBlockSize = 32 << 10
+	// benchmarkRandomSize is the size of each random read or write.
+	benchmarkRandomSize = 4 << 10
+	// benchmarkSmallFileSize is the size of the files created by
+	// BenchmarkCreateSmallFiles.
+	benchmarkSmallFileSize = 1 << 10
+	// benchmarkTreeDepth and benchmarkTreeWidth are the depth of the tree of
+	// directories listed by BenchmarkReadDirDeep, and the number of files of
+	// each of them.
+	benchmarkTreeDepth = 8
+	benchmarkTreeWidth = 16
+)
+
+// BenchmarkSuite is a convenient suite of standardized benchmarks, comparable
+// between any implementations of billy.Filesystem. The benchmarks run with
+// the -check.b flag of gopkg.in/check.v1, FS must be a new empty filesystem
+// before each of their rounds, as set by SetUpTest. The ones requiring a
+// capability missing in FS are skipped.
+type BenchmarkSuite struct {
+	FS Filesystem
+}
+
+// NewBenchmarkSuite returns a new BenchmarkSuite based on the given fs.
+func NewBenchmarkSuite(fs Filesystem) BenchmarkSuite {
+	return BenchmarkSuite{FS: fs}
+}
+
+// BenchmarkSequentialWrite writes a file of benchmarkFileSize from the start
+// to the end, in blocks of benchmarkBlockSize.
+func (s *BenchmarkSuite) BenchmarkSequentialWrite(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	block := benchmarkData(benchmarkBlockSize)
+	c.SetBytes(benchmarkFileSize)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		f, err := s.FS.Create("file")
+		c.Assert(err, IsNil)
+
+		for n := 0; n < benchmarkFileSize; n += len(block) {
+			_, err = f.Write(block)
+			c.Assert(err, IsNil)
+		}
+
+		c.Assert(f.Close(), IsNil)
+	}
+}
+
+// BenchmarkSequentialRead reads a file of benchmarkFileSize from the start to
+// the end, in blocks of benchmarkBlockSize.
+func (s *BenchmarkSuite) BenchmarkSequentialRead(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|ReadCapability)
+
+	err := util.WriteFile(s.FS, "file", benchmarkData(benchmarkFileSize), 0644)
+	c.Assert(err, IsNil)
+
+	block := make([]byte, benchmarkBlockSize)
+	c.SetBytes(benchmarkFileSize)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		f, err := s.FS.Open("file")
+		c.Assert(err, IsNil)
+
+		for err == nil {
+			_, err = f.Read(block)
+		}
+
+		c.Assert(err, Equals, io.EOF)
+		c.Assert(f.Close(), IsNil)
+	}
+}
+
+// BenchmarkRandomRead reads blocks of benchmarkRandomSize at random offsets
+// of a file of benchmarkFileSize.
+func (s *BenchmarkSuite) BenchmarkRandomRead(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|ReadCapability|SeekCapability)
+
+	err := util.WriteFile(s.FS, "file", benchmarkData(benchmarkFileSize), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.Open("file")
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	r := rand.New(rand.NewSource(1))
+	block := make([]byte, benchmarkRandomSize)
+	c.SetBytes(benchmarkRandomSize)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		off := r.Int63n(benchmarkFileSize - benchmarkRandomSize)
+		_, err := f.ReadAt(block, off)
+		c.Assert(err, IsNil)
+	}
+}
+
+// BenchmarkRandomWrite writes blocks of benchmarkRandomSize at random offsets
+// of a file of benchmarkFileSize.
+func (s *BenchmarkSuite) BenchmarkRandomWrite(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|SeekCapability)
+
+	err := util.WriteFile(s.FS, "file", benchmarkData(benchmarkFileSize), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("file", os.O_WRONLY, 0)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	r := rand.New(rand.NewSource(1))
+	block := benchmarkData(benchmarkRandomSize)
+	c.SetBytes(benchmarkRandomSize)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		off := r.Int63n(benchmarkFileSize - benchmarkRandomSize)
+		_, err := f.Seek(off, io.SeekStart)
+		c.Assert(err, IsNil)
+
+		_, err = f.Write(block)
+		c.Assert(err, IsNil)
+	}
+}
+
+// BenchmarkCreateSmallFiles creates files of benchmarkSmallFileSize, all in
+// the same directory.
+func (s *BenchmarkSuite) BenchmarkCreateSmallFiles(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	c.Assert(s.FS.MkdirAll("dir", 0755), IsNil)
+
+	content := benchmarkData(benchmarkSmallFileSize)
+	c.SetBytes(benchmarkSmallFileSize)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		f, err := s.FS.Create(s.FS.Join("dir", fmt.Sprintf("file%d", i)))
+		c.Assert(err, IsNil)
+
+		_, err = f.Write(content)
+		c.Assert(err, IsNil)
+		c.Assert(f.Close(), IsNil)
+	}
+}
+
+// BenchmarkReadDirDeep lists every directory of a tree benchmarkTreeDepth
+// deep, each of them holding benchmarkTreeWidth files.
+func (s *BenchmarkSuite) BenchmarkReadDirDeep(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	dirs := []string{""}
+	for depth := 0; depth < benchmarkTreeDepth; depth++ {
+		dir := dirs[len(dirs)-1]
+		for i := 0; i < benchmarkTreeWidth; i++ {
+			err := util.WriteFile(s.FS, s.FS.Join(dir, fmt.Sprintf("file%d", i)), nil, 0644)
+			c.Assert(err, IsNil)
+		}
+
+		dirs = append(dirs, s.FS.Join(dir, fmt.Sprintf("dir%d", depth)))
+	}
+
+	c.Assert(s.FS.MkdirAll(dirs[len(dirs)-1], 0755), IsNil)
+	c.ResetTimer()
+
+	for i := 0; i < c.N; i++ {
+		for _, dir := range dirs {
+			_, err := s.FS.ReadDir(dir)
+			c.Assert(err, IsNil)
+		}
+	}
+}
+
+// BenchmarkRenameStorm renames a file over and over, moving it between two
+// directories.
+func (s *BenchmarkSuite) BenchmarkRenameStorm(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	dirs := []string{"a", "b"}
+	for _, dir := range dirs {
+		c.Assert(s.FS.MkdirAll(dir, 0755), IsNil)
+	}
+
+	from := s.FS.Join("a", "file0")
+	err := util.WriteFile(s.FS, from, benchmarkData(benchmarkSmallFileSize), 0644)
+	c.Assert(err, IsNil)
+
+	c.ResetTimer()
+
+	for i := 1; i <= c.N; i++ {
+		to := s.FS.Join(dirs[i%2], fmt.Sprintf("file%d", i))
+		c.Assert(s.FS.Rename(from, to), IsNil)
+		from = to
+	}
+}
+
+// benchmarkData returns n bytes of content.
+func benchmarkData(n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"os"
+	"path/filepath"
 
 	. "gopkg.in/check.v1"
 	. "gopkg.in/src-d/go-billy.v4"
@@ -118,6 +119,77 @@ func (s *ChrootSuite) TestRemoveOutOffBoundary(c *C) {
 	c.Assert(err, Equals, ErrCrossedBoundary)
 }
 
+func (s *ChrootSuite) TestEscapeAttempts(c *C) {
+	err := util.WriteFile(s.FS, "bar", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	fs, _ := s.FS.Chroot("foo")
+	for _, path := range []string{
+		"..", "../bar", "/..", "/../bar", "/../../bar", "qux/../../bar",
+		"./../bar", "/qux/../../bar", "qux/./../..",
+	} {
+		_, err := fs.Open(path)
+		c.Assert(err, Equals, ErrCrossedBoundary, Commentf("open %s", path))
+
+		_, err = fs.Create(path + "/baz")
+		c.Assert(err, Equals, ErrCrossedBoundary, Commentf("create %s", path))
+
+		_, err = fs.Stat(path)
+		c.Assert(err, Equals, ErrCrossedBoundary, Commentf("stat %s", path))
+
+		err = fs.MkdirAll(path+"/baz", 0755)
+		c.Assert(err, Equals, ErrCrossedBoundary, Commentf("mkdir %s", path))
+
+		err = fs.Remove(path)
+		c.Assert(err, Equals, ErrCrossedBoundary, Commentf("remove %s", path))
+	}
+
+	_, err = s.FS.Stat("baz")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = s.FS.Stat("bar")
+	c.Assert(err, IsNil)
+}
+
+func (s *ChrootSuite) TestEscapeAttemptsWithinBase(c *C) {
+	err := util.WriteFile(s.FS, "foo/bar", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	fs, _ := s.FS.Chroot("foo")
+	for _, path := range []string{"bar", "/bar", "qux/../bar", "/qux/../bar", "./bar"} {
+		f, err := fs.Open(path)
+		c.Assert(err, IsNil, Commentf("open %s", path))
+		c.Assert(f.Close(), IsNil)
+	}
+}
+
+func (s *ChrootSuite) TestSymlinkEscapeAttempts(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
+	err := util.WriteFile(s.FS, "bar", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	fs, _ := s.FS.Chroot("foo")
+	symlink := fs.(Symlink)
+	for _, target := range []string{"../bar", "/../bar", "qux/../../bar", "/qux/../../bar"} {
+		err := symlink.Symlink(target, "link")
+		c.Assert(err, Equals, ErrCrossedBoundary, Commentf("symlink %s", target))
+	}
+
+	err = symlink.Symlink("../../bar", "qux/link")
+	c.Assert(err, Equals, ErrCrossedBoundary)
+
+	c.Assert(symlink.Symlink("../bar", "qux/link"), IsNil)
+	c.Assert(symlink.Symlink("/bar", "link"), IsNil)
+
+	_, err = fs.Lstat("link")
+	c.Assert(err, IsNil)
+
+	target, err := symlink.Readlink("link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, fs.Join(string(filepath.Separator), "bar"))
+}
+
 func (s *FilesystemSuite) TestRoot(c *C) {
 	c.Assert(s.FS.Root(), Not(Equals), "")
 }
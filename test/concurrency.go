@@ -0,0 +1,181 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// concurrency is the number of goroutines used by ConcurrencySuite.
+const concurrency = 16
+
+// ConcurrencySuite is a convenient test suite to validate that an
+// implementation of billy.Filesystem is safe for concurrent use. It should be
+// run with the race detector enabled.
+type ConcurrencySuite struct {
+	FS interface {
+		Basic
+		Dir
+	}
+}
+
+// parallel runs fn concurrently n times, returning the first error found.
+func parallel(n int, fn func(i int) error) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ConcurrencySuite) TestConcurrentCreateAndReadDir(c *C) {
+	err := parallel(concurrency, func(i int) error {
+		if i%2 == 0 {
+			_, err := s.FS.ReadDir("foo")
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		name := s.FS.Join("foo", fmt.Sprintf("file-%d", i))
+		return util.WriteFile(s.FS, name, []byte("foo"), 0644)
+	})
+	c.Assert(err, IsNil)
+
+	l, err := s.FS.ReadDir("foo")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, concurrency/2)
+}
+
+func (s *ConcurrencySuite) TestConcurrentWrite(c *C) {
+	size := 10
+	err := util.WriteFile(s.FS, "foo", make([]byte, size*concurrency), 0644)
+	c.Assert(err, IsNil)
+
+	err = parallel(concurrency, func(i int) error {
+		f, err := s.FS.OpenFile("foo", os.O_RDWR, 0)
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.Seek(int64(i*size), io.SeekStart); err != nil {
+			return err
+		}
+
+		if _, err := f.Write(bytes.Repeat([]byte{byte('a' + i)}, size)); err != nil {
+			return err
+		}
+
+		return f.Close()
+	})
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.Open("foo")
+	c.Assert(err, IsNil)
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	for i := 0; i < concurrency; i++ {
+		expected := bytes.Repeat([]byte{byte('a' + i)}, size)
+		c.Assert(content[i*size:(i+1)*size], DeepEquals, expected)
+	}
+}
+
+func (s *ConcurrencySuite) TestConcurrentReadAndWrite(c *C) {
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = parallel(concurrency, func(i int) error {
+		if i%2 == 0 {
+			return util.WriteFile(s.FS, "foo", []byte("bar"), 0644)
+		}
+
+		f, err := s.FS.Open("foo")
+		if err != nil {
+			return err
+		}
+
+		if _, err := ioutil.ReadAll(f); err != nil {
+			return err
+		}
+
+		return f.Close()
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *ConcurrencySuite) TestConcurrentRenameAndStat(c *C) {
+	for i := 0; i < concurrency; i++ {
+		err := util.WriteFile(s.FS, fmt.Sprintf("file-%d", i), nil, 0644)
+		c.Assert(err, IsNil)
+	}
+
+	err := parallel(concurrency, func(i int) error {
+		from := fmt.Sprintf("file-%d", i)
+		if i%2 == 0 {
+			_, err := s.FS.Stat(from)
+			return err
+		}
+
+		return s.FS.Rename(from, s.FS.Join("dir", from))
+	})
+	c.Assert(err, IsNil)
+
+	l, err := s.FS.ReadDir("dir")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, concurrency/2)
+}
+
+func (s *ConcurrencySuite) TestConcurrentMkdirAll(c *C) {
+	err := parallel(concurrency, func(i int) error {
+		return s.FS.MkdirAll(s.FS.Join("foo", "bar", "qux"), 0755)
+	})
+	c.Assert(err, IsNil)
+
+	fi, err := s.FS.Stat("foo/bar/qux")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+}
+
+func (s *ConcurrencySuite) TestConcurrentRemove(c *C) {
+	for i := 0; i < concurrency; i++ {
+		err := util.WriteFile(s.FS, s.FS.Join("foo", fmt.Sprintf("file-%d", i)), nil, 0644)
+		c.Assert(err, IsNil)
+	}
+
+	err := parallel(concurrency, func(i int) error {
+		return s.FS.Remove(s.FS.Join("foo", fmt.Sprintf("file-%d", i)))
+	})
+	c.Assert(err, IsNil)
+
+	l, err := s.FS.ReadDir("foo")
+	c.Assert(err, IsNil)
+	c.Assert(l, HasLen, 0)
+}
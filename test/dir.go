@@ -1,7 +1,9 @@
 package test
 
 import (
+	"io"
 	"os"
+	"sort"
 	"strconv"
 
 	. "gopkg.in/check.v1"
@@ -136,6 +138,49 @@ func (s *DirSuite) TestReadDir(c *C) {
 	c.Assert(info, HasLen, 2)
 }
 
+func (s *DirSuite) TestReadDirIter(c *C) {
+	files := []string{"a", "b", "c", "d", "qux/baz"}
+	for _, name := range files {
+		err := util.WriteFile(s.FS, name, nil, 0644)
+		c.Assert(err, IsNil)
+	}
+
+	i, err := ReadDirIter(s.FS, "/")
+	c.Assert(err, IsNil)
+
+	var names []string
+	for {
+		entries, err := i.Next(2)
+		if err == io.EOF {
+			c.Assert(entries, HasLen, 0)
+			break
+		}
+
+		c.Assert(err, IsNil)
+		c.Assert(len(entries) > 0 && len(entries) <= 2, Equals, true)
+		for _, fi := range entries {
+			names = append(names, fi.Name())
+		}
+	}
+
+	c.Assert(i.Close(), IsNil)
+	sort.Strings(names)
+	c.Assert(names, DeepEquals, []string{"a", "b", "c", "d", "qux"})
+
+	i, err = ReadDirIter(s.FS, "/qux")
+	c.Assert(err, IsNil)
+	defer i.Close()
+
+	entries, err := i.Next(0)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Name(), Equals, "baz")
+
+	entries, err = i.Next(0)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 0)
+}
+
 func (s *DirSuite) TestReadDirNested(c *C) {
 	max := 100
 	path := "/"
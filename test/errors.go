@@ -0,0 +1,133 @@
+package test
+
+import (
+	"os"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// ErrorsSuite is a convenient test suite to validate that the operations of
+// any implementation of billy.Filesystem fail with errors wrapped in a
+// *os.PathError or *os.LinkError, classified by the predicates of the os and
+// billy packages.
+type ErrorsSuite struct {
+	FS Filesystem
+}
+
+// assertError checks that err is wrapped in a *os.PathError or *os.LinkError,
+// and satisfies is.
+func assertError(c *C, err error, is func(error) bool, op string) {
+	c.Assert(err, NotNil, Commentf("%s", op))
+
+	switch err.(type) {
+	case *os.PathError, *os.LinkError:
+	default:
+		c.Fatalf("%s: unwrapped error %T: %v", op, err, err)
+	}
+
+	c.Assert(is(err), Equals, true, Commentf("%s: %v", op, err))
+}
+
+func (s *ErrorsSuite) TestNotExist(c *C) {
+	_, err := s.FS.Open("foo")
+	assertError(c, err, os.IsNotExist, "open")
+
+	_, err = s.FS.OpenFile("foo", os.O_RDWR, 0)
+	assertError(c, err, os.IsNotExist, "openfile")
+
+	_, err = s.FS.Stat("foo")
+	assertError(c, err, os.IsNotExist, "stat")
+
+	_, err = s.FS.Lstat("foo")
+	assertError(c, err, os.IsNotExist, "lstat")
+
+	_, err = s.FS.Open("foo/bar")
+	assertError(c, err, os.IsNotExist, "open nested")
+
+	_, err = s.FS.ReadDir("foo")
+	assertError(c, err, os.IsNotExist, "readdir")
+}
+
+func (s *ErrorsSuite) TestNotExistWrite(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := s.FS.Remove("foo")
+	assertError(c, err, os.IsNotExist, "remove")
+
+	err = s.FS.Rename("foo", "bar")
+	assertError(c, err, os.IsNotExist, "rename")
+}
+
+func (s *ErrorsSuite) TestNotExistSymlink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
+	_, err := s.FS.Readlink("foo")
+	assertError(c, err, os.IsNotExist, "readlink")
+}
+
+func (s *ErrorsSuite) TestExist(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.OpenFile("foo", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	assertError(c, err, os.IsExist, "openfile")
+}
+
+func (s *ErrorsSuite) TestExistSymlink(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|SymlinkCapability)
+
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Symlink("bar", "foo")
+	assertError(c, err, os.IsExist, "symlink")
+}
+
+func (s *ErrorsSuite) TestNotDir(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.OpenFile("foo/bar", os.O_WRONLY|os.O_CREATE, 0644)
+	assertError(c, err, IsNotDir, "create")
+
+	err = s.FS.MkdirAll("foo/bar", 0755)
+	assertError(c, err, IsNotDir, "mkdirall")
+
+	err = s.FS.MkdirAll("foo", 0755)
+	assertError(c, err, IsNotDir, "mkdirall file")
+
+	_, err = s.FS.ReadDir("foo")
+	assertError(c, err, IsNotDir, "readdir")
+
+	_, err = s.FS.TempFile("foo", "bar")
+	assertError(c, err, IsNotDir, "tempfile")
+}
+
+func (s *ErrorsSuite) TestIsDir(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := s.FS.MkdirAll("foo", 0755)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.OpenFile("foo", os.O_WRONLY, 0)
+	assertError(c, err, IsDir, "openfile")
+
+	_, err = s.FS.OpenFile("foo", os.O_WRONLY|os.O_CREATE, 0644)
+	assertError(c, err, IsDir, "create")
+}
+
+func (s *ErrorsSuite) TestNotEmpty(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo/bar", nil, 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Remove("foo")
+	assertError(c, err, IsNotEmpty, "remove")
+}
@@ -18,6 +18,12 @@ type FilesystemSuite struct {
 	SymlinkSuite
 	TempFileSuite
 	ChrootSuite
+	ConcurrencySuite
+	SeekSuite
+	OpenFileSuite
+	ErrorsSuite
+	PermissionSuite
+	XattrSuite
 }
 
 // NewFilesystemSuite returns a new FilesystemSuite based on the given fs.
@@ -28,11 +34,27 @@ func NewFilesystemSuite(fs Filesystem) FilesystemSuite {
 	s.SymlinkSuite.FS = s.FS
 	s.TempFileSuite.FS = s.FS
 	s.ChrootSuite.FS = s.FS
+	s.ConcurrencySuite.FS = s.FS
+	s.SeekSuite.FS = s.FS
+	s.OpenFileSuite.FS = s.FS
+	s.ErrorsSuite.FS = s.FS
+	s.PermissionSuite.FS = s.FS
+	s.XattrSuite.FS = s.FS
 
 	return s
 }
 
+// skipIfNotCapable skips the running test if the filesystem lacks any of the
+// given capabilities.
+func skipIfNotCapable(c *C, fs Basic, caps Capability) {
+	if !CapabilityCheck(fs, caps) {
+		c.Skip("filesystem does not support the required capabilities")
+	}
+}
+
 func (s *FilesystemSuite) TestSymlinkToDir(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := s.FS.MkdirAll("dir", 0755)
 	c.Assert(err, IsNil)
 
@@ -46,6 +68,8 @@ func (s *FilesystemSuite) TestSymlinkToDir(c *C) {
 }
 
 func (s *FilesystemSuite) TestSymlinkReadDir(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "dir/file", []byte("foo"), 0644)
 	c.Assert(err, IsNil)
 
@@ -85,6 +109,8 @@ func (s *ChrootSuite) TestReadDirWithChroot(c *C) {
 }
 
 func (s *FilesystemSuite) TestSymlinkWithChrootBasic(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	qux, _ := s.FS.Chroot("/qux")
 
 	err := util.WriteFile(qux, "file", nil, 0644)
@@ -103,18 +129,22 @@ func (s *FilesystemSuite) TestSymlinkWithChrootBasic(c *C) {
 }
 
 func (s *FilesystemSuite) TestSymlinkWithChrootCrossBounders(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	qux, _ := s.FS.Chroot("/qux")
 	util.WriteFile(s.FS, "file", []byte("foo"), customMode)
 
 	err := qux.Symlink("../../file", "qux/link")
-	c.Assert(err, Equals, nil)
+	c.Assert(err, Equals, ErrCrossedBoundary)
 
-	fi, err := qux.Stat("qux/link")
-	c.Assert(fi, NotNil)
-	c.Assert(err, Equals, nil)
+	fi, err := qux.Lstat("qux/link")
+	c.Assert(fi, IsNil)
+	c.Assert(os.IsNotExist(err), Equals, true)
 }
 
 func (s *FilesystemSuite) TestReadDirWithLink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	util.WriteFile(s.FS, "foo/bar", []byte("foo"), customMode)
 	s.FS.Symlink("bar", "foo/qux")
 
@@ -205,3 +235,87 @@ func (s *FilesystemSuite) TestReadDir(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(info, HasLen, 2)
 }
+
+func (s *FilesystemSuite) TestRenameDirTree(c *C) {
+	fnames := []string{
+		"foo/1",
+		"foo/bar/1",
+		"foo/bar/baz/1",
+		"foo/bar/baz/qux/1",
+		"foobar/1",
+	}
+
+	for _, fname := range fnames {
+		err := util.WriteFile(s.FS, fname, []byte(fname), 0644)
+		c.Assert(err, IsNil)
+	}
+
+	c.Assert(s.FS.Rename("foo", "qux/foo"), IsNil)
+
+	for _, fname := range fnames[:4] {
+		_, err := s.FS.Stat(fname)
+		comment := Commentf("not moved: %s %s", fname, err)
+		c.Assert(os.IsNotExist(err), Equals, true, comment)
+
+		fi, err := s.FS.Stat(s.FS.Join("qux", fname))
+		c.Assert(err, IsNil)
+		c.Assert(fi.Size(), Equals, int64(len(fname)))
+	}
+
+	_, err := s.FS.Stat("foobar/1")
+	c.Assert(err, IsNil)
+
+	info, err := s.FS.ReadDir("qux/foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(info, HasLen, 2)
+}
+
+func (s *FilesystemSuite) TestRenameFileOntoDir(c *C) {
+	c.Assert(util.WriteFile(s.FS, "a", []byte("a"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "d/x", []byte("x"), 0644), IsNil)
+
+	err := s.FS.Rename("a", "d")
+	c.Assert(err, NotNil)
+
+	s.assertSize(c, "a", 1)
+	s.assertSize(c, "d/x", 1)
+}
+
+func (s *FilesystemSuite) TestRenameDirOntoNonEmptyDir(c *C) {
+	c.Assert(util.WriteFile(s.FS, "a/y", []byte("y"), 0644), IsNil)
+	c.Assert(util.WriteFile(s.FS, "d/x", []byte("x"), 0644), IsNil)
+
+	err := s.FS.Rename("a", "d")
+	c.Assert(err, NotNil)
+
+	s.assertSize(c, "a/y", 1)
+	s.assertSize(c, "d/x", 1)
+}
+
+func (s *FilesystemSuite) TestRenameOntoParent(c *C) {
+	c.Assert(util.WriteFile(s.FS, "p/q/x", []byte("x"), 0644), IsNil)
+
+	err := s.FS.Rename(s.FS.Join("p", "q"), "p")
+	c.Assert(err, NotNil)
+
+	s.assertSize(c, "p/q/x", 1)
+}
+
+func (s *FilesystemSuite) TestRenameIntoSubtree(c *C) {
+	c.Assert(util.WriteFile(s.FS, "a/x", []byte("x"), 0644), IsNil)
+
+	err := s.FS.Rename("a", s.FS.Join("a", "b"))
+	c.Assert(err, NotNil)
+
+	s.assertSize(c, "a/x", 1)
+
+	_, err = s.FS.Stat(s.FS.Join("a", "b"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+// assertSize asserts the file at path exists and has the given size.
+func (s *FilesystemSuite) assertSize(c *C, path string, size int64) {
+	fi, err := s.FS.Stat(path)
+	c.Assert(err, IsNil)
+	c.Assert(fi.Size(), Equals, size)
+}
@@ -57,6 +57,7 @@ func (fs *BasicMock) Join(elem ...string) string {
 type TempFileMock struct {
 	BasicMock
 	TempFileArgs [][2]string
+	TempDirArgs  [][2]string
 }
 
 func (fs *TempFileMock) TempFile(dir, prefix string) (billy.File, error) {
@@ -64,6 +65,11 @@ func (fs *TempFileMock) TempFile(dir, prefix string) (billy.File, error) {
 	return &FileMock{name: "/tmp/hardcoded/mock/temp"}, nil
 }
 
+func (fs *TempFileMock) TempDir(dir, prefix string) (string, error) {
+	fs.TempDirArgs = append(fs.TempDirArgs, [2]string{dir, prefix})
+	return "/tmp/hardcoded/mock/tempdir", nil
+}
+
 type DirMock struct {
 	BasicMock
 	ReadDirArgs  []string
@@ -154,3 +160,37 @@ func (o *NoLockCapFs) Capabilities() billy.Capability {
 		billy.SeekCapability |
 		billy.TruncateCapability
 }
+
+type DescribedFs struct {
+	BasicMock
+	Description billy.Description
+}
+
+func (o *DescribedFs) Describe() billy.Description {
+	return o.Description
+}
+
+type OptionOpenerFs struct {
+	BasicMock
+	OpenFileOptArgs [][]billy.OpenOption
+}
+
+func (fs *OptionOpenerFs) OpenFileOpt(filename string, flag int, mode os.FileMode, opts ...billy.OpenOption) (billy.File, error) {
+	fs.OpenFileOptArgs = append(fs.OpenFileOptArgs, opts)
+	return fs.OpenFile(filename, flag, mode)
+}
+
+type BulkRemoverMock struct {
+	BasicMock
+	RemoveManyArgs [][]string
+	Errors         map[string]error
+}
+
+func (fs *BulkRemoverMock) RemoveMany(paths []string) error {
+	fs.RemoveManyArgs = append(fs.RemoveManyArgs, paths)
+	if len(fs.Errors) > 0 {
+		return &billy.RemoveManyError{Errors: fs.Errors}
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// OpenFileSuite is a convenient test suite to validate the flags of the
+// OpenFile of any implementation of billy.Basic, with the combinations used
+// by go-git.
+type OpenFileSuite struct {
+	FS Basic
+}
+
+func (s *OpenFileSuite) assertContent(c *C, filename, expected string) {
+	content, err := readFile(s.FS, filename)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, expected)
+}
+
+func (s *OpenFileSuite) TestOpenFileNotExist(c *C) {
+	for _, flag := range []int{
+		os.O_RDONLY,
+		os.O_WRONLY,
+		os.O_RDWR,
+		os.O_WRONLY | os.O_TRUNC,
+		os.O_WRONLY | os.O_APPEND,
+		os.O_RDWR | os.O_EXCL,
+	} {
+		_, err := s.FS.OpenFile("foo", flag, 0644)
+		c.Assert(os.IsNotExist(err), Equals, true, Commentf("flag %#o: %v", flag, err))
+	}
+}
+
+func (s *OpenFileSuite) TestOpenFileCreate(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	for i, flag := range []int{
+		os.O_WRONLY | os.O_CREATE,
+		os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+		os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+		os.O_WRONLY | os.O_CREATE | os.O_EXCL,
+		os.O_RDWR | os.O_CREATE | os.O_EXCL,
+	} {
+		filename := s.FS.Join("dir", string(rune('a'+i)))
+		f, err := s.FS.OpenFile(filename, flag, 0644)
+		c.Assert(err, IsNil, Commentf("flag %#o", flag))
+		_, err = f.Write([]byte("foo"))
+		c.Assert(err, IsNil)
+		c.Assert(f.Close(), IsNil)
+
+		s.assertContent(c, filename, "foo")
+	}
+}
+
+func (s *OpenFileSuite) TestOpenFileExcl(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	for _, flag := range []int{
+		os.O_WRONLY | os.O_CREATE | os.O_EXCL,
+		os.O_RDWR | os.O_CREATE | os.O_EXCL,
+		os.O_RDWR | os.O_CREATE | os.O_EXCL | os.O_TRUNC,
+	} {
+		_, err := s.FS.OpenFile("foo", flag, 0644)
+		c.Assert(os.IsExist(err), Equals, true, Commentf("flag %#o: %v", flag, err))
+	}
+
+	s.assertContent(c, "foo", "foo")
+}
+
+func (s *OpenFileSuite) TestOpenFileExclSymlink(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|SymlinkCapability)
+
+	sl, ok := s.FS.(Symlink)
+	if !ok {
+		c.Skip("filesystem does not implement Symlink")
+	}
+
+	c.Assert(sl.Symlink("target", "link"), IsNil)
+
+	_, err := s.FS.OpenFile("link", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	c.Assert(os.IsExist(err), Equals, true, Commentf("%v", err))
+
+	_, err = s.FS.Stat("target")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *OpenFileSuite) TestOpenFileTrunc(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	for _, flag := range []int{
+		os.O_WRONLY | os.O_TRUNC,
+		os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+		os.O_RDWR | os.O_CREATE | os.O_TRUNC,
+	} {
+		err := util.WriteFile(s.FS, "foo", []byte("foobar"), 0644)
+		c.Assert(err, IsNil)
+
+		f, err := s.FS.OpenFile("foo", flag, 0644)
+		c.Assert(err, IsNil, Commentf("flag %#o", flag))
+		_, err = f.Write([]byte("qux"))
+		c.Assert(err, IsNil)
+		c.Assert(f.Close(), IsNil)
+
+		s.assertContent(c, "foo", "qux")
+	}
+}
+
+func (s *OpenFileSuite) TestOpenFileWithoutTrunc(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|ReadAndWriteCapability)
+
+	for _, flag := range []int{
+		os.O_WRONLY,
+		os.O_RDWR | os.O_CREATE,
+	} {
+		err := util.WriteFile(s.FS, "foo", []byte("foobar"), 0644)
+		c.Assert(err, IsNil)
+
+		f, err := s.FS.OpenFile("foo", flag, 0644)
+		c.Assert(err, IsNil, Commentf("flag %#o", flag))
+		_, err = f.Write([]byte("qux"))
+		c.Assert(err, IsNil)
+		c.Assert(f.Close(), IsNil)
+
+		s.assertContent(c, "foo", "quxbar")
+	}
+}
+
+func (s *OpenFileSuite) TestOpenFileAppend(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|ReadAndWriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", os.O_WRONLY|os.O_APPEND, 0644)
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+	_, err = f.Write([]byte("qux"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	s.assertContent(c, "foo", "foobarqux")
+}
+
+func (s *OpenFileSuite) TestOpenFileAppendCreate(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|ReadAndWriteCapability)
+
+	for i := 0; i < 2; i++ {
+		f, err := s.FS.OpenFile("foo", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		c.Assert(err, IsNil)
+		_, err = f.Write([]byte("foo"))
+		c.Assert(err, IsNil)
+		c.Assert(f.Close(), IsNil)
+	}
+
+	s.assertContent(c, "foo", "foofoo")
+}
+
+func (s *OpenFileSuite) TestOpenFileReadOnly(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", os.O_RDONLY, 0)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, NotNil)
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *OpenFileSuite) TestOpenFileReadOnlyCreate(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	f, err := s.FS.OpenFile("foo", os.O_RDONLY|os.O_CREATE, 0644)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, NotNil)
+
+	if CapabilityCheck(s.FS, TruncateCapability) {
+		c.Assert(f.Truncate(0), NotNil)
+	}
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "")
+
+	err = util.WriteFile(s.FS, "bar", []byte("bar"), 0644)
+	c.Assert(err, IsNil)
+
+	g, err := s.FS.OpenFile("bar", os.O_RDONLY|os.O_CREATE, 0644)
+	c.Assert(err, IsNil)
+	defer g.Close()
+
+	content, err = ioutil.ReadAll(g)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "bar")
+}
+
+func (s *OpenFileSuite) TestOpenFileReadOnlyTrunc(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", os.O_RDONLY|os.O_TRUNC, 0)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.Write([]byte("bar"))
+	c.Assert(err, NotNil)
+
+	_, err = ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+}
+
+func (s *OpenFileSuite) TestOpenFileWriteOnly(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|ReadAndWriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", os.O_WRONLY, 0)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	_, err = f.Read(make([]byte, 3))
+	c.Assert(err, NotNil)
+}
+
+func (s *OpenFileSuite) TestOpenFileReadWrite(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|ReadAndWriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foobar"), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", os.O_RDWR, 0)
+	c.Assert(err, IsNil)
+
+	b := make([]byte, 3)
+	_, err = f.Read(b)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "foo")
+
+	_, err = f.Write([]byte("qux"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	s.assertContent(c, "foo", "fooqux")
+}
+
+func (s *OpenFileSuite) TestOpenFileKeepsMode(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	before, err := s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", os.O_WRONLY|os.O_CREATE, 0600)
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	after, err := s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(after.Mode(), Equals, before.Mode())
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"os"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// PermissionSuite is a convenient test suite to validate that any
+// implementation of billy.Filesystem declaring the PermissionCapability
+// enforces the permissions of the files and directories, as the ones of their
+// owner.
+type PermissionSuite struct {
+	FS Filesystem
+}
+
+func (s *PermissionSuite) TestOpenReadOnlyFile(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|PermissionCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0444)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.OpenFile("foo", os.O_WRONLY, 0)
+	assertError(c, err, os.IsPermission, "openfile")
+
+	_, err = s.FS.OpenFile("foo", os.O_RDWR, 0)
+	assertError(c, err, os.IsPermission, "openfile")
+
+	_, err = s.FS.Create("foo")
+	assertError(c, err, os.IsPermission, "create")
+
+	content, err := readFile(s.FS, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo")
+}
+
+func (s *PermissionSuite) TestOpenWriteOnlyFile(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|PermissionCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0200)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Open("foo")
+	assertError(c, err, os.IsPermission, "open")
+
+	f, err := s.FS.OpenFile("foo", os.O_WRONLY|os.O_APPEND, 0)
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *PermissionSuite) TestCreateInReadOnlyDir(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|PermissionCapability)
+
+	err := s.FS.MkdirAll("dir", 0555)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Create(s.FS.Join("dir", "foo"))
+	assertError(c, err, os.IsPermission, "create")
+
+	err = s.FS.MkdirAll(s.FS.Join("dir", "bar"), 0755)
+	assertError(c, err, os.IsPermission, "mkdirall")
+
+	_, err = s.FS.Stat(s.FS.Join("dir", "foo"))
+	assertError(c, err, os.IsNotExist, "stat")
+}
+
+func (s *PermissionSuite) TestReadDirWithoutRead(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|PermissionCapability)
+
+	err := s.FS.MkdirAll("dir", 0300)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.ReadDir("dir")
+	assertError(c, err, os.IsPermission, "readdir")
+}
+
+func (s *PermissionSuite) TestStatWithoutSearch(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|PermissionCapability)
+
+	err := s.FS.MkdirAll("dir", 0600)
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat(s.FS.Join("dir", "foo"))
+	assertError(c, err, os.IsPermission, "stat")
+
+	_, err = s.FS.Open(s.FS.Join("dir", "foo"))
+	assertError(c, err, os.IsPermission, "open")
+
+	_, err = s.FS.Create(s.FS.Join("dir", "foo"))
+	assertError(c, err, os.IsPermission, "create")
+}
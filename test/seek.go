@@ -0,0 +1,184 @@
+package test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// SeekSuite is a convenient test suite to validate the io.Seeker semantics of
+// the files of any implementation of billy.Basic, as the ones of os.File: the
+// offsets are relative to the start, the current offset or the end, seeking
+// past the end is allowed, reading there returns io.EOF and writing there
+// fills the gap with zeros, and seeking to a negative offset fails.
+type SeekSuite struct {
+	FS Basic
+}
+
+// create writes the given content to foo, and opens it with flag.
+func (s *SeekSuite) create(c *C, content string, flag int) File {
+	err := util.WriteFile(s.FS, "foo", []byte(content), 0644)
+	c.Assert(err, IsNil)
+
+	f, err := s.FS.OpenFile("foo", flag, 0)
+	c.Assert(err, IsNil)
+	return f
+}
+
+func (s *SeekSuite) assertSeek(c *C, f File, offset int64, whence int, expected int64) {
+	p, err := f.Seek(offset, whence)
+	c.Assert(err, IsNil)
+	c.Assert(p, Equals, expected)
+}
+
+func (s *SeekSuite) assertRead(c *C, f File, expected string) {
+	b := make([]byte, len(expected))
+	_, err := io.ReadFull(f, b)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, expected)
+}
+
+func (s *SeekSuite) TestSeekWhence(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability)
+
+	f := s.create(c, "0123456789", os.O_RDONLY)
+	defer f.Close()
+
+	s.assertSeek(c, f, 2, io.SeekStart, 2)
+	s.assertRead(c, f, "23")
+	s.assertSeek(c, f, 0, io.SeekCurrent, 4)
+	s.assertSeek(c, f, 3, io.SeekCurrent, 7)
+	s.assertRead(c, f, "7")
+	s.assertSeek(c, f, -4, io.SeekCurrent, 4)
+	s.assertRead(c, f, "4")
+	s.assertSeek(c, f, 0, io.SeekEnd, 10)
+	s.assertSeek(c, f, -3, io.SeekEnd, 7)
+	s.assertRead(c, f, "789")
+}
+
+func (s *SeekSuite) TestSeekAfterWrite(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability|ReadAndWriteCapability)
+
+	f := s.create(c, "0123456789", os.O_RDWR)
+	defer f.Close()
+
+	_, err := f.Write([]byte("abc"))
+	c.Assert(err, IsNil)
+	s.assertSeek(c, f, 0, io.SeekCurrent, 3)
+	s.assertRead(c, f, "34")
+
+	s.assertSeek(c, f, 0, io.SeekEnd, 10)
+	_, err = f.Write([]byte("xyz"))
+	c.Assert(err, IsNil)
+	s.assertSeek(c, f, 0, io.SeekCurrent, 13)
+	s.assertSeek(c, f, 0, io.SeekEnd, 13)
+	s.assertSeek(c, f, -6, io.SeekEnd, 7)
+	s.assertRead(c, f, "789xyz")
+}
+
+func (s *SeekSuite) TestSeekPastEnd(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability)
+
+	f := s.create(c, "foo", os.O_RDONLY)
+	defer f.Close()
+
+	s.assertSeek(c, f, 10, io.SeekStart, 10)
+
+	b := make([]byte, 3)
+	n, err := f.Read(b)
+	c.Assert(err, Equals, io.EOF)
+	c.Assert(n, Equals, 0)
+
+	n, err = f.ReadAt(b, 10)
+	c.Assert(err, Equals, io.EOF)
+	c.Assert(n, Equals, 0)
+
+	s.assertSeek(c, f, 0, io.SeekCurrent, 10)
+	s.assertSeek(c, f, 1, io.SeekStart, 1)
+	s.assertRead(c, f, "oo")
+}
+
+func (s *SeekSuite) TestSeekPastEndAndWrite(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability|ReadAndWriteCapability)
+
+	f := s.create(c, "foo", os.O_RDWR)
+	s.assertSeek(c, f, 3, io.SeekCurrent, 3)
+	s.assertSeek(c, f, 3, io.SeekEnd, 6)
+
+	n, err := f.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 3)
+	s.assertSeek(c, f, 0, io.SeekEnd, 9)
+	c.Assert(f.Close(), IsNil)
+
+	content, err := readFile(s.FS, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foo\x00\x00\x00bar")
+}
+
+func (s *SeekSuite) TestSeekNegative(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability)
+
+	f := s.create(c, "foo", os.O_RDONLY)
+	defer f.Close()
+
+	s.assertSeek(c, f, 1, io.SeekStart, 1)
+
+	_, err := f.Seek(-1, io.SeekStart)
+	c.Assert(err, NotNil)
+	_, err = f.Seek(-2, io.SeekCurrent)
+	c.Assert(err, NotNil)
+	_, err = f.Seek(-4, io.SeekEnd)
+	c.Assert(err, NotNil)
+
+	s.assertSeek(c, f, 0, io.SeekCurrent, 1)
+	s.assertRead(c, f, "oo")
+}
+
+func (s *SeekSuite) TestSeekInvalidWhence(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability)
+
+	for _, flag := range []int{os.O_RDONLY, os.O_RDWR | os.O_CREATE | os.O_TRUNC} {
+		f := s.create(c, "foo", flag)
+
+		_, err := f.Seek(0, 42)
+		c.Assert(err, NotNil, Commentf("flag %#o", flag))
+		s.assertSeek(c, f, 0, io.SeekCurrent, 0)
+		c.Assert(f.Close(), IsNil)
+	}
+}
+
+func (s *SeekSuite) TestSeekAppend(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability|WriteCapability)
+
+	f := s.create(c, "foo", os.O_WRONLY|os.O_APPEND)
+	s.assertSeek(c, f, 0, io.SeekStart, 0)
+
+	_, err := f.Write([]byte("bar"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	content, err := readFile(s.FS, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "foobar")
+}
+
+func (s *SeekSuite) TestSeekReadAll(c *C) {
+	skipIfNotCapable(c, s.FS, SeekCapability)
+
+	f := s.create(c, "0123456789", os.O_RDONLY)
+	defer f.Close()
+
+	all, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(all), Equals, "0123456789")
+
+	s.assertSeek(c, f, -5, io.SeekCurrent, 5)
+	all, err = ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(all), Equals, "56789")
+}
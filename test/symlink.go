@@ -1,6 +1,7 @@
 package test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 
@@ -19,6 +20,8 @@ type SymlinkSuite struct {
 }
 
 func (s *SymlinkSuite) TestSymlink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "file", nil, 0644)
 	c.Assert(err, IsNil)
 
@@ -31,6 +34,8 @@ func (s *SymlinkSuite) TestSymlink(c *C) {
 }
 
 func (s *SymlinkSuite) TestSymlinkCrossDirs(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "foo/file", nil, 0644)
 	c.Assert(err, IsNil)
 
@@ -43,6 +48,8 @@ func (s *SymlinkSuite) TestSymlinkCrossDirs(c *C) {
 }
 
 func (s *SymlinkSuite) TestSymlinkNested(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "file", []byte("hello world!"), 0644)
 	c.Assert(err, IsNil)
 
@@ -59,6 +66,8 @@ func (s *SymlinkSuite) TestSymlinkNested(c *C) {
 }
 
 func (s *SymlinkSuite) TestSymlinkWithNonExistentdTarget(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := s.FS.Symlink("file", "link")
 	c.Assert(err, IsNil)
 
@@ -67,6 +76,8 @@ func (s *SymlinkSuite) TestSymlinkWithNonExistentdTarget(c *C) {
 }
 
 func (s *SymlinkSuite) TestSymlinkWithExistingLink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "link", nil, 0644)
 	c.Assert(err, IsNil)
 
@@ -75,6 +86,8 @@ func (s *SymlinkSuite) TestSymlinkWithExistingLink(c *C) {
 }
 
 func (s *SymlinkSuite) TestOpenWithSymlinkToRelativePath(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "dir/file", []byte("foo"), 0644)
 	c.Assert(err, IsNil)
 
@@ -91,6 +104,8 @@ func (s *SymlinkSuite) TestOpenWithSymlinkToRelativePath(c *C) {
 }
 
 func (s *SymlinkSuite) TestOpenWithSymlinkToAbsolutePath(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "dir/file", []byte("foo"), 0644)
 	c.Assert(err, IsNil)
 
@@ -107,6 +122,8 @@ func (s *SymlinkSuite) TestOpenWithSymlinkToAbsolutePath(c *C) {
 }
 
 func (s *SymlinkSuite) TestReadlink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "file", nil, 0644)
 	c.Assert(err, IsNil)
 
@@ -115,6 +132,8 @@ func (s *SymlinkSuite) TestReadlink(c *C) {
 }
 
 func (s *SymlinkSuite) TestReadlinkWithRelativePath(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "dir/file", nil, 0644)
 	c.Assert(err, IsNil)
 
@@ -127,6 +146,8 @@ func (s *SymlinkSuite) TestReadlinkWithRelativePath(c *C) {
 }
 
 func (s *SymlinkSuite) TestReadlinkWithAbsolutePath(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "dir/file", nil, 0644)
 	c.Assert(err, IsNil)
 
@@ -139,6 +160,8 @@ func (s *SymlinkSuite) TestReadlinkWithAbsolutePath(c *C) {
 }
 
 func (s *SymlinkSuite) TestReadlinkWithNonExistentTarget(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := s.FS.Symlink("file", "link")
 	c.Assert(err, IsNil)
 
@@ -148,11 +171,15 @@ func (s *SymlinkSuite) TestReadlinkWithNonExistentTarget(c *C) {
 }
 
 func (s *SymlinkSuite) TestReadlinkWithNonExistentLink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	_, err := s.FS.Readlink("link")
 	c.Assert(os.IsNotExist(err), Equals, true)
 }
 
 func (s *SymlinkSuite) TestStatLink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	util.WriteFile(s.FS, "foo/bar", []byte("foo"), customMode)
 	s.FS.Symlink("bar", "foo/qux")
 
@@ -166,6 +193,8 @@ func (s *SymlinkSuite) TestStatLink(c *C) {
 }
 
 func (s *SymlinkSuite) TestLstat(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	util.WriteFile(s.FS, "foo/bar", []byte("foo"), customMode)
 
 	fi, err := s.FS.Lstat("foo/bar")
@@ -178,6 +207,8 @@ func (s *SymlinkSuite) TestLstat(c *C) {
 }
 
 func (s *SymlinkSuite) TestLstatLink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	util.WriteFile(s.FS, "foo/bar", []byte("fosddddaaao"), customMode)
 	s.FS.Symlink("bar", "foo/qux")
 
@@ -190,6 +221,8 @@ func (s *SymlinkSuite) TestLstatLink(c *C) {
 }
 
 func (s *SymlinkSuite) TestRenameWithSymlink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := s.FS.Symlink("file", "link")
 	c.Assert(err, IsNil)
 
@@ -201,6 +234,8 @@ func (s *SymlinkSuite) TestRenameWithSymlink(c *C) {
 }
 
 func (s *SymlinkSuite) TestRemoveWithSymlink(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
 	err := util.WriteFile(s.FS, "file", []byte("foo"), 0644)
 	c.Assert(err, IsNil)
 
@@ -219,3 +254,94 @@ func (s *SymlinkSuite) TestRemoveWithSymlink(c *C) {
 	_, err = s.FS.Stat("file")
 	c.Assert(err, IsNil)
 }
+
+func (s *SymlinkSuite) TestSymlinkLoop(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
+	err := s.FS.Symlink("b", "a")
+	c.Assert(err, IsNil)
+
+	err = s.FS.Symlink("a", "b")
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("a")
+	assertError(c, err, IsTooManyLinks, "stat")
+
+	_, err = s.FS.Open("a")
+	assertError(c, err, IsTooManyLinks, "open")
+
+	fi, err := s.FS.Lstat("a")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode()&os.ModeSymlink != 0, Equals, true)
+
+	target, err := s.FS.Readlink("b")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "a")
+}
+
+func (s *SymlinkSuite) TestSymlinkSelfLoop(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
+	err := s.FS.Symlink("self", "self")
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("self")
+	assertError(c, err, IsTooManyLinks, "stat")
+
+	_, err = s.FS.Open("self")
+	assertError(c, err, IsTooManyLinks, "open")
+
+	err = s.FS.Remove("self")
+	c.Assert(err, IsNil)
+}
+
+func (s *SymlinkSuite) TestSymlinkChain(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
+	err := util.WriteFile(s.FS, "file", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	target := "file"
+	for i := 0; i < 20; i++ {
+		link := fmt.Sprintf("link%d", i)
+		err = s.FS.Symlink(target, link)
+		c.Assert(err, IsNil)
+		target = link
+	}
+
+	fi, err := s.FS.Stat(target)
+	c.Assert(err, IsNil)
+	c.Assert(fi.Size(), Equals, int64(3))
+}
+
+func (s *SymlinkSuite) TestSymlinkChainMax(c *C) {
+	skipIfNotCapable(c, s.FS, SymlinkCapability)
+
+	err := util.WriteFile(s.FS, "file", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	target := "file"
+	for i := 0; i < MaxSymlinks; i++ {
+		link := fmt.Sprintf("link%d", i)
+		err = s.FS.Symlink(target, link)
+		c.Assert(err, IsNil)
+		target = link
+	}
+
+	fi, err := s.FS.Stat(target)
+	c.Assert(err, IsNil)
+	c.Assert(fi.Size(), Equals, int64(3))
+
+	f, err := s.FS.Open(target)
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	err = s.FS.Symlink(target, "link")
+	c.Assert(err, IsNil)
+
+	_, err = s.FS.Stat("link")
+	assertError(c, err, IsTooManyLinks, "stat")
+
+	_, err = s.FS.Open("link")
+	assertError(c, err, IsTooManyLinks, "open")
+}
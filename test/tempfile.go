@@ -25,6 +25,13 @@ func (s *TempFileSuite) TestTempFile(c *C) {
 	c.Assert(strings.Index(f.Name(), "bar"), Not(Equals), -1)
 }
 
+func (s *TempFileSuite) TestTempFileSync(c *C) {
+	f, err := s.FS.TempFile("", "bar")
+	c.Assert(err, IsNil)
+	c.Assert(billy.Sync(f), IsNil)
+	c.Assert(f.Close(), IsNil)
+}
+
 func (s *TempFileSuite) TestTempFileWithPath(c *C) {
 	f, err := s.FS.TempFile("foo", "bar")
 	c.Assert(err, IsNil)
@@ -92,3 +99,38 @@ func (s *TempFileSuite) TestTempFileManyWithUtil(c *C) {
 		}
 	}
 }
+
+func (s *TempFileSuite) TestTempDir(c *C) {
+	name, err := s.FS.TempDir("", "bar")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Index(name, "bar"), Not(Equals), -1)
+
+	fi, err := s.FS.Stat(name)
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+	c.Assert(s.FS.Remove(name), IsNil)
+}
+
+func (s *TempFileSuite) TestTempDirWithPath(c *C) {
+	name, err := s.FS.TempDir("foo", "bar")
+	c.Assert(err, IsNil)
+	c.Assert(strings.HasPrefix(name, s.FS.Join("foo", "bar")), Equals, true)
+
+	f, err := s.FS.Create(s.FS.Join(name, "qux"))
+	c.Assert(err, IsNil)
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *TempFileSuite) TestTempDirMany(c *C) {
+	names := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		name, err := s.FS.TempDir("test-dir", "test-prefix")
+		c.Assert(err, IsNil)
+		c.Assert(names[name], Equals, false)
+		names[name] = true
+	}
+
+	for name := range names {
+		c.Assert(s.FS.Remove(name), IsNil)
+	}
+}
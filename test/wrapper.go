@@ -0,0 +1,396 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// wrapperFixtures are the files created in the filesystems of a WrapperSuite
+// before wrapping them up.
+var wrapperFixtures = map[string]string{
+	"foo":         "foo",
+	"empty":       "",
+	"dir/bar":     "bar",
+	"dir/qux/baz": "baz",
+}
+
+// WrapperSuite is a convenient test suite to validate any helper wrapping up a
+// billy.Filesystem. Every operation is done on the wrapper and on a reference
+// filesystem, comparing their results, so a wrapper passes as long as it
+// preserves the semantics of the filesystem it wraps up. The operations
+// requiring a capability missing in the wrapper are skipped, but the wrapper
+// can't claim a capability that the reference doesn't have.
+type WrapperSuite struct {
+	// New returns a new empty filesystem, used as reference and as the
+	// filesystem wrapped up.
+	New func() Filesystem
+	// Wrap returns the wrapper to validate, wrapping up fs. The fixtures are
+	// already created in fs when it's called.
+	Wrap func(fs Filesystem) Filesystem
+
+	ref Filesystem
+	fs  Filesystem
+}
+
+// NewWrapperSuite returns a new WrapperSuite validating the wrapper returned by
+// wrap, against the filesystems returned by new.
+func NewWrapperSuite(new func() Filesystem, wrap func(fs Filesystem) Filesystem) WrapperSuite {
+	return WrapperSuite{New: new, Wrap: wrap}
+}
+
+func (s *WrapperSuite) SetUpTest(c *C) {
+	s.ref = s.New()
+	underlying := s.New()
+	for _, fs := range []Filesystem{s.ref, underlying} {
+		for filename, content := range wrapperFixtures {
+			c.Assert(util.WriteFile(fs, filename, []byte(content), 0644), IsNil)
+		}
+
+		c.Assert(fs.MkdirAll("dir/empty", 0755), IsNil)
+	}
+
+	s.fs = s.Wrap(underlying)
+}
+
+// compare runs op on the reference and on the wrapper, asserting that their
+// results are equal.
+func (s *WrapperSuite) compare(c *C, op func(fs Filesystem) string) {
+	expected := op(s.ref)
+	obtained := op(s.fs)
+	c.Assert(obtained, Equals, expected)
+}
+
+// skipIfNotCapable skips the running test if the wrapper lacks any of the given
+// capabilities.
+func (s *WrapperSuite) skipIfNotCapable(c *C, caps Capability) {
+	skipIfNotCapable(c, s.fs, caps)
+}
+
+func (s *WrapperSuite) TestCapabilities(c *C) {
+	ref, caps := Capabilities(s.ref), Capabilities(s.fs)
+	c.Assert(caps&^ref, Equals, Capability(0),
+		Commentf("the wrapper claims capabilities the reference lacks"))
+}
+
+func (s *WrapperSuite) TestTree(c *C) {
+	s.compare(c, func(fs Filesystem) string {
+		return dumpTree(fs)
+	})
+}
+
+func (s *WrapperSuite) TestJoin(c *C) {
+	s.compare(c, func(fs Filesystem) string {
+		return fs.Join("dir", "qux", "baz")
+	})
+}
+
+func (s *WrapperSuite) TestStat(c *C) {
+	s.compare(c, func(fs Filesystem) string {
+		var out []string
+		for _, path := range []string{"foo", "empty", "dir", "/dir/qux/baz", "missing", "dir/missing"} {
+			fi, err := fs.Stat(path)
+			out = append(out, fmt.Sprintf("%s: %s %s", path, describeInfo(fi), errorClass(err)))
+		}
+
+		return strings.Join(out, "\n")
+	})
+}
+
+func (s *WrapperSuite) TestReadDir(c *C) {
+	s.compare(c, func(fs Filesystem) string {
+		var out []string
+		for _, path := range []string{"/", "dir", "dir/qux", "dir/empty", "missing"} {
+			infos, err := fs.ReadDir(path)
+			out = append(out, fmt.Sprintf("%s: %s", path, errorClass(err)))
+			for _, fi := range sortInfos(infos) {
+				out = append(out, "  "+describeInfo(fi))
+			}
+		}
+
+		return strings.Join(out, "\n")
+	})
+}
+
+func (s *WrapperSuite) TestRead(c *C) {
+	s.skipIfNotCapable(c, ReadCapability)
+	s.compare(c, func(fs Filesystem) string {
+		var out []string
+		for _, filename := range []string{"foo", "dir/bar", "empty", "missing"} {
+			out = append(out, filename+": "+readTranscript(fs, filename))
+		}
+
+		return strings.Join(out, "\n")
+	})
+}
+
+func (s *WrapperSuite) TestWrite(c *C) {
+	s.skipIfNotCapable(c, WriteCapability)
+	s.compare(c, func(fs Filesystem) string {
+		var out []string
+		f, err := fs.Create("dir/new/foo")
+		out = append(out, "create: "+errorClass(err))
+		if err == nil {
+			n, err := f.Write([]byte("foobar"))
+			out = append(out, fmt.Sprintf("write: %d %s", n, errorClass(err)))
+			out = append(out, "close: "+errorClass(f.Close()))
+		}
+
+		f, err = fs.OpenFile("foo", os.O_WRONLY|os.O_APPEND, 0)
+		out = append(out, "append: "+errorClass(err))
+		if err == nil {
+			n, err := f.Write([]byte("qux"))
+			out = append(out, fmt.Sprintf("write: %d %s", n, errorClass(err)))
+			out = append(out, "close: "+errorClass(f.Close()))
+		}
+
+		return strings.Join(append(out, dumpTree(fs)), "\n")
+	})
+}
+
+func (s *WrapperSuite) TestTruncate(c *C) {
+	s.skipIfNotCapable(c, WriteCapability|TruncateCapability)
+	s.compare(c, func(fs Filesystem) string {
+		f, err := fs.OpenFile("dir/bar", os.O_WRONLY, 0)
+		if err != nil {
+			return "open: " + errorClass(err)
+		}
+
+		err = f.Truncate(1)
+		return fmt.Sprintf("truncate: %s close: %s\n%s",
+			errorClass(err), errorClass(f.Close()), dumpTree(fs),
+		)
+	})
+}
+
+func (s *WrapperSuite) TestReadAndWrite(c *C) {
+	s.skipIfNotCapable(c, ReadAndWriteCapability)
+	s.compare(c, func(fs Filesystem) string {
+		f, err := fs.OpenFile("foo", os.O_RDWR, 0)
+		if err != nil {
+			return "open: " + errorClass(err)
+		}
+
+		var out []string
+		_, err = f.Seek(1, io.SeekStart)
+		out = append(out, "seek: "+errorClass(err))
+		_, err = f.Write([]byte("X"))
+		out = append(out, "write: "+errorClass(err))
+		_, err = f.Seek(0, io.SeekStart)
+		out = append(out, "seek: "+errorClass(err))
+		content, err := ioutil.ReadAll(f)
+		out = append(out, fmt.Sprintf("read: %q %s", content, errorClass(err)))
+		out = append(out, "close: "+errorClass(f.Close()))
+
+		return strings.Join(out, "\n")
+	})
+}
+
+func (s *WrapperSuite) TestDir(c *C) {
+	s.skipIfNotCapable(c, WriteCapability)
+	s.compare(c, func(fs Filesystem) string {
+		return strings.Join([]string{
+			"mkdir: " + errorClass(fs.MkdirAll("a/b/c", 0755)),
+			"mkdir existing: " + errorClass(fs.MkdirAll("dir/qux", 0755)),
+			"rename file: " + errorClass(fs.Rename("dir/bar", "dir/bar2")),
+			"rename dir: " + errorClass(fs.Rename("dir/qux", "dir/quux")),
+			"rename missing: " + errorClass(fs.Rename("missing", "dir/missing")),
+			"remove file: " + errorClass(fs.Remove("empty")),
+			"remove empty dir: " + errorClass(fs.Remove("dir/empty")),
+			"remove missing: " + errorClass(fs.Remove("missing")),
+			dumpTree(fs),
+		}, "\n")
+	})
+}
+
+func (s *WrapperSuite) TestRemoveAll(c *C) {
+	s.skipIfNotCapable(c, WriteCapability)
+	s.compare(c, func(fs Filesystem) string {
+		return "remove all: " + errorClass(util.RemoveAll(fs, "dir")) + "\n" + dumpTree(fs)
+	})
+}
+
+func (s *WrapperSuite) TestTempFile(c *C) {
+	s.skipIfNotCapable(c, WriteCapability)
+	s.compare(c, func(fs Filesystem) string {
+		f, err := fs.TempFile("dir", "tmp")
+		if err != nil {
+			return "tempfile: " + errorClass(err)
+		}
+
+		dir, base := filepath.Split(f.Name())
+		_, err = f.Write([]byte("foo"))
+		out := []string{
+			fmt.Sprintf("tempfile: %s %t", filepath.Clean(dir), strings.HasPrefix(base, "tmp")),
+			"write: " + errorClass(err),
+			"close: " + errorClass(f.Close()),
+		}
+
+		content, err := readFile(fs, f.Name())
+		out = append(out, fmt.Sprintf("read: %q %s", content, errorClass(err)))
+		out = append(out, "remove: "+errorClass(fs.Remove(f.Name())))
+
+		name, err := fs.TempDir("dir", "tmp")
+		out = append(out, "tempdir: "+errorClass(err))
+		if err == nil {
+			fi, err := fs.Stat(name)
+			out = append(out, fmt.Sprintf("stat: %t %s", fi != nil && fi.IsDir(), errorClass(err)))
+			out = append(out, "remove: "+errorClass(fs.Remove(name)))
+		}
+
+		return strings.Join(append(out, dumpTree(fs)), "\n")
+	})
+}
+
+func (s *WrapperSuite) TestSymlink(c *C) {
+	s.skipIfNotCapable(c, WriteCapability|SymlinkCapability)
+	s.compare(c, func(fs Filesystem) string {
+		var out []string
+		out = append(out, "symlink: "+errorClass(fs.Symlink("dir/bar", "link")))
+		out = append(out, "symlink existing: "+errorClass(fs.Symlink("foo", "link")))
+
+		target, err := fs.Readlink("link")
+		out = append(out, fmt.Sprintf("readlink: %s %s", target, errorClass(err)))
+		_, err = fs.Readlink("foo")
+		out = append(out, "readlink file: "+errorClass(err))
+
+		fi, err := fs.Lstat("link")
+		out = append(out, fmt.Sprintf("lstat: %s %s", describeInfo(fi), errorClass(err)))
+		fi, err = fs.Stat("link")
+		out = append(out, fmt.Sprintf("stat: %s %s", describeInfo(fi), errorClass(err)))
+		out = append(out, "read: "+readTranscript(fs, "link"))
+
+		return strings.Join(append(out, dumpTree(fs)), "\n")
+	})
+}
+
+func (s *WrapperSuite) TestChroot(c *C) {
+	write := CapabilityCheck(s.fs, WriteCapability)
+	s.compare(c, func(fs Filesystem) string {
+		chroot, err := fs.Chroot("dir")
+		if err != nil {
+			return "chroot: " + errorClass(err)
+		}
+
+		out := []string{dumpTree(chroot)}
+		if write {
+			out = append(out, "write: "+errorClass(util.WriteFile(chroot, "qux/new", []byte("new"), 0644)))
+			out = append(out, dumpTree(fs))
+		}
+
+		return strings.Join(out, "\n")
+	})
+}
+
+// readTranscript describes the results of reading the given file
+// sequentially, with a seek, and at an offset.
+func readTranscript(fs Basic, filename string) string {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return "open: " + errorClass(err)
+	}
+
+	var out []string
+	content, err := ioutil.ReadAll(f)
+	out = append(out, fmt.Sprintf("read: %q %s", content, errorClass(err)))
+
+	_, err = f.Seek(1, io.SeekStart)
+	out = append(out, "seek: "+errorClass(err))
+	content, err = ioutil.ReadAll(f)
+	out = append(out, fmt.Sprintf("read: %q %s", content, errorClass(err)))
+
+	b := make([]byte, 2)
+	n, err := f.ReadAt(b, 1)
+	out = append(out, fmt.Sprintf("readat: %q %s", b[:n], errorClass(err)))
+	out = append(out, "close: "+errorClass(f.Close()))
+
+	return strings.Join(out, " ")
+}
+
+// dumpTree describes the files of fs, with their content, and directories,
+// sorted by path.
+func dumpTree(fs Filesystem) string {
+	var out []string
+	err := util.Walk(fs, "/", func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		path = filepath.ToSlash(path)
+		switch {
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := fs.Readlink(path)
+			out = append(out, fmt.Sprintf("%s -> %s %s", path, target, errorClass(err)))
+		case fi.IsDir():
+			out = append(out, path+"/")
+		default:
+			content, err := readFile(fs, path)
+			out = append(out, fmt.Sprintf("%s %q %s", path, content, errorClass(err)))
+		}
+
+		return nil
+	})
+
+	sort.Strings(out)
+	return fmt.Sprintf("tree: %s\n%s", errorClass(err), strings.Join(out, "\n"))
+}
+
+// describeInfo describes the name, type and, for the regular files, size of
+// fi, ignoring the attributes that a wrapper may change.
+func describeInfo(fi os.FileInfo) string {
+	if fi == nil {
+		return "<nil>"
+	}
+
+	switch {
+	case fi.Mode()&os.ModeSymlink != 0:
+		return fi.Name() + " symlink"
+	case fi.IsDir():
+		return fi.Name() + " dir"
+	default:
+		return fmt.Sprintf("%s file %d", fi.Name(), fi.Size())
+	}
+}
+
+// errorClass describes err by its class, since the errors of a wrapper may
+// differ in their message from the ones of the filesystem.
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case err == io.EOF:
+		return "eof"
+	case os.IsNotExist(err):
+		return "not exist"
+	case os.IsExist(err):
+		return "exist"
+	default:
+		return "error"
+	}
+}
+
+func sortInfos(infos []os.FileInfo) []os.FileInfo {
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+
+	return infos
+}
+
+func readFile(fs Basic, filename string) ([]byte, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"os"
+	"syscall"
+
+	. "gopkg.in/check.v1"
+	. "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// XattrSuite is a convenient test suite to validate any implementation of
+// billy.Xattrer. The tests are skipped if the filesystem, or the storage
+// behind it, doesn't support the extended attributes.
+type XattrSuite struct {
+	FS Filesystem
+}
+
+// xattrName is the name of the attributes set, in the namespace of the users
+// required by Linux.
+const xattrName = "user.billy"
+
+// setXattr sets attr on name, skipping the test if it isn't supported.
+func (s *XattrSuite) setXattr(c *C, name, attr string, value []byte) {
+	err := SetXattr(s.FS, name, attr, value)
+	if e, ok := err.(*os.PathError); ok && (e.Err == ErrNotSupported || e.Err == syscall.ENOTSUP) {
+		c.Skip("filesystem does not support extended attributes")
+	}
+
+	c.Assert(err, IsNil)
+}
+
+func (s *XattrSuite) TestXattr(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	s.setXattr(c, "foo", xattrName, []byte("bar"))
+
+	value, err := GetXattr(s.FS, "foo", xattrName)
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "bar")
+
+	attrs, err := ListXattr(s.FS, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(hasString(attrs, xattrName), Equals, true)
+
+	s.setXattr(c, "foo", xattrName, []byte("qux"))
+
+	value, err = GetXattr(s.FS, "foo", xattrName)
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "qux")
+
+	err = RemoveXattr(s.FS, "foo", xattrName)
+	c.Assert(err, IsNil)
+
+	_, err = GetXattr(s.FS, "foo", xattrName)
+	assertError(c, err, IsNoXattr, "getxattr")
+
+	err = RemoveXattr(s.FS, "foo", xattrName)
+	assertError(c, err, IsNoXattr, "removexattr")
+
+	attrs, err = ListXattr(s.FS, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(hasString(attrs, xattrName), Equals, false)
+}
+
+func (s *XattrSuite) TestXattrEmptyValue(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := util.WriteFile(s.FS, "foo", nil, 0644)
+	c.Assert(err, IsNil)
+
+	s.setXattr(c, "foo", xattrName, nil)
+
+	value, err := GetXattr(s.FS, "foo", xattrName)
+	c.Assert(err, IsNil)
+	c.Assert(value, HasLen, 0)
+}
+
+func (s *XattrSuite) TestXattrDir(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability)
+
+	err := s.FS.MkdirAll("dir", 0755)
+	c.Assert(err, IsNil)
+
+	s.setXattr(c, "dir", xattrName, []byte("bar"))
+
+	value, err := GetXattr(s.FS, "dir", xattrName)
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "bar")
+}
+
+func (s *XattrSuite) TestXattrSymlink(c *C) {
+	skipIfNotCapable(c, s.FS, WriteCapability|SymlinkCapability)
+
+	err := util.WriteFile(s.FS, "foo", []byte("foo"), 0644)
+	c.Assert(err, IsNil)
+
+	err = s.FS.Symlink("foo", "link")
+	c.Assert(err, IsNil)
+
+	s.setXattr(c, "link", xattrName, []byte("bar"))
+
+	value, err := GetXattr(s.FS, "foo", xattrName)
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "bar")
+}
+
+func (s *XattrSuite) TestXattrNotExist(c *C) {
+	_, err := GetXattr(s.FS, "foo", xattrName)
+	if e, ok := err.(*os.PathError); ok && e.Err == ErrNotSupported {
+		c.Skip("filesystem does not support extended attributes")
+	}
+
+	assertError(c, err, os.IsNotExist, "getxattr")
+
+	_, err = ListXattr(s.FS, "foo")
+	assertError(c, err, os.IsNotExist, "listxattr")
+}
+
+// hasString returns true if list contains s.
+func hasString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+)
+
+// PoolOptions are the options of the pool of connections kept by the HTTP
+// transport of the network backends, which also discards the broken ones by
+// itself. The zero value is valid, and keeps the options of the transport.
+type PoolOptions struct {
+	// MaxConns is the maximum number of connections open to a host, in use
+	// or idle. Once reached, the requests block until another one is
+	// released.
+	MaxConns int
+	// MaxIdleConns is the maximum number of idle connections kept to be
+	// reused for each host.
+	MaxIdleConns int
+	// IdleTimeout is the time after which an idle connection is closed.
+	IdleTimeout time.Duration
+}
+
+func (o *PoolOptions) isSet() bool {
+	return o.MaxConns != 0 || o.MaxIdleConns != 0 || o.IdleTimeout != 0
+}
+
+// apply sets the options with a non-zero value to t.
+func (o *PoolOptions) apply(t *http.Transport) {
+	if o.MaxConns > 0 {
+		t.MaxConnsPerHost = o.MaxConns
+	}
+
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConns
+	}
+
+	if o.IdleTimeout > 0 {
+		t.IdleConnTimeout = o.IdleTimeout
+	}
+}
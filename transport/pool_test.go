@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type PoolSuite struct{}
+
+var _ = Suite(&PoolSuite{})
+
+func (s *PoolSuite) TestConfigPool(c *C) {
+	cfg := &Config{Pool: PoolOptions{MaxConns: 8, IdleTimeout: time.Minute}}
+	t := cfg.RoundTripper().(*http.Transport)
+	c.Assert(t.MaxConnsPerHost, Equals, 8)
+	c.Assert(t.MaxIdleConnsPerHost, Equals, 0)
+	c.Assert(t.IdleConnTimeout, Equals, time.Minute)
+	c.Assert(http.DefaultTransport.(*http.Transport).MaxConnsPerHost, Equals, 0)
+
+	custom := &http.Transport{MaxConnsPerHost: 4, MaxIdleConnsPerHost: 4}
+	cfg = &Config{Transport: custom, Pool: PoolOptions{MaxIdleConns: 2}}
+	t = cfg.RoundTripper().(*http.Transport)
+	c.Assert(t, Not(Equals), custom)
+	c.Assert(t.MaxConnsPerHost, Equals, 4)
+	c.Assert(t.MaxIdleConnsPerHost, Equals, 2)
+
+	cfg = &Config{Transport: custom}
+	c.Assert(cfg.RoundTripper(), Equals, custom)
+}
@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"time"
+)
+
+// DefaultRetryPolicy is the retry policy used by the network backends when
+// none is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 100 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// RetryPolicy bounds the retries of the operations interrupted by a transient
+// failure, as the reads of a file resumed from the last offset read. The
+// retries are consecutive, so the counter is reset once an operation makes
+// progress. The zero value disables the retries.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of consecutive retries.
+	MaxRetries int
+	// MinBackoff is the wait before the first retry, doubled on each of the
+	// next ones up to MaxBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum wait before a retry, if zero there is no
+	// maximum.
+	MaxBackoff time.Duration
+}
+
+// Backoff returns the wait before the given retry, counted from 0, and false if
+// it exceeds the maximum number of retries.
+func (p *RetryPolicy) Backoff(retry int) (time.Duration, bool) {
+	if retry >= p.MaxRetries {
+		return 0, false
+	}
+
+	wait := p.MinBackoff
+	for i := 0; i < retry && wait > 0; i++ {
+		wait *= 2
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			break
+		}
+	}
+
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	return wait, true
+}
+
+// Wait sleeps the backoff of the given retry, returning false, without
+// sleeping, if it exceeds the maximum number of retries.
+func (p *RetryPolicy) Wait(retry int) bool {
+	wait, ok := p.Backoff(retry)
+	if ok {
+		time.Sleep(wait)
+	}
+
+	return ok
+}
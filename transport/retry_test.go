@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type RetrySuite struct{}
+
+var _ = Suite(&RetrySuite{})
+
+func (s *RetrySuite) TestBackoff(c *C) {
+	p := &RetryPolicy{MaxRetries: 5, MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for retry, wait := range expected {
+		backoff, ok := p.Backoff(retry)
+		c.Assert(ok, Equals, true)
+		c.Assert(backoff, Equals, wait)
+	}
+
+	_, ok := p.Backoff(5)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *RetrySuite) TestBackoffWithoutMax(c *C) {
+	p := &RetryPolicy{MaxRetries: 10, MinBackoff: time.Millisecond}
+	backoff, ok := p.Backoff(9)
+	c.Assert(ok, Equals, true)
+	c.Assert(backoff, Equals, 512*time.Millisecond)
+}
+
+func (s *RetrySuite) TestZeroValue(c *C) {
+	p := &RetryPolicy{}
+	_, ok := p.Backoff(0)
+	c.Assert(ok, Equals, false)
+	c.Assert(p.Wait(0), Equals, false)
+}
+
+func (s *RetrySuite) TestConfigRetryPolicy(c *C) {
+	var config *Config
+	c.Assert(config.RetryPolicy(), Equals, DefaultRetryPolicy)
+
+	config = &Config{Retry: &RetryPolicy{MaxRetries: 1}}
+	c.Assert(config.RetryPolicy(), Equals, RetryPolicy{MaxRetries: 1})
+}
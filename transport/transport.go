@@ -0,0 +1,206 @@
+// Package transport provides the configuration of the connections shared by
+// the network backends: TLS, credentials, request signing and connection
+// pooling. The credentials are requested and the requests signed on every
+// request, so they can be rotated without recreating the filesystems. They're
+// never sent to another host than the configured one, e.g. following a
+// redirect.
+package transport // import "gopkg.in/src-d/go-billy.v4/transport"
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Credentials are the credentials sent with a request. If Token is set, it's
+// sent as a bearer token, otherwise Username and Password are sent with basic
+// authentication, if any of them is set.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// CredentialsProvider provides the credentials of the requests. It's called on
+// every request, so it must be safe for concurrent use, caching and refreshing
+// the credentials if obtaining them is expensive.
+type CredentialsProvider interface {
+	Credentials(r *http.Request) (*Credentials, error)
+}
+
+// CredentialsFunc is an adapter to use an ordinary function as a
+// CredentialsProvider.
+type CredentialsFunc func(r *http.Request) (*Credentials, error)
+
+// Credentials calls f(r).
+func (f CredentialsFunc) Credentials(r *http.Request) (*Credentials, error) {
+	return f(r)
+}
+
+// StaticCredentials returns a CredentialsProvider always providing c.
+func StaticCredentials(c Credentials) CredentialsProvider {
+	return CredentialsFunc(func(*http.Request) (*Credentials, error) {
+		return &c, nil
+	})
+}
+
+// Signer signs the requests, once the credentials are set, e.g. adding the
+// headers of a signature scheme of an object store. It's called on every
+// request, so it must be safe for concurrent use.
+type Signer interface {
+	Sign(r *http.Request) error
+}
+
+// SignerFunc is an adapter to use an ordinary function as a Signer.
+type SignerFunc func(r *http.Request) error
+
+// Sign calls f(r).
+func (f SignerFunc) Sign(r *http.Request) error {
+	return f(r)
+}
+
+// Config is the configuration of the connections of a network backend. The
+// zero value is valid, and equivalent to http.DefaultClient.
+type Config struct {
+	// TLS is the TLS configuration of the connections, if nil the default
+	// one is used. The client certificates used for mutual TLS can be
+	// rotated with GetClientCertificate.
+	TLS *tls.Config
+	// Credentials provides the credentials of every request, if any.
+	Credentials CredentialsProvider
+	// Signer signs every request, if not nil.
+	Signer Signer
+	// Host is the host, with the port if any, of the requests authorized with
+	// Credentials and signed with Signer, the requests to any other host are
+	// sent as they are. If empty, it's the host of the request sent by the
+	// client, so the redirects to other hosts aren't authorized.
+	Host string
+	// Transport is the transport sending the requests, if nil a copy of
+	// http.DefaultTransport is used. TLS is applied to it only if it's a
+	// *http.Transport, otherwise it must be configured by the caller.
+	Transport http.RoundTripper
+	// Timeout is the time limit of the requests, a timeout of zero means no
+	// timeout.
+	Timeout time.Duration
+	// Pool are the options of the pool of connections of each host, applied
+	// as TLS is, the options with a zero value keep the ones of Transport.
+	Pool PoolOptions
+	// Retry is the retry policy of the interrupted reads, if nil
+	// DefaultRetryPolicy is used.
+	Retry *RetryPolicy
+}
+
+// RetryPolicy returns the retry policy of the config.
+func (c *Config) RetryPolicy() RetryPolicy {
+	if c == nil || c.Retry == nil {
+		return DefaultRetryPolicy
+	}
+
+	return *c.Retry
+}
+
+// Client returns a new http.Client sending the requests with the configuration.
+// A nil config returns http.DefaultClient.
+func (c *Config) Client() *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+
+	return &http.Client{
+		Transport: c.RoundTripper(),
+		Timeout:   c.Timeout,
+	}
+}
+
+// RoundTripper returns a new http.RoundTripper sending the requests with the
+// configuration.
+func (c *Config) RoundTripper() http.RoundTripper {
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if t, ok := base.(*http.Transport); ok && (c.TLS != nil || c.Transport == nil || c.Pool.isSet()) {
+		t = t.Clone()
+		if c.TLS != nil {
+			t.TLSClientConfig = c.TLS.Clone()
+		}
+
+		c.Pool.apply(t)
+		base = t
+	}
+
+	if c.Credentials == nil && c.Signer == nil {
+		return base
+	}
+
+	return &roundTripper{base: base, credentials: c.Credentials, signer: c.Signer, host: c.Host}
+}
+
+type roundTripper struct {
+	base        http.RoundTripper
+	credentials CredentialsProvider
+	signer      Signer
+	host        string
+}
+
+func (t *roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !t.isAuthorized(r) {
+		return t.base.RoundTrip(r)
+	}
+
+	// a RoundTripper must not modify the given request.
+	r = r.Clone(r.Context())
+	if err := t.authorize(r); err != nil {
+		closeBody(r)
+		return nil, err
+	}
+
+	return t.base.RoundTrip(r)
+}
+
+// isAuthorized returns true if r is sent to the host of the config, or, if
+// none, to the host of the first request of its chain of redirects.
+func (t *roundTripper) isAuthorized(r *http.Request) bool {
+	host := t.host
+	if host == "" {
+		first := r
+		for first.Response != nil && first.Response.Request != nil {
+			first = first.Response.Request
+		}
+
+		host = first.URL.Host
+	}
+
+	return strings.EqualFold(r.URL.Host, host)
+}
+
+func (t *roundTripper) authorize(r *http.Request) error {
+	if t.credentials != nil {
+		c, err := t.credentials.Credentials(r)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case c == nil:
+		case c.Token != "":
+			r.Header.Set("Authorization", "Bearer "+c.Token)
+		case c.Username != "" || c.Password != "":
+			r.SetBasicAuth(c.Username, c.Password)
+		}
+	}
+
+	if t.signer != nil {
+		return t.signer.Sign(r)
+	}
+
+	return nil
+}
+
+func closeBody(r *http.Request) {
+	if r.Body != nil {
+		r.Body.Close()
+	}
+}
@@ -0,0 +1,193 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&TransportSuite{})
+
+type TransportSuite struct {
+	server *httptest.Server
+}
+
+func (s *TransportSuite) SetUpTest(c *C) {
+	s.server = httptest.NewServer(http.HandlerFunc(echoAuth))
+}
+
+func (s *TransportSuite) TearDownTest(c *C) {
+	s.server.Close()
+}
+
+// echoAuth answers with the Authorization and X-Signature headers.
+func echoAuth(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "%s|%s", r.Header.Get("Authorization"), r.Header.Get("X-Signature"))
+}
+
+func get(c *C, client *http.Client, url string) string {
+	res, err := client.Get(url)
+	c.Assert(err, IsNil)
+	defer res.Body.Close()
+
+	var body [256]byte
+	n, _ := res.Body.Read(body[:])
+	return string(body[:n])
+}
+
+func (s *TransportSuite) TestClientNil(c *C) {
+	var cfg *Config
+	c.Assert(cfg.Client(), Equals, http.DefaultClient)
+}
+
+func (s *TransportSuite) TestClientZero(c *C) {
+	cfg := &Config{}
+	c.Assert(get(c, cfg.Client(), s.server.URL), Equals, "|")
+}
+
+func (s *TransportSuite) TestBasicAuth(c *C) {
+	cfg := &Config{Credentials: StaticCredentials(Credentials{
+		Username: "foo",
+		Password: "bar",
+	})}
+
+	c.Assert(get(c, cfg.Client(), s.server.URL), Equals, "Basic Zm9vOmJhcg==|")
+}
+
+func (s *TransportSuite) TestRotatingToken(c *C) {
+	var calls int32
+	cfg := &Config{Credentials: CredentialsFunc(func(*http.Request) (*Credentials, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return &Credentials{Token: fmt.Sprintf("token-%d", n)}, nil
+	})}
+
+	client := cfg.Client()
+	c.Assert(get(c, client, s.server.URL), Equals, "Bearer token-1|")
+	c.Assert(get(c, client, s.server.URL), Equals, "Bearer token-2|")
+}
+
+func (s *TransportSuite) TestCredentialsError(c *C) {
+	cfg := &Config{Credentials: CredentialsFunc(func(*http.Request) (*Credentials, error) {
+		return nil, errors.New("expired")
+	})}
+
+	_, err := cfg.Client().Get(s.server.URL)
+	c.Assert(err, ErrorMatches, ".*expired")
+}
+
+func (s *TransportSuite) TestSigner(c *C) {
+	cfg := &Config{
+		Credentials: StaticCredentials(Credentials{Token: "foo"}),
+		Signer: SignerFunc(func(r *http.Request) error {
+			r.Header.Set("X-Signature", r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+			return nil
+		}),
+	}
+
+	c.Assert(get(c, cfg.Client(), s.server.URL+"/bar"), Equals, "Bearer foo|GET /bar Bearer foo")
+}
+
+func (s *TransportSuite) TestSignerDoesNotModifyRequest(c *C) {
+	cfg := &Config{Signer: SignerFunc(func(r *http.Request) error {
+		r.Header.Set("X-Signature", "foo")
+		return nil
+	})}
+
+	req, err := http.NewRequest("GET", s.server.URL, nil)
+	c.Assert(err, IsNil)
+	res, err := cfg.Client().Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+
+	c.Assert(req.Header.Get("X-Signature"), Equals, "")
+}
+
+func (s *TransportSuite) TestRedirectToOtherHost(c *C) {
+	redirect := httptest.NewServer(http.RedirectHandler(s.server.URL+"/bar", http.StatusFound))
+	defer redirect.Close()
+
+	cfg := &Config{
+		Credentials: StaticCredentials(Credentials{Token: "foo"}),
+		Signer: SignerFunc(func(r *http.Request) error {
+			r.Header.Set("X-Signature", "foo")
+			return nil
+		}),
+	}
+
+	c.Assert(get(c, cfg.Client(), redirect.URL), Equals, "|")
+}
+
+func (s *TransportSuite) TestRedirectToSameHost(c *C) {
+	mux := http.NewServeMux()
+	mux.Handle("/foo", http.RedirectHandler("/bar", http.StatusFound))
+	mux.HandleFunc("/bar", echoAuth)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := &Config{Credentials: StaticCredentials(Credentials{Token: "foo"})}
+	c.Assert(get(c, cfg.Client(), server.URL+"/foo"), Equals, "Bearer foo|")
+}
+
+func (s *TransportSuite) TestHost(c *C) {
+	cfg := &Config{
+		Credentials: StaticCredentials(Credentials{Token: "foo"}),
+		Host:        "example.com",
+	}
+
+	c.Assert(get(c, cfg.Client(), s.server.URL), Equals, "|")
+
+	cfg.Host = strings.TrimPrefix(s.server.URL, "http://")
+	c.Assert(get(c, cfg.Client(), s.server.URL), Equals, "Bearer foo|")
+}
+
+func (s *TransportSuite) TestTLS(c *C) {
+	server := httptest.NewTLSServer(http.HandlerFunc(echoAuth))
+	defer server.Close()
+
+	_, err := (&Config{}).Client().Get(server.URL)
+	c.Assert(err, NotNil)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	cfg := &Config{
+		TLS:         &tls.Config{RootCAs: pool},
+		Credentials: StaticCredentials(Credentials{Token: "foo"}),
+	}
+
+	c.Assert(get(c, cfg.Client(), server.URL), Equals, "Bearer foo|")
+}
+
+func (s *TransportSuite) TestTLSClientCertificate(c *C) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, len(r.TLS.PeerCertificates))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	cert := server.TLS.Certificates[0]
+	var requested int32
+	cfg := &Config{TLS: &tls.Config{
+		RootCAs: pool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			atomic.AddInt32(&requested, 1)
+			return &cert, nil
+		},
+	}}
+
+	c.Assert(get(c, cfg.Client(), server.URL), Equals, "1")
+	c.Assert(atomic.LoadInt32(&requested), Equals, int32(1))
+}
@@ -0,0 +1,128 @@
+package util
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// Copy copies the file at path of src to the same path of dst, replacing it if
+// it exists and creating its missing parent directories. The permissions of
+// the file are kept, with Chmod if dst implements billy.Change, so they
+// aren't masked by the umask. A symbolic link is copied as a link if both
+// filesystems support them, otherwise the content of its target is copied.
+// If path is a directory, only the directory is created, CopyDir copies its
+// content.
+func Copy(dst, src billy.Filesystem, path string) error {
+	fi, err := src.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	return copyEntry(dst, src, path, fi)
+}
+
+// CopyDir copies recursively the directory at path of src, with all its files,
+// directories and symbolic links, to the same path of dst, merging it with
+// the directory if it exists. The files are copied as Copy does, and the
+// directories are created with the same permissions.
+func CopyDir(dst, src billy.Filesystem, path string) error {
+	return Walk(src, path, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		return copyEntry(dst, src, path, fi)
+	})
+}
+
+func copyEntry(dst, src billy.Filesystem, path string, fi os.FileInfo) error {
+	switch {
+	case fi.IsDir():
+		if err := dst.MkdirAll(path, fi.Mode().Perm()); err != nil {
+			return err
+		}
+
+		return chmod(dst, path, fi.Mode())
+	case fi.Mode()&os.ModeSymlink != 0:
+		return copySymlink(dst, src, path)
+	default:
+		return copyFile(dst, src, path, fi.Mode())
+	}
+}
+
+func copySymlink(dst, src billy.Filesystem, path string) error {
+	if !billy.CapabilityCheck(dst, billy.SymlinkCapability) {
+		fi, err := src.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return &os.PathError{Op: "copy", Path: path, Err: billy.ErrNotSupported}
+		}
+
+		return copyFile(dst, src, path, fi.Mode())
+	}
+
+	target, err := src.Readlink(path)
+	if err != nil {
+		return err
+	}
+
+	if err := dst.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err := dst.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return dst.Symlink(target, path)
+}
+
+func copyFile(dst, src billy.Filesystem, path string, mode os.FileMode) (err error) {
+	in, err := src.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	// a link at path would be followed, replacing its target instead.
+	if fi, err := dst.Lstat(path); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if err := dst.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	out, err := dst.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err1 := out.Close(); err == nil {
+			err = err1
+		}
+	}()
+
+	if _, err = io.Copy(out, in); err != nil {
+		return err
+	}
+
+	return chmod(dst, path, mode)
+}
+
+// chmod sets the permissions of the file at path to the ones of mode, if fs
+// implements billy.Change.
+func chmod(fs billy.Filesystem, path string, mode os.FileMode) error {
+	c, ok := fs.(billy.Change)
+	if !ok {
+		return nil
+	}
+
+	return c.Chmod(path, mode.Perm())
+}
@@ -0,0 +1,130 @@
+package util_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// noSymlinkFS hides the symbolic links support of the filesystem.
+type noSymlinkFS struct {
+	billy.Filesystem
+}
+
+func (fs *noSymlinkFS) Capabilities() billy.Capability {
+	return billy.Capabilities(fs.Filesystem) &^ billy.SymlinkCapability
+}
+
+func newCopyTree(c *C) billy.Filesystem {
+	fs := memfs.New()
+	c.Assert(util.WriteFile(fs, "dir/foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "dir/bin", []byte("bin"), 0755), IsNil)
+	c.Assert(util.WriteFile(fs, "dir/qux/secret", []byte("secret"), 0600), IsNil)
+	c.Assert(fs.MkdirAll("dir/empty", 0700), IsNil)
+	c.Assert(fs.Symlink("foo", "dir/link"), IsNil)
+	return fs
+}
+
+func (s *UtilSuite) assertFile(c *C, fs billy.Filesystem, path, content string, perm os.FileMode) {
+	fi, err := fs.Lstat(path)
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode().IsRegular(), Equals, true, Commentf("%s", path))
+	c.Assert(fi.Mode().Perm(), Equals, perm, Commentf("%s", path))
+
+	f, err := fs.Open(path)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, content)
+}
+
+func (s *UtilSuite) assertCopiedTree(c *C, fs billy.Filesystem) {
+	s.assertFile(c, fs, "dir/foo", "foo", 0644)
+	s.assertFile(c, fs, "dir/bin", "bin", 0755)
+	s.assertFile(c, fs, "dir/qux/secret", "secret", 0600)
+
+	fi, err := fs.Stat("dir/empty")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+
+	target, err := fs.Readlink("dir/link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "foo")
+}
+
+func (s *UtilSuite) TestCopyDir(c *C) {
+	src := newCopyTree(c)
+
+	disk := osfs.New(c.MkDir())
+	c.Assert(util.CopyDir(disk, src, "dir"), IsNil)
+	s.assertCopiedTree(c, disk)
+
+	mem := memfs.New()
+	c.Assert(util.CopyDir(mem, disk, "/"), IsNil)
+	s.assertCopiedTree(c, mem)
+}
+
+func (s *UtilSuite) TestCopyDirMerge(c *C) {
+	src := newCopyTree(c)
+
+	dst := memfs.New()
+	c.Assert(util.WriteFile(dst, "dir/foo", []byte("old"), 0644), IsNil)
+	c.Assert(util.WriteFile(dst, "dir/other", []byte("other"), 0644), IsNil)
+	c.Assert(dst.Symlink("other", "dir/link"), IsNil)
+
+	c.Assert(util.CopyDir(dst, src, "dir"), IsNil)
+	s.assertCopiedTree(c, dst)
+	s.assertFile(c, dst, "dir/other", "other", 0644)
+
+	fi, err := dst.Stat("dir/empty")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode().Perm(), Equals, os.FileMode(0700))
+}
+
+func (s *UtilSuite) TestCopy(c *C) {
+	src := newCopyTree(c)
+
+	dst := memfs.New()
+	c.Assert(util.Copy(dst, src, "dir/qux/secret"), IsNil)
+	s.assertFile(c, dst, "dir/qux/secret", "secret", 0600)
+
+	c.Assert(util.Copy(dst, src, "dir/link"), IsNil)
+	target, err := dst.Readlink("dir/link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "foo")
+
+	err = util.Copy(dst, src, "dir/missing")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *UtilSuite) TestCopyReplacesLink(c *C) {
+	src := newCopyTree(c)
+
+	dst := memfs.New()
+	c.Assert(util.WriteFile(dst, "target", []byte("target"), 0644), IsNil)
+	c.Assert(dst.MkdirAll("dir", 0755), IsNil)
+	c.Assert(dst.Symlink("../target", "dir/foo"), IsNil)
+
+	c.Assert(util.Copy(dst, src, "dir/foo"), IsNil)
+	s.assertFile(c, dst, "dir/foo", "foo", 0644)
+	s.assertFile(c, dst, "target", "target", 0644)
+}
+
+func (s *UtilSuite) TestCopySymlinkNotSupported(c *C) {
+	src := newCopyTree(c)
+	c.Assert(src.Symlink("qux", "dir/dirlink"), IsNil)
+
+	dst := &noSymlinkFS{memfs.New()}
+	c.Assert(util.Copy(dst, src, "dir/link"), IsNil)
+	s.assertFile(c, dst, "dir/link", "foo", 0644)
+
+	err := util.Copy(dst, src, "dir/dirlink")
+	c.Assert(err, NotNil)
+}
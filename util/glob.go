@@ -11,7 +11,9 @@ import (
 // Glob returns the names of all files matching pattern or nil
 // if there is no matching file. The syntax of patterns is the same
 // as in Match. The pattern may describe hierarchical names such as
-// /usr/*/bin/ed (assuming the Separator is '/').
+// /usr/*/bin/ed (assuming the Separator is '/'). The matches are
+// returned in lexical order, element by element, which is the same order
+// Walk visits them, regardless of the order returned by ReadDir.
 //
 // Glob ignores file system errors such as I/O errors reading directories.
 // The only possible returned error is ErrBadPattern, when pattern
@@ -43,6 +45,8 @@ func Glob(fs billy.Filesystem, pattern string) (matches []string, err error) {
 			return
 		}
 	}
+
+	sortPaths(matches)
 	return
 }
 
@@ -0,0 +1,135 @@
+package util_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// failingRemoveFS fails to remove the given path, forcing RemoveAll to
+// inspect it.
+type failingRemoveFS struct {
+	billy.Filesystem
+	path string
+}
+
+func (fs *failingRemoveFS) Remove(path string) error {
+	if filepath.Clean(path) == fs.path {
+		return &os.PathError{Op: "remove", Path: path, Err: os.ErrPermission}
+	}
+
+	return fs.Filesystem.Remove(path)
+}
+
+func (s *UtilSuite) TestRemoveAll(c *C) {
+	fs := newTree(c, "foo/bar", "foo/qux/baz", "qux")
+	c.Assert(util.RemoveAll(fs, "foo"), IsNil)
+
+	_, err := fs.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = fs.Stat("qux")
+	c.Assert(err, IsNil)
+}
+
+func (s *UtilSuite) TestRemoveAllNotExists(c *C) {
+	c.Assert(util.RemoveAll(memfs.New(), "foo"), IsNil)
+}
+
+func (s *UtilSuite) TestRemoveAllNeverFollowsSymlinks(c *C) {
+	dir, err := ioutil.TempDir("", "util-remove")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	link := filepath.Join("tree", "link")
+	fs := &failingRemoveFS{Filesystem: osfs.New(dir), path: link}
+	c.Assert(util.WriteFile(fs, "outside/secret", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(fs, "tree/foo", []byte("foo"), 0644), IsNil)
+	c.Assert(fs.Symlink(filepath.Join(dir, "outside"), link), IsNil)
+
+	err = util.RemoveAll(fs, "tree")
+	c.Assert(os.IsPermission(err), Equals, true)
+
+	_, err = fs.Stat("outside/secret")
+	c.Assert(err, IsNil)
+
+	_, err = fs.Lstat(link)
+	c.Assert(err, IsNil)
+
+	_, err = fs.Stat("tree/foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *UtilSuite) TestRemoveAllSymlinkOutsideChroot(c *C) {
+	dir, err := ioutil.TempDir("", "util-remove")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	outside := filepath.Join(dir, "outside")
+	c.Assert(os.MkdirAll(outside, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(outside, "secret"), nil, 0644), IsNil)
+
+	fs := osfs.New(filepath.Join(dir, "tree"))
+	c.Assert(util.WriteFile(fs, "foo/bar", nil, 0644), IsNil)
+	c.Assert(os.Symlink(outside, filepath.Join(dir, "tree", "foo", "link")), IsNil)
+
+	c.Assert(util.RemoveAll(fs, "foo"), IsNil)
+
+	_, err = fs.Lstat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = os.Stat(filepath.Join(outside, "secret"))
+	c.Assert(err, IsNil)
+}
+
+// bulkRemoverFS records the calls to RemoveMany.
+type bulkRemoverFS struct {
+	billy.Filesystem
+	calls [][]string
+}
+
+func (fs *bulkRemoverFS) RemoveMany(paths []string) error {
+	fs.calls = append(fs.calls, paths)
+	return billy.RemoveMany(fs.Filesystem, paths)
+}
+
+func (s *UtilSuite) TestRemoveAllBulkRemover(c *C) {
+	fs := &bulkRemoverFS{Filesystem: newTree(c, "foo/bar", "foo/qux/baz", "qux")}
+	c.Assert(util.RemoveAll(fs, "foo"), IsNil)
+	c.Assert(fs.calls, DeepEquals, [][]string{{
+		"foo/qux/baz", "foo/qux", "foo/bar", "foo",
+	}})
+
+	_, err := fs.Stat("foo")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = fs.Stat("qux")
+	c.Assert(err, IsNil)
+}
+
+// removerAllFS records the calls to RemoveAll.
+type removerAllFS struct {
+	billy.Filesystem
+	calls []string
+}
+
+func (fs *removerAllFS) RemoveAll(path string) error {
+	fs.calls = append(fs.calls, path)
+	return nil
+}
+
+func (s *UtilSuite) TestRemoveAllRemoverAll(c *C) {
+	fs := &removerAllFS{Filesystem: newTree(c, "foo/bar")}
+	c.Assert(util.RemoveAll(fs, "foo"), IsNil)
+	c.Assert(fs.calls, DeepEquals, []string{"foo"})
+
+	c.Assert(util.RemoveAll(chroot.New(fs, "/foo"), "bar"), IsNil)
+	c.Assert(fs.calls, DeepEquals, []string{"foo", filepath.Join("/foo", "bar")})
+}
@@ -0,0 +1,137 @@
+package util
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// resumeChunkSize is the number of bytes copied by ResumeCopy between two
+// saves of the progress.
+const resumeChunkSize = 4 << 20
+
+// Progress is the state of a copy done by ResumeCopy, persisted to resume it
+// if it's interrupted.
+type Progress struct {
+	// Offset is the number of bytes copied and synced to the destination.
+	Offset int64 `json:"offset"`
+	// Size is the size of the source when the copy started.
+	Size int64 `json:"size"`
+}
+
+// ResumeCopy copies the file src of srcFS to the file dst of dstFS, saving the
+// progress to the file progress of dstFS every time a chunk of the content is
+// written, and synced if the file supports it. If the progress file exists the
+// copy is resumed from its offset, seeking both files, instead of restarting,
+// unless the size of the source changed. The progress file is removed once the
+// copy is complete. It returns the number of bytes copied by this call.
+//
+// The resumed reads are efficient if the source supports seeking without
+// reading the skipped content, as httpfs and blobfs do with range requests.
+func ResumeCopy(dstFS billy.Filesystem, dst string, srcFS billy.Basic, src, progress string) (written int64, err error) {
+	fi, err := srcFS.Stat(src)
+	if err != nil {
+		return 0, err
+	}
+
+	p, err := readProgress(dstFS, progress)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.Size != fi.Size() || p.Offset > fi.Size() {
+		p = &Progress{Size: fi.Size()}
+	}
+
+	in, err := srcFS.Open(src)
+	if err != nil {
+		return 0, err
+	}
+
+	defer in.Close()
+	out, err := dstFS.OpenFile(dst, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err1 := out.Close(); err == nil {
+			err = err1
+		}
+	}()
+
+	// the content written after the last save of the progress is discarded,
+	// it could be incomplete.
+	if err := out.Truncate(p.Offset); err != nil {
+		return 0, err
+	}
+
+	if _, err := in.Seek(p.Offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if _, err := out.Seek(p.Offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	for {
+		n, err := io.CopyN(out, in, resumeChunkSize)
+		written += n
+		if err != nil && err != io.EOF {
+			return written, err
+		}
+
+		if n > 0 {
+			if err := billy.Sync(out); err != nil {
+				return written, err
+			}
+
+			p.Offset += n
+			if err := writeProgress(dstFS, progress, p); err != nil {
+				return written, err
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if err := dstFS.Remove(progress); err != nil && !os.IsNotExist(err) {
+		return written, err
+	}
+
+	return written, nil
+}
+
+// readProgress returns the progress saved in the given file, or an empty
+// one if it doesn't exist.
+func readProgress(fs billy.Basic, filename string) (*Progress, error) {
+	f, err := fs.Open(filename)
+	if os.IsNotExist(err) {
+		return &Progress{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	p := &Progress{}
+	if err := json.NewDecoder(f).Decode(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func writeProgress(fs billy.Filesystem, filename string, p *Progress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(fs, filename, data, 0644)
+}
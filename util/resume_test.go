@@ -0,0 +1,108 @@
+package util_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+var errInterrupted = errors.New("interrupted")
+
+// countingFS counts the bytes read from its files, failing once limit bytes
+// were read, if limit isn't zero.
+type countingFS struct {
+	billy.Filesystem
+	read  int64
+	limit int64
+}
+
+func (fs *countingFS) Open(filename string) (billy.File, error) {
+	f, err := fs.Filesystem.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &countingFile{File: f, fs: fs}, nil
+}
+
+type countingFile struct {
+	billy.File
+	fs *countingFS
+}
+
+func (f *countingFile) Read(b []byte) (int, error) {
+	if f.fs.limit != 0 && f.fs.read >= f.fs.limit {
+		return 0, errInterrupted
+	}
+
+	n, err := f.File.Read(b)
+	f.fs.read += int64(n)
+	return n, err
+}
+
+func (s *UtilSuite) TestResumeCopy(c *C) {
+	content := bytes.Repeat([]byte("0123456789"), 1<<20)
+	src := &countingFS{Filesystem: memfs.New(), limit: 6 << 20}
+	c.Assert(util.WriteFile(src, "foo", content, 0644), IsNil)
+
+	dst := memfs.New()
+	written, err := util.ResumeCopy(dst, "bar", src, "foo", "bar.progress")
+	c.Assert(err, Equals, errInterrupted)
+	c.Assert(written, Equals, int64(6<<20))
+
+	c.Assert(readFile(c, dst, "bar.progress"), Equals, `{"offset":4194304,"size":10485760}`)
+
+	src.read, src.limit = 0, 0
+	written, err = util.ResumeCopy(dst, "bar", src, "foo", "bar.progress")
+	c.Assert(err, IsNil)
+	c.Assert(written, Equals, int64(len(content)-4<<20))
+	c.Assert(src.read, Equals, written)
+
+	c.Assert(readFile(c, dst, "bar") == string(content), Equals, true)
+
+	_, err = dst.Stat("bar.progress")
+	c.Assert(err, NotNil)
+}
+
+func (s *UtilSuite) TestResumeCopySourceChanged(c *C) {
+	src := memfs.New()
+	dst := memfs.New()
+	c.Assert(util.WriteFile(src, "foo", []byte("foo"), 0644), IsNil)
+	c.Assert(util.WriteFile(dst, "bar", []byte("qux"), 0644), IsNil)
+	c.Assert(util.WriteFile(dst, "bar.progress", []byte(`{"offset":2,"size":2}`), 0644), IsNil)
+
+	written, err := util.ResumeCopy(dst, "bar", src, "foo", "bar.progress")
+	c.Assert(err, IsNil)
+	c.Assert(written, Equals, int64(3))
+
+	c.Assert(readFile(c, dst, "bar"), Equals, "foo")
+}
+
+func (s *UtilSuite) TestResumeCopyDiscardsUnsaved(c *C) {
+	src := memfs.New()
+	dst := memfs.New()
+	c.Assert(util.WriteFile(src, "foo", []byte("foobar"), 0644), IsNil)
+	c.Assert(util.WriteFile(dst, "bar", []byte("fooXXXXX"), 0644), IsNil)
+	c.Assert(util.WriteFile(dst, "bar.progress", []byte(`{"offset":3,"size":6}`), 0644), IsNil)
+
+	written, err := util.ResumeCopy(dst, "bar", src, "foo", "bar.progress")
+	c.Assert(err, IsNil)
+	c.Assert(written, Equals, int64(3))
+
+	c.Assert(readFile(c, dst, "bar"), Equals, "foobar")
+}
+
+func readFile(c *C, fs billy.Basic, filename string) string {
+	f, err := fs.Open(filename)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	return string(content)
+}
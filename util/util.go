@@ -13,19 +13,74 @@ import (
 
 // RemoveAll removes path and any children it contains. It removes everything it
 // can but returns the first error it encounters. If the path does not exist,
-// RemoveAll returns nil (no error).
+// RemoveAll returns nil (no error). The symbolic links are removed, never
+// followed, so nothing outside path is removed. If the filesystem implements
+// billy.RemoverAll its RemoveAll is used, otherwise if it implements
+// billy.BulkRemover every path of the tree is removed with a single call to
+// RemoveMany.
 func RemoveAll(fs billy.Basic, path string) error {
+	if r, ok := fs.(billy.RemoverAll); ok {
+		return r.RemoveAll(path)
+	}
+
 	fs, path = getUnderlyingAndPath(fs, path)
 
-	if r, ok := fs.(removerAll); ok {
+	if r, ok := fs.(billy.RemoverAll); ok {
 		return r.RemoveAll(path)
 	}
 
+	if r, ok := fs.(billy.BulkRemover); ok {
+		return removeMany(fs, r, path)
+	}
+
 	return removeAll(fs, path)
 }
 
-type removerAll interface {
-	RemoveAll(string) error
+func removeMany(fs billy.Basic, r billy.BulkRemover, path string) error {
+	var paths []string
+	if err := listTree(fs, path, &paths); err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	return r.RemoveMany(paths)
+}
+
+// listTree appends to paths every path of the tree at path, the children of
+// each directory before it, without following symbolic links.
+func listTree(fs billy.Basic, path string, paths *[]string) error {
+	fi, err := lstat(fs, path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() {
+		dirfs, ok := fs.(billy.Dir)
+		if !ok {
+			return billy.ErrNotSupported
+		}
+
+		fis, err := dirfs.ReadDir(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		for _, fi := range fis {
+			if err := listTree(fs, fs.Join(path, fi.Name()), paths); err != nil {
+				return err
+			}
+		}
+	}
+
+	*paths = append(*paths, path)
+	return nil
 }
 
 func removeAll(fs billy.Basic, path string) error {
@@ -37,8 +92,9 @@ func removeAll(fs billy.Basic, path string) error {
 		return nil
 	}
 
-	// Otherwise, is this a directory we need to recurse into?
-	dir, serr := fs.Stat(path)
+	// Otherwise, is this a directory we need to recurse into? A symbolic
+	// link to a directory is not, its target must be left untouched.
+	dir, serr := lstat(fs, path)
 	if serr != nil {
 		if os.IsNotExist(serr) {
 			return nil
@@ -93,6 +149,16 @@ func removeAll(fs billy.Basic, path string) error {
 
 }
 
+// lstat returns the FileInfo of path without following it, if the filesystem
+// supports symbolic links.
+func lstat(fs billy.Basic, path string) (os.FileInfo, error) {
+	if sl, ok := fs.(billy.Symlink); ok {
+		return sl.Lstat(path)
+	}
+
+	return fs.Stat(path)
+}
+
 // WriteFile writes data to a file named by filename in the given filesystem.
 // If the file does not exist, WriteFile creates it with permissions perm;
 // otherwise WriteFile truncates it before writing.
@@ -114,6 +180,43 @@ func WriteFile(fs billy.Basic, filename string, data []byte, perm os.FileMode) e
 	return err
 }
 
+// WriteFileAtomic writes data to a file named by filename in the given
+// filesystem, replacing it atomically if it exists. The data is written to a
+// temporary file in the same directory, synced if the file supports it, and
+// renamed to filename, so readers never see a partially written file. The
+// temporary file is created with permissions perm.
+func WriteFileAtomic(fs billy.Filesystem, filename string, data []byte, perm os.FileMode) (err error) {
+	f, err := tempFile(fs, filepath.Dir(filename), "."+filepath.Base(filename), perm)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			fs.Remove(f.Name())
+		}
+	}()
+
+	n, err := f.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+
+	if err == nil {
+		err = billy.Sync(f)
+	}
+
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return fs.Rename(f.Name(), filename)
+}
+
 // Random number state.
 // We generate random temporary file names so that there's a good
 // chance the file doesn't exist yet - keeps the number of tries in
@@ -145,6 +248,10 @@ func nextSuffix() string {
 // f.Name() to find the pathname of the file. It is the caller's responsibility
 // to remove the file when no longer needed.
 func TempFile(fs billy.Basic, dir, prefix string) (f billy.File, err error) {
+	return tempFile(fs, dir, prefix, 0600)
+}
+
+func tempFile(fs billy.Basic, dir, prefix string, perm os.FileMode) (f billy.File, err error) {
 	// This implementation is based on stdlib ioutil.TempFile.
 
 	if dir == "" {
@@ -154,7 +261,7 @@ func TempFile(fs billy.Basic, dir, prefix string) (f billy.File, err error) {
 	nconflict := 0
 	for i := 0; i < 10000; i++ {
 		name := filepath.Join(dir, prefix+nextSuffix())
-		f, err = fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		f, err = fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
 		if os.IsExist(err) {
 			if nconflict++; nconflict > 10 {
 				randmu.Lock()
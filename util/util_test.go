@@ -1,11 +1,14 @@
 package util_test
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
 	"testing"
 
+	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/memfs"
 	"gopkg.in/src-d/go-billy.v4/util"
 )
@@ -41,3 +44,72 @@ func TestTempDir(t *testing.T) {
 		}
 	}
 }
+
+// failingRenameFS fails to rename any file.
+type failingRenameFS struct {
+	billy.Filesystem
+}
+
+func (fs *failingRenameFS) Rename(from, to string) error {
+	return errors.New("rename failed")
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	fs := memfs.New()
+	if err := util.WriteFile(fs, "foo/bar", []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := util.WriteFileAtomic(fs, "foo/bar", []byte("new"), 0640); err != nil {
+		t.Fatalf("WriteFileAtomic() = %v", err)
+	}
+
+	f, err := fs.Open("foo/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+	content, err := ioutil.ReadAll(f)
+	if err != nil || string(content) != "new" {
+		t.Errorf("content = %q, %v, want %q", content, err, "new")
+	}
+
+	fi, err := fs.Stat("foo/bar")
+	if err != nil || fi.Mode() != 0640 {
+		t.Errorf("Stat() = %v, %v, want mode 0640", fi, err)
+	}
+
+	fis, err := fs.ReadDir("foo")
+	if err != nil || len(fis) != 1 {
+		t.Errorf("ReadDir() = %d entries, %v, want only the file", len(fis), err)
+	}
+}
+
+func TestWriteFileAtomicError(t *testing.T) {
+	mem := memfs.New()
+	if err := util.WriteFile(mem, "foo", []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := &failingRenameFS{mem}
+	if err := util.WriteFileAtomic(fs, "foo", []byte("new"), 0644); err == nil {
+		t.Fatal("WriteFileAtomic() = nil, want error")
+	}
+
+	fis, err := mem.ReadDir("/")
+	if err != nil || len(fis) != 1 {
+		t.Errorf("ReadDir() = %d entries, %v, want the temporary file removed", len(fis), err)
+	}
+
+	f, err := mem.Open("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+	content, err := ioutil.ReadAll(f)
+	if err != nil || string(content) != "old" {
+		t.Errorf("content = %q, %v, want %q", content, err, "old")
+	}
+}
@@ -0,0 +1,121 @@
+package util_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// reverseFS returns the entries of ReadDir in reverse order.
+type reverseFS struct {
+	billy.Filesystem
+}
+
+func (fs *reverseFS) ReadDir(path string) ([]os.FileInfo, error) {
+	l, err := fs.Filesystem.ReadDir(path)
+	for i, j := 0, len(l)-1; i < j; i, j = i+1, j-1 {
+		l[i], l[j] = l[j], l[i]
+	}
+
+	return l, err
+}
+
+func newTree(c *C, files ...string) billy.Filesystem {
+	fs := memfs.New()
+	for _, name := range files {
+		c.Assert(util.WriteFile(fs, name, nil, 0644), IsNil)
+	}
+
+	return &reverseFS{Filesystem: fs}
+}
+
+func walkPaths(c *C, fs billy.Filesystem, root string, fn filepath.WalkFunc) []string {
+	var paths []string
+	err := util.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+		c.Assert(err, IsNil)
+		paths = append(paths, filepath.ToSlash(path))
+		if fn != nil {
+			return fn(path, info, err)
+		}
+
+		return nil
+	})
+	c.Assert(err, IsNil)
+	return paths
+}
+
+func (s *UtilSuite) TestWalk(c *C) {
+	fs := newTree(c, "b/2", "b/1", "a/c/1", "a-b", "a/1")
+
+	c.Assert(walkPaths(c, fs, "/", nil), DeepEquals, []string{
+		"/", "/a", "/a/1", "/a/c", "/a/c/1", "/a-b", "/b", "/b/1", "/b/2",
+	})
+
+	c.Assert(walkPaths(c, fs, "a", nil), DeepEquals, []string{
+		"a", "a/1", "a/c", "a/c/1",
+	})
+}
+
+func (s *UtilSuite) TestWalkSkipDir(c *C) {
+	fs := newTree(c, "a/1", "b/1", "c/1")
+
+	paths := walkPaths(c, fs, "/", func(path string, info os.FileInfo, err error) error {
+		if info.IsDir() && info.Name() == "b" {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	c.Assert(paths, DeepEquals, []string{"/", "/a", "/a/1", "/b", "/c", "/c/1"})
+}
+
+func (s *UtilSuite) TestWalkError(c *C) {
+	fs := newTree(c, "a/1", "b/1")
+
+	stop := errors.New("stop")
+	err := util.Walk(fs, "/", func(path string, info os.FileInfo, err error) error {
+		if filepath.Base(path) == "a" {
+			return stop
+		}
+
+		return nil
+	})
+	c.Assert(err, Equals, stop)
+}
+
+func (s *UtilSuite) TestWalkNotExists(c *C) {
+	fs := newTree(c)
+
+	err := util.Walk(fs, "foo", func(path string, info os.FileInfo, err error) error {
+		return err
+	})
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *UtilSuite) TestWalkSymlink(c *C) {
+	fs := newTree(c, "a/1")
+	c.Assert(fs.Symlink("a", "link"), IsNil)
+
+	c.Assert(walkPaths(c, fs, "/", nil), DeepEquals, []string{
+		"/", "/a", "/a/1", "/link",
+	})
+}
+
+func (s *UtilSuite) TestGlobOrder(c *C) {
+	fs := newTree(c, "b/x", "a/x", "a-b/x", "a/y")
+
+	names, err := util.Glob(fs, "*/*")
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{
+		filepath.Join("a", "x"),
+		filepath.Join("a", "y"),
+		filepath.Join("a-b", "x"),
+		filepath.Join("b", "x"),
+	})
+}
@@ -0,0 +1,116 @@
+package util
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+)
+
+// ChangeSet is a batch of changes, holding the operations done to each path.
+type ChangeSet map[string]billy.Op
+
+// Paths returns the paths changed, sorted.
+func (cs ChangeSet) Paths() []string {
+	paths := make([]string, 0, len(cs))
+	for path := range cs {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// Debounce reads the events from the given channel, coalescing the events of
+// the same path, and delivers them in a ChangeSet once no event has been
+// received during window. The operations of each path are the union of the
+// ones received, so a file created and removed in the same burst is reported
+// with both. The returned channel is closed, after delivering the pending
+// changes, once events is closed.
+func Debounce(events <-chan billy.Event, window time.Duration) <-chan ChangeSet {
+	return debounce(events, window, nil)
+}
+
+// Watch watches the given path of fs, as billy.Watcher does, delivering the
+// events debounced, as Debounce does. It returns billy.ErrNotSupported if fs
+// doesn't implement billy.Watcher.
+func Watch(fs billy.Basic, path string, recursive bool, window time.Duration) (<-chan ChangeSet, billy.CancelFunc, error) {
+	w, ok := fs.(billy.Watcher)
+	if !ok {
+		return nil, nil, billy.ErrNotSupported
+	}
+
+	events, cancel, err := w.Watch(path, recursive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	return debounce(events, window, done), func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+	}, nil
+}
+
+// debounce implements Debounce, discarding the pending changes once done is
+// closed.
+func debounce(events <-chan billy.Event, window time.Duration, done <-chan struct{}) <-chan ChangeSet {
+	changes := make(chan ChangeSet)
+	go func() {
+		defer close(changes)
+
+		timer := time.NewTimer(window)
+		timer.Stop()
+
+		var pending ChangeSet
+		deliver := func() bool {
+			select {
+			case changes <- pending:
+				pending = nil
+				return true
+			case <-done:
+				return false
+			}
+		}
+
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					if len(pending) > 0 {
+						deliver()
+					}
+
+					return
+				}
+
+				if pending == nil {
+					pending = make(ChangeSet)
+				}
+
+				pending[e.Path] |= e.Op
+
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+
+				timer.Reset(window)
+			case <-timer.C:
+				if !deliver() {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return changes
+}
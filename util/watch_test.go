@@ -0,0 +1,115 @@
+package util_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+const window = 50 * time.Millisecond
+
+// watcherFS is a filesystem whose watches deliver the events sent to its
+// channel.
+type watcherFS struct {
+	billy.Filesystem
+	events   chan billy.Event
+	canceled bool
+}
+
+func (fs *watcherFS) Watch(path string, recursive bool) (<-chan billy.Event, billy.CancelFunc, error) {
+	return fs.events, func() {
+		fs.canceled = true
+		close(fs.events)
+	}, nil
+}
+
+func (s *UtilSuite) TestDebounce(c *C) {
+	events := make(chan billy.Event)
+	changes := util.Debounce(events, window)
+
+	events <- billy.Event{Path: "foo", Op: billy.Create}
+	events <- billy.Event{Path: "bar", Op: billy.Write}
+	events <- billy.Event{Path: "foo", Op: billy.Write}
+	events <- billy.Event{Path: "foo", Op: billy.Write}
+
+	cs := <-changes
+	c.Assert(cs, DeepEquals, util.ChangeSet{
+		"foo": billy.Create | billy.Write,
+		"bar": billy.Write,
+	})
+
+	c.Assert(cs.Paths(), DeepEquals, []string{"bar", "foo"})
+
+	events <- billy.Event{Path: "foo", Op: billy.Remove}
+	c.Assert(<-changes, DeepEquals, util.ChangeSet{"foo": billy.Remove})
+
+	close(events)
+	_, ok := <-changes
+	c.Assert(ok, Equals, false)
+}
+
+func (s *UtilSuite) TestDebounceWindow(c *C) {
+	events := make(chan billy.Event)
+	changes := util.Debounce(events, window)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		events <- billy.Event{Path: "foo", Op: billy.Write}
+		time.Sleep(window / 5)
+	}
+
+	c.Assert(<-changes, DeepEquals, util.ChangeSet{"foo": billy.Write})
+	c.Assert(time.Since(start) >= window, Equals, true)
+	close(events)
+}
+
+func (s *UtilSuite) TestDebounceClose(c *C) {
+	events := make(chan billy.Event, 1)
+	changes := util.Debounce(events, time.Hour)
+
+	events <- billy.Event{Path: "foo", Op: billy.Create}
+	close(events)
+
+	c.Assert(<-changes, DeepEquals, util.ChangeSet{"foo": billy.Create})
+	_, ok := <-changes
+	c.Assert(ok, Equals, false)
+}
+
+func (s *UtilSuite) TestWatch(c *C) {
+	fs := &watcherFS{Filesystem: memfs.New(), events: make(chan billy.Event)}
+	changes, cancel, err := util.Watch(fs, "/", true, window)
+	c.Assert(err, IsNil)
+
+	fs.events <- billy.Event{Path: "foo", Op: billy.Create}
+	c.Assert(<-changes, DeepEquals, util.ChangeSet{"foo": billy.Create})
+
+	fs.events <- billy.Event{Path: "foo", Op: billy.Write}
+	cancel()
+	cancel()
+	c.Assert(fs.canceled, Equals, true)
+
+	for range changes {
+	}
+}
+
+func (s *UtilSuite) TestWatchMemory(c *C) {
+	fs := memfs.New()
+	changes, cancel, err := util.Watch(fs, "/", true, window)
+	c.Assert(err, IsNil)
+	defer cancel()
+
+	c.Assert(util.WriteFile(fs, "foo/bar", []byte("foo"), 0644), IsNil)
+	c.Assert(<-changes, DeepEquals, util.ChangeSet{
+		"foo":     billy.Create,
+		"foo/bar": billy.Create | billy.Write,
+	})
+}
+
+func (s *UtilSuite) TestWatchNotSupported(c *C) {
+	_, _, err := util.Watch(&test.BasicMock{}, "/", true, window)
+	c.Assert(err, Equals, billy.ErrNotSupported)
+}
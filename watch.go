@@ -0,0 +1,60 @@
+package billy
+
+import "strings"
+
+// Op is a set of operations done to a file, reported by a Watcher.
+type Op uint32
+
+const (
+	// Create means that the file was created.
+	Create Op = 1 << iota
+	// Write means that the content of the file was changed.
+	Write
+	// Remove means that the file was removed.
+	Remove
+	// Rename means that the file was renamed, the event is reported with the
+	// old name, and an event with Create is reported with the new one.
+	Rename
+	// Chmod means that the attributes of the file were changed.
+	Chmod
+)
+
+// String returns the names of the operations of the set, separated by "|".
+func (op Op) String() string {
+	var names []string
+	for _, o := range []struct {
+		op   Op
+		name string
+	}{
+		{Create, "CREATE"},
+		{Write, "WRITE"},
+		{Remove, "REMOVE"},
+		{Rename, "RENAME"},
+		{Chmod, "CHMOD"},
+	} {
+		if op&o.op != 0 {
+			names = append(names, o.name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// Event is a change of a file, reported by a Watcher.
+type Event struct {
+	// Path is the path of the file, as it would be given to the filesystem.
+	Path string
+	// Op is the set of operations done to the file.
+	Op Op
+}
+
+// CancelFunc stops a watch, closing its channel of events.
+type CancelFunc func()
+
+// Watcher interface can notify the changes of the files of a filesystem.
+type Watcher interface {
+	// Watch returns a channel delivering the events of the given path, the
+	// file or the directory and its children, or all its descendants if
+	// recursive is true. The channel is closed when the watch is canceled.
+	Watch(path string, recursive bool) (<-chan Event, CancelFunc, error)
+}
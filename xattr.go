@@ -0,0 +1,82 @@
+package billy
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrNoXattr is returned when getting or removing an extended attribute that
+// the file doesn't have.
+var ErrNoXattr = errors.New("attribute not found")
+
+// Xattrer interface can store extended attributes, pairs of names and values
+// attached to the files, as an extension to the Basic interface. The symbolic
+// links are followed. The names allowed depend on the filesystem, e.g. osfs
+// requires the "user." prefix on Linux, and memfs allows any name.
+type Xattrer interface {
+	// GetXattr returns the value of the attr attribute of the named file, or
+	// ErrNoXattr if it doesn't have it.
+	GetXattr(name, attr string) ([]byte, error)
+	// SetXattr sets the value of the attr attribute of the named file,
+	// creating or replacing it.
+	SetXattr(name, attr string, value []byte) error
+	// ListXattr returns the names of the attributes of the named file.
+	ListXattr(name string) ([]string, error)
+	// RemoveXattr removes the attr attribute of the named file, or returns
+	// ErrNoXattr if it doesn't have it.
+	RemoveXattr(name, attr string) error
+}
+
+// IsNoXattr returns a boolean indicating whether the error is known to report
+// that a file doesn't have an extended attribute.
+func IsNoXattr(err error) bool {
+	return underlyingError(err) == ErrNoXattr
+}
+
+// GetXattr returns the value of the attr attribute of the named file, if the
+// FS implements the Xattrer interface, otherwise it returns a *os.PathError
+// with ErrNotSupported.
+func GetXattr(fs Basic, name, attr string) ([]byte, error) {
+	x, ok := fs.(Xattrer)
+	if !ok {
+		return nil, &os.PathError{Op: "getxattr", Path: name, Err: ErrNotSupported}
+	}
+
+	return x.GetXattr(name, attr)
+}
+
+// SetXattr sets the value of the attr attribute of the named file, if the FS
+// implements the Xattrer interface, otherwise it returns a *os.PathError with
+// ErrNotSupported.
+func SetXattr(fs Basic, name, attr string, value []byte) error {
+	x, ok := fs.(Xattrer)
+	if !ok {
+		return &os.PathError{Op: "setxattr", Path: name, Err: ErrNotSupported}
+	}
+
+	return x.SetXattr(name, attr, value)
+}
+
+// ListXattr returns the names of the attributes of the named file, if the FS
+// implements the Xattrer interface, otherwise it returns a *os.PathError with
+// ErrNotSupported.
+func ListXattr(fs Basic, name string) ([]string, error) {
+	x, ok := fs.(Xattrer)
+	if !ok {
+		return nil, &os.PathError{Op: "listxattr", Path: name, Err: ErrNotSupported}
+	}
+
+	return x.ListXattr(name)
+}
+
+// RemoveXattr removes the attr attribute of the named file, if the FS
+// implements the Xattrer interface, otherwise it returns a *os.PathError with
+// ErrNotSupported.
+func RemoveXattr(fs Basic, name, attr string) error {
+	x, ok := fs.(Xattrer)
+	if !ok {
+		return &os.PathError{Op: "removexattr", Path: name, Err: ErrNotSupported}
+	}
+
+	return x.RemoveXattr(name, attr)
+}
@@ -0,0 +1,193 @@
+package zipfs
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+// Writable is a filesystem over a zip archive stored in a file of another
+// filesystem. The contents of the archive are loaded in memory when opened,
+// where the changes are buffered until Flush or Close rewrite the archive. The
+// permissions of the entries are kept in the archive but not enforced, as an
+// archive may hold files inside read-only directories.
+type Writable struct {
+	billy.Filesystem
+
+	fs       billy.Basic
+	filename string
+}
+
+// NewWritable returns a writable filesystem over the zip archive stored in the
+// given file of fs, if the file doesn't exist the filesystem is empty and the
+// file is created on Flush.
+func NewWritable(fs billy.Basic, filename string) (*Writable, error) {
+	w := &Writable{
+		Filesystem: memfs.NewWithOptions(memfs.WithoutPermissions()),
+		fs:         fs,
+		filename:   filename,
+	}
+
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Writable) load() error {
+	f, err := w.fs.Open(w.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+	fi, err := w.fs.Stat(w.filename)
+	if err != nil {
+		return err
+	}
+
+	archive, err := New(f, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	return util.Walk(archive, "/", func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case fi.IsDir():
+			return w.MkdirAll(path, fi.Mode().Perm())
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := archive.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return w.Symlink(target, path)
+		default:
+			return copyFile(archive, w, path, fi.Mode().Perm())
+		}
+	})
+}
+
+// Flush writes the current contents of the filesystem to the archive file,
+// replacing it. The new archive is written to a temporary file first, which is
+// renamed once complete.
+func (w *Writable) Flush() error {
+	tmp, err := util.TempFile(w.fs, filepath.Dir(w.filename), ".zipfs")
+	if err != nil {
+		return err
+	}
+
+	if err := w.write(tmp); err != nil {
+		tmp.Close()
+		w.fs.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		w.fs.Remove(tmp.Name())
+		return err
+	}
+
+	return w.fs.Rename(tmp.Name(), w.filename)
+}
+
+// Close flushes the contents of the filesystem to the archive file.
+func (w *Writable) Close() error {
+	return w.Flush()
+}
+
+func (w *Writable) write(dst io.Writer) error {
+	zw := zip.NewWriter(dst)
+	err := util.Walk(w.Filesystem, "/", func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(path)[1:]
+		if name == "" {
+			return nil
+		}
+
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = name
+		switch {
+		case fi.IsDir():
+			hdr.Name += "/"
+			_, err = zw.CreateHeader(hdr)
+			return err
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := w.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			hdr.Method = zip.Store
+			zf, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+
+			_, err = zf.Write([]byte(target))
+			return err
+		default:
+			hdr.Method = zip.Deflate
+			zf, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+
+			f, err := w.Open(path)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			_, err = io.Copy(zf, f)
+			return err
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func copyFile(src, dst billy.Basic, filename string, perm os.FileMode) error {
+	from, err := src.Open(filename)
+	if err != nil {
+		return err
+	}
+
+	defer from.Close()
+	to, err := dst.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(to, from); err != nil {
+		to.Close()
+		return err
+	}
+
+	return to.Close()
+}
@@ -0,0 +1,109 @@
+package zipfs
+
+import (
+	"archive/zip"
+	"os"
+
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/test"
+	"gopkg.in/src-d/go-billy.v4/util"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&WritableSuite{})
+
+type WritableSuite struct {
+	test.FilesystemSuite
+}
+
+func (s *WritableSuite) SetUpTest(c *C) {
+	fs, err := NewWritable(memfs.New(), "bundle.zip")
+	c.Assert(err, IsNil)
+	s.FilesystemSuite = test.NewFilesystemSuite(fs)
+}
+
+func (s *WritableSuite) TestFlush(c *C) {
+	storage := memfs.New()
+	fs, err := NewWritable(storage, "bundle.zip")
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(fs, "foo/bar", []byte("bar"), 0600), IsNil)
+	c.Assert(fs.MkdirAll("qux", 0755), IsNil)
+	c.Assert(fs.Symlink("foo/bar", "link"), IsNil)
+
+	_, err = storage.Stat("bundle.zip")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	c.Assert(fs.Flush(), IsNil)
+
+	f, err := storage.Open("bundle.zip")
+	c.Assert(err, IsNil)
+	fi, err := storage.Stat("bundle.zip")
+	c.Assert(err, IsNil)
+
+	archive, err := New(f, fi.Size())
+	c.Assert(err, IsNil)
+	c.Assert(readFile(c, archive, "foo/bar"), Equals, "bar")
+	c.Assert(readFile(c, archive, "link"), Equals, "bar")
+
+	fi, err = archive.Stat("qux")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+
+	entries, err := storage.ReadDir("/")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+}
+
+func (s *WritableSuite) TestLoad(c *C) {
+	storage := memfs.New()
+	fs, err := NewWritable(storage, "bundle.zip")
+	c.Assert(err, IsNil)
+
+	c.Assert(util.WriteFile(fs, "foo/bar", []byte("bar"), 0600), IsNil)
+	c.Assert(fs.Symlink("foo/bar", "link"), IsNil)
+	c.Assert(fs.Close(), IsNil)
+
+	fs, err = NewWritable(storage, "bundle.zip")
+	c.Assert(err, IsNil)
+	c.Assert(readFile(c, fs, "foo/bar"), Equals, "bar")
+
+	target, err := fs.Readlink("link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "foo/bar")
+
+	c.Assert(fs.Remove("link"), IsNil)
+	c.Assert(fs.Close(), IsNil)
+
+	fs, err = NewWritable(storage, "bundle.zip")
+	c.Assert(err, IsNil)
+	_, err = fs.Lstat("link")
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *WritableSuite) TestLoadReadOnlyDir(c *C) {
+	storage := memfs.New()
+	f, err := storage.Create("bundle.zip")
+	c.Assert(err, IsNil)
+
+	w := zip.NewWriter(f)
+	writeEntry(c, w, "ro/", zip.Store, os.ModeDir|0555, "")
+	writeEntry(c, w, "ro/file", zip.Store, 0444, "foo")
+	c.Assert(w.Close(), IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	fs, err := NewWritable(storage, "bundle.zip")
+	c.Assert(err, IsNil)
+	c.Assert(readFile(c, fs, "ro/file"), Equals, "foo")
+
+	fi, err := fs.Stat("ro")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode().Perm(), Equals, os.FileMode(0555))
+
+	c.Assert(fs.Close(), IsNil)
+
+	fs, err = NewWritable(storage, "bundle.zip")
+	c.Assert(err, IsNil)
+	c.Assert(readFile(c, fs, "ro/file"), Equals, "foo")
+}
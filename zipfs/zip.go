@@ -0,0 +1,401 @@
+// Package zipfs provides a billy filesystem over a zip archive, read-only or
+// writable, buffering the changes until they are flushed to a new archive.
+package zipfs // import "gopkg.in/src-d/go-billy.v4/zipfs"
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/helper/chroot"
+)
+
+const defaultDirectoryMode = os.ModeDir | 0755
+
+// Zip is a read-only filesystem based on a zip archive.
+type Zip struct {
+	r    io.ReaderAt
+	root *node
+}
+
+// node is an entry of the archive, directories not present in the archive
+// but containing some entry are created with a nil file.
+type node struct {
+	file     *zip.File
+	children map[string]*node
+}
+
+// New returns a new read-only filesystem with the contents of the zip archive
+// of the given size read from r. The content of the files is read from r on
+// demand, so r must remain available while the filesystem is in use. The
+// compressed files are decompressed in memory when opened, the stored ones
+// are read directly from r.
+func New(r io.ReaderAt, size int64) (billy.Filesystem, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := &Zip{r: r, root: newDir()}
+	for _, f := range zr.File {
+		fs.add(f)
+	}
+
+	return chroot.New(fs, string(filepath.Separator)), nil
+}
+
+func (fs *Zip) add(f *zip.File) {
+	parts := billy.SplitPath(f.Name)
+	if len(parts) == 0 {
+		return
+	}
+
+	parent := fs.root
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := parent.children[part]
+		if !ok || !child.isDir() {
+			child = newDir()
+			parent.children[part] = child
+		}
+
+		parent = child
+	}
+
+	name := parts[len(parts)-1]
+	n := &node{file: f}
+	if n.isDir() {
+		n.children = make(map[string]*node)
+		if prev, ok := parent.children[name]; ok && prev.isDir() {
+			n.children = prev.children
+		}
+	}
+
+	parent.children[name] = n
+}
+
+func (fs *Zip) Create(filename string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *Zip) Open(filename string) (billy.File, error) {
+	return fs.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (fs *Zip) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+		return nil, billy.ErrReadOnly
+	}
+
+	n, err := fs.resolve(filename, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.isDir() {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: billy.ErrIsDir}
+	}
+
+	r, err := fs.reader(n.file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{name: filename, SectionReader: r}, nil
+}
+
+// reader returns a reader of the content of f, reading it from the archive if
+// it is stored, or decompressing it in memory otherwise.
+func (fs *Zip) reader(f *zip.File) (*io.SectionReader, error) {
+	if f.Method == zip.Store {
+		offset, err := f.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NewSectionReader(fs.r, offset, int64(f.UncompressedSize64)), nil
+	}
+
+	content, err := readAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NewSectionReader(bytes.NewReader(content), 0, int64(len(content))), nil
+}
+
+func (fs *Zip) Stat(filename string) (os.FileInfo, error) {
+	n, err := fs.resolve(filename, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.info(filename), nil
+}
+
+func (fs *Zip) Lstat(filename string) (os.FileInfo, error) {
+	n, err := fs.resolve(filename, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.info(filename), nil
+}
+
+func (fs *Zip) ReadDir(path string) ([]os.FileInfo, error) {
+	n, err := fs.resolve(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !n.isDir() {
+		return nil, &os.PathError{Op: "readdir", Path: path, Err: syscall.ENOTDIR}
+	}
+
+	entries := make([]os.FileInfo, 0, len(n.children))
+	for name, child := range n.children {
+		entries = append(entries, child.info(name))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+func (fs *Zip) Readlink(link string) (string, error) {
+	n, err := fs.resolve(link, false)
+	if err != nil {
+		return "", err
+	}
+
+	if !n.isSymlink() {
+		return "", &os.PathError{Op: "readlink", Path: link, Err: syscall.EINVAL}
+	}
+
+	target, err := readAll(n.file)
+	return string(target), err
+}
+
+func (fs *Zip) Rename(from, to string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *Zip) Remove(filename string) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *Zip) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (fs *Zip) TempFile(dir, prefix string) (billy.File, error) {
+	return nil, billy.ErrReadOnly
+}
+
+func (fs *Zip) TempDir(dir, prefix string) (string, error) {
+	return "", billy.ErrReadOnly
+}
+
+func (fs *Zip) MkdirAll(filename string, perm os.FileMode) error {
+	return billy.ErrReadOnly
+}
+
+func (fs *Zip) Symlink(target, link string) error {
+	return billy.ErrReadOnly
+}
+
+// Capabilities implements the Capable interface.
+func (fs *Zip) Capabilities() billy.Capability {
+	return billy.ReadCapability | billy.SeekCapability
+}
+
+// resolve returns the node of the given path, following the symbolic links,
+// including the last element if follow is true.
+func (fs *Zip) resolve(filename string, follow bool) (*node, error) {
+	stack := []*node{fs.root}
+	parts := billy.SplitPath(filename)
+	for links := 0; len(parts) > 0; {
+		part := parts[0]
+		parts = parts[1:]
+		cur := stack[len(stack)-1]
+
+		if part == ".." {
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+
+			continue
+		}
+
+		if !cur.isDir() {
+			return nil, &os.PathError{Op: "stat", Path: filename, Err: syscall.ENOTDIR}
+		}
+
+		n, ok := cur.children[part]
+		if !ok {
+			return nil, &os.PathError{Op: "stat", Path: filename, Err: os.ErrNotExist}
+		}
+
+		if n.isSymlink() && (follow || len(parts) > 0) {
+			if links++; links > billy.MaxSymlinks {
+				return nil, &os.PathError{Op: "stat", Path: filename, Err: billy.ErrTooManyLinks}
+			}
+
+			target, err := readAll(n.file)
+			if err != nil {
+				return nil, err
+			}
+
+			if path.IsAbs(filepath.ToSlash(string(target))) {
+				stack = stack[:1]
+			}
+
+			parts = append(billy.SplitPath(string(target)), parts...)
+			continue
+		}
+
+		stack = append(stack, n)
+	}
+
+	return stack[len(stack)-1], nil
+}
+
+func readAll(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func newDir() *node {
+	return &node{children: make(map[string]*node)}
+}
+
+func (n *node) isDir() bool {
+	return n.file == nil || n.file.Mode().IsDir()
+}
+
+func (n *node) isSymlink() bool {
+	return n.file != nil && n.file.Mode()&os.ModeSymlink != 0
+}
+
+func (n *node) info(filename string) os.FileInfo {
+	fi := &fileInfo{name: filepath.Base(filename), mode: defaultDirectoryMode}
+	if n.file == nil {
+		return fi
+	}
+
+	fi.mode = n.file.Mode()
+	fi.modTime = n.file.Modified
+	fi.sys = &n.file.FileHeader
+	if !n.isDir() {
+		fi.size = int64(n.file.UncompressedSize64)
+	}
+
+	return fi
+}
+
+type file struct {
+	*io.SectionReader
+	name     string
+	isClosed bool
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+func (f *file) Read(b []byte) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	return f.SectionReader.Read(b)
+}
+
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	return f.SectionReader.ReadAt(b, off)
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	return f.SectionReader.Seek(offset, whence)
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	return 0, billy.ErrReadOnly
+}
+
+func (f *file) Close() error {
+	if f.isClosed {
+		return os.ErrClosed
+	}
+
+	f.isClosed = true
+	return nil
+}
+
+// Lock is a no-op in zipfs.
+func (f *file) Lock() error {
+	return nil
+}
+
+// Unlock is a no-op in zipfs.
+func (f *file) Unlock() error {
+	return nil
+}
+
+func (f *file) Truncate(size int64) error {
+	return billy.ErrReadOnly
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	sys     interface{}
+}
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *fileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi *fileInfo) Mode() os.FileMode {
+	return fi.mode
+}
+
+func (fi *fileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+func (fi *fileInfo) IsDir() bool {
+	return fi.mode.IsDir()
+}
+
+func (fi *fileInfo) Sys() interface{} {
+	return fi.sys
+}
@@ -0,0 +1,136 @@
+package zipfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gopkg.in/src-d/go-billy.v4"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&ZipSuite{})
+
+type ZipSuite struct {
+	FS billy.Filesystem
+}
+
+func (s *ZipSuite) SetUpTest(c *C) {
+	buf := bytes.NewBuffer(nil)
+	w := zip.NewWriter(buf)
+
+	writeEntry(c, w, "foo/", zip.Store, os.ModeDir|0700, "")
+	writeEntry(c, w, "foo/bar", zip.Deflate, 0644, "bar")
+	writeEntry(c, w, "qux/baz", zip.Store, 0600, "baz")
+	writeEntry(c, w, "link", zip.Store, os.ModeSymlink|0777, "foo/bar")
+	writeEntry(c, w, "abs", zip.Store, os.ModeSymlink|0777, "/qux")
+	c.Assert(w.Close(), IsNil)
+
+	var err error
+	s.FS, err = New(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	c.Assert(err, IsNil)
+}
+
+func writeEntry(c *C, w *zip.Writer, name string, method uint16, mode os.FileMode, content string) {
+	hdr := &zip.FileHeader{Name: name, Method: method}
+	hdr.SetMode(mode)
+
+	f, err := w.CreateHeader(hdr)
+	c.Assert(err, IsNil)
+
+	_, err = f.Write([]byte(content))
+	c.Assert(err, IsNil)
+}
+
+func readFile(c *C, fs billy.Basic, filename string) string {
+	f, err := fs.Open(filename)
+	c.Assert(err, IsNil)
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	c.Assert(err, IsNil)
+	return string(content)
+}
+
+func (s *ZipSuite) TestOpen(c *C) {
+	c.Assert(readFile(c, s.FS, "foo/bar"), Equals, "bar")
+	c.Assert(readFile(c, s.FS, "qux/baz"), Equals, "baz")
+	c.Assert(readFile(c, s.FS, "link"), Equals, "bar")
+	c.Assert(readFile(c, s.FS, "abs/baz"), Equals, "baz")
+
+	_, err := s.FS.Open("foo/qux")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = s.FS.Open("foo")
+	c.Assert(err, NotNil)
+}
+
+func (s *ZipSuite) TestSeek(c *C) {
+	for _, name := range []string{"foo/bar", "qux/baz"} {
+		f, err := s.FS.Open(name)
+		c.Assert(err, IsNil)
+
+		_, err = f.Seek(1, 0)
+		c.Assert(err, IsNil)
+
+		content, err := ioutil.ReadAll(f)
+		c.Assert(err, IsNil)
+		c.Assert(string(content), Equals, name[len(name)-2:])
+		c.Assert(f.Close(), IsNil)
+	}
+}
+
+func (s *ZipSuite) TestStat(c *C) {
+	fi, err := s.FS.Stat("foo/bar")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Name(), Equals, "bar")
+	c.Assert(fi.Size(), Equals, int64(3))
+	c.Assert(fi.Mode(), Equals, os.FileMode(0644))
+
+	fi, err = s.FS.Stat("foo")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+
+	fi, err = s.FS.Stat("qux")
+	c.Assert(err, IsNil)
+	c.Assert(fi.IsDir(), Equals, true)
+
+	fi, err = s.FS.Lstat("link")
+	c.Assert(err, IsNil)
+	c.Assert(fi.Mode()&os.ModeSymlink, Equals, os.ModeSymlink)
+}
+
+func (s *ZipSuite) TestReadDir(c *C) {
+	entries, err := s.FS.ReadDir("/")
+	c.Assert(err, IsNil)
+
+	var names []string
+	for _, fi := range entries {
+		names = append(names, fi.Name())
+	}
+
+	c.Assert(names, DeepEquals, []string{"abs", "foo", "link", "qux"})
+}
+
+func (s *ZipSuite) TestReadlink(c *C) {
+	target, err := s.FS.Readlink("link")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "foo/bar")
+}
+
+func (s *ZipSuite) TestReadOnly(c *C) {
+	_, err := s.FS.Create("foo")
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	_, err = s.FS.OpenFile("foo/bar", os.O_RDWR, 0)
+	c.Assert(err, Equals, billy.ErrReadOnly)
+
+	c.Assert(s.FS.Remove("foo/bar"), Equals, billy.ErrReadOnly)
+	c.Assert(s.FS.MkdirAll("bar", 0755), Equals, billy.ErrReadOnly)
+	c.Assert(billy.CapabilityCheck(s.FS, billy.WriteCapability), Equals, false)
+}